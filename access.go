@@ -0,0 +1,407 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// sourceCIDRAllowlist wraps the given handler, rejecting the requests of
+// clients whose address is not in the given CIDRs. Health checks are always
+// allowed, as they are sent by the kubelet from the address of the node.
+// Headers like X-Forwarded-For are deliberately ignored, as clients can set
+// them freely.
+func sourceCIDRAllowlist(cidrs options.CIDRList, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthzPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := remoteIP(r)
+		if ip == nil || !cidrs.Contains(ip) {
+			klog.V(2).Infof("Rejecting request to %s from %s not in source CIDR allowlist", r.URL.Path, r.RemoteAddr)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// remoteIP returns the IP address of the client of the given request, or nil
+// if it can not be determined.
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return nil
+	}
+	// Strip the zone of IPv6 link-local addresses, e.g. fe80::1%eth0.
+	if i := strings.LastIndex(host, "%"); i >= 0 {
+		host = host[:i]
+	}
+	return net.ParseIP(host)
+}
+
+// rateLimitedScrapesTotal counts the scrapes rejected by scrapeRateLimiter.
+var rateLimitedScrapesTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "kube_state_metrics_rate_limited_scrapes_total",
+		Help: "Number of scrapes rejected because their client exceeded the scrape rate limit",
+	},
+)
+
+// scrapeRateLimiter limits the number of scrapes of each client, identified
+// by its IP address, to a number per minute. A client can scrape that many
+// times in a burst, e.g. after a restart of Prometheus.
+type scrapeRateLimiter struct {
+	perMinute int
+	clock     flowcontrol.Clock
+	next      http.Handler
+
+	mtx     sync.Mutex
+	clients map[string]*clientRateLimiter
+	// pruned is the time clients which have not scraped for a while were
+	// last removed.
+	pruned time.Time
+}
+
+type clientRateLimiter struct {
+	limiter  flowcontrol.RateLimiter
+	lastSeen time.Time
+}
+
+// newScrapeRateLimiter wraps the given handler, rejecting scrapes of clients
+// which exceed the given number of scrapes per minute.
+func newScrapeRateLimiter(perMinute int, clock flowcontrol.Clock, next http.Handler) *scrapeRateLimiter {
+	return &scrapeRateLimiter{
+		perMinute: perMinute,
+		clock:     clock,
+		next:      next,
+		clients:   map[string]*clientRateLimiter{},
+		pruned:    clock.Now(),
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (l *scrapeRateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	client := ""
+	if ip := remoteIP(r); ip != nil {
+		client = ip.String()
+	}
+
+	if !l.accept(client) {
+		rateLimitedScrapesTotal.Inc()
+		klog.V(2).Infof("Rejecting scrape from %s exceeding %d scrapes per minute", r.RemoteAddr, l.perMinute)
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Minute/time.Second)/l.perMinute+1))
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+	l.next.ServeHTTP(w, r)
+}
+
+// accept returns whether the given client may scrape now.
+func (l *scrapeRateLimiter) accept(client string) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.clock.Now()
+	// A client which has not scraped for a minute has its full burst
+	// available again, so it can be forgotten.
+	if now.Sub(l.pruned) > time.Minute {
+		for c, cl := range l.clients {
+			if now.Sub(cl.lastSeen) > time.Minute {
+				delete(l.clients, c)
+			}
+		}
+		l.pruned = now
+	}
+
+	cl, ok := l.clients[client]
+	if !ok {
+		cl = &clientRateLimiter{
+			limiter: flowcontrol.NewTokenBucketRateLimiterWithClock(float32(l.perMinute)/60, l.perMinute, l.clock),
+		}
+		l.clients[client] = cl
+	}
+	cl.lastSeen = now
+
+	return cl.limiter.TryAccept()
+}
+
+// authorizationCacheTTL is the time the authorization decisions for a token
+// are reused, so that every scrape does not cause a TokenReview and
+// SubjectAccessReviews.
+const authorizationCacheTTL = time.Minute
+
+// unauthorizedRequestsTotal counts the requests rejected by
+// scrapeAuthorizer.
+var unauthorizedRequestsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kube_state_metrics_unauthorized_requests_total",
+		Help: "Number of requests rejected because their client could not be authenticated or may not list the requested resources",
+	},
+	[]string{"reason"},
+)
+
+// resourceLister lists the enabled resources and the API resources listed and
+// watched by the stores of a resource, e.g. *store.Builder.
+type resourceLister interface {
+	EnabledResources() []string
+	ListedAPIResources(resource string) ([]store.CollectorAPIResource, bool)
+}
+
+// scrapeAuthorizer delegates the authentication and authorization of the
+// requests of the metrics server to the apiserver. The bearer token of a
+// request is authenticated with a TokenReview, and its user must be allowed
+// to list all API resources listed and watched for the requested resources in
+// the requested namespace, or in all namespaces if none is requested,
+// according to SubjectAccessReviews. Requests to paths not serving metrics,
+// e.g. /-/reload, are authorized as non-resource URLs instead.
+type scrapeAuthorizer struct {
+	client    kubernetes.Interface
+	resources resourceLister
+	clock     flowcontrol.Clock
+	next      http.Handler
+
+	mtx sync.Mutex
+	// decisions holds the status codes of recent requests by the hash of
+	// their token and what they access.
+	decisions map[string]authorizationDecision
+	// pruned is the time expired decisions were last removed.
+	pruned time.Time
+}
+
+type authorizationDecision struct {
+	status  int
+	expires time.Time
+}
+
+// newScrapeAuthorizer wraps the given handler, rejecting requests whose
+// client may not list the requested resources, out of the resources enabled
+// in the given resourceLister.
+func newScrapeAuthorizer(client kubernetes.Interface, resources resourceLister, clock flowcontrol.Clock, next http.Handler) *scrapeAuthorizer {
+	return &scrapeAuthorizer{
+		client:    client,
+		resources: resources,
+		clock:     clock,
+		next:      next,
+		decisions: map[string]authorizationDecision{},
+		pruned:    clock.Now(),
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (a *scrapeAuthorizer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := ""
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		token = strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	}
+
+	status := http.StatusUnauthorized
+	if token != "" {
+		var err error
+		status, err = a.authorize(r.Context(), token, requestedAccess(r, a.resources.EnabledResources()))
+		if err != nil {
+			klog.Errorf("Failed to authorize request to %s from %s: %v", r.URL.Path, r.RemoteAddr, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	switch status {
+	case http.StatusUnauthorized:
+		unauthorizedRequestsTotal.WithLabelValues("unauthenticated").Inc()
+	case http.StatusForbidden:
+		unauthorizedRequestsTotal.WithLabelValues("forbidden").Inc()
+	default:
+		a.next.ServeHTTP(w, r)
+		return
+	}
+	klog.V(2).Infof("Rejecting unauthorized request to %s from %s", r.URL.Path, r.RemoteAddr)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// access is what a request to the metrics server accesses: either the
+// metrics of resources in a namespace, or a path not serving metrics.
+type access struct {
+	namespace string
+	resources []string
+	// path and verb are set for paths not serving metrics, which are
+	// authorized as non-resource URLs, e.g. post on /-/reload.
+	path string
+	verb string
+}
+
+// key returns the key of the authorization decisions of the access.
+func (a access) key() string {
+	return a.namespace + "/" + strings.Join(a.resources, ",") + "/" + a.verb + " " + a.path
+}
+
+// requestedAccess returns what the given request accesses, out of the given
+// enabled resources. Objects are requested by their kind, and scrapes of
+// /metrics may select resources with collect[] parameters and a namespace.
+// All enabled resources in all namespaces, for which the namespace is empty,
+// are requested otherwise. Reloads and profiles access their path.
+func requestedAccess(r *http.Request, enabled []string) access {
+	query := r.URL.Query()
+
+	switch {
+	case r.URL.Path == debugObjectPath:
+		resource, _ := store.ResourceForKind(query.Get("kind"))
+		return access{namespace: query.Get("namespace"), resources: []string{resource}}
+	case r.URL.Path == metricsPath:
+		if collect := query["collect[]"]; len(collect) > 0 {
+			return access{namespace: query.Get("namespace"), resources: collect}
+		}
+		return access{namespace: query.Get("namespace"), resources: enabled}
+	case r.URL.Path == reloadPath, strings.HasPrefix(r.URL.Path, pprofPath):
+		return access{path: r.URL.Path, verb: strings.ToLower(r.Method)}
+	}
+	return access{resources: enabled}
+}
+
+// authorize returns http.StatusOK if the given token is allowed the given
+// access, http.StatusUnauthorized if the token is not valid and
+// http.StatusForbidden otherwise.
+func (a *scrapeAuthorizer) authorize(ctx context.Context, token string, acc access) (int, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:]) + "/" + acc.key()
+
+	a.mtx.Lock()
+	now := a.clock.Now()
+	if now.Sub(a.pruned) > authorizationCacheTTL {
+		for k, d := range a.decisions {
+			if now.After(d.expires) {
+				delete(a.decisions, k)
+			}
+		}
+		a.pruned = now
+	}
+	d, ok := a.decisions[key]
+	a.mtx.Unlock()
+	if ok && now.Before(d.expires) {
+		return d.status, nil
+	}
+
+	status, err := a.review(ctx, token, acc)
+	if err != nil {
+		return 0, err
+	}
+
+	a.mtx.Lock()
+	a.decisions[key] = authorizationDecision{status: status, expires: now.Add(authorizationCacheTTL)}
+	a.mtx.Unlock()
+
+	return status, nil
+}
+
+// review authenticates the given token and checks whether its user is allowed
+// the given access against the apiserver.
+func (a *scrapeAuthorizer) review(ctx context.Context, token string, acc access) (int, error) {
+	tr, err := a.client.AuthenticationV1().TokenReviews().CreateContext(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create TokenReview")
+	}
+	if !tr.Status.Authenticated {
+		return http.StatusUnauthorized, nil
+	}
+
+	user := tr.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	spec := authorizationv1.SubjectAccessReviewSpec{
+		User:   user.Username,
+		UID:    user.UID,
+		Groups: user.Groups,
+		Extra:  extra,
+	}
+
+	if acc.path != "" {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{Path: acc.path, Verb: acc.verb}
+		allowed, err := a.subjectAccessReview(ctx, spec)
+		if err != nil {
+			return 0, err
+		}
+		if !allowed {
+			klog.V(2).Infof("User %s may not %s %s", user.Username, acc.verb, acc.path)
+			return http.StatusForbidden, nil
+		}
+		return http.StatusOK, nil
+	}
+
+	// The metrics of a resource may be generated from several API
+	// resources, e.g. kube_poddisruptionbudget_pod from pods, so all of them
+	// must be allowed.
+	for _, resource := range acc.resources {
+		apiResources, ok := a.resources.ListedAPIResources(resource)
+		if !ok {
+			return http.StatusForbidden, nil
+		}
+		for _, r := range apiResources {
+			spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+				Namespace: acc.namespace,
+				Verb:      "list",
+				Group:     r.Group,
+				Resource:  r.Resource,
+			}
+			allowed, err := a.subjectAccessReview(ctx, spec)
+			if err != nil {
+				return 0, err
+			}
+			if !allowed {
+				klog.V(2).Infof("User %s may not list %s in namespace %q", user.Username, r.Resource, acc.namespace)
+				return http.StatusForbidden, nil
+			}
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// subjectAccessReview returns whether the given SubjectAccessReview is
+// allowed.
+func (a *scrapeAuthorizer) subjectAccessReview(ctx context.Context, spec authorizationv1.SubjectAccessReviewSpec) (bool, error) {
+	sar, err := a.client.AuthorizationV1().SubjectAccessReviews().CreateContext(ctx, &authorizationv1.SubjectAccessReview{Spec: spec})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create SubjectAccessReview")
+	}
+	return sar.Status.Allowed, nil
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/optin"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestSourceCIDRAllowlist(t *testing.T) {
+	cidrs := options.CIDRList{}
+	if err := cidrs.Set("10.0.0.0/8,fd00::1"); err != nil {
+		t.Fatal(err)
+	}
+	handler := sourceCIDRAllowlist(cidrs, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		RemoteAddr string
+		Path       string
+		Want       int
+	}{
+		{"10.1.2.3:41234", metricsPath, http.StatusOK},
+		{"[fd00::1]:41234", metricsPath, http.StatusOK},
+		{"[fd00::1%eth0]:41234", metricsPath, http.StatusOK},
+		{"192.168.1.10:41234", metricsPath, http.StatusForbidden},
+		{"[fd00::2]:41234", metricsPath, http.StatusForbidden},
+		{"192.168.1.10:41234", reloadPath, http.StatusForbidden},
+		{"192.168.1.10:41234", healthzPath, http.StatusOK},
+		{"@", metricsPath, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "http://localhost:8080"+test.Path, nil)
+		req.RemoteAddr = test.RemoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != test.Want {
+			t.Errorf("expected status %d for request to %s from %s, got %d", test.Want, test.Path, test.RemoteAddr, w.Code)
+		}
+	}
+}
+
+func TestScrapeRateLimiter(t *testing.T) {
+	c := clock.NewFakeClock(time.Now())
+	limiter := newScrapeRateLimiter(2, c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	scrape := func(remoteAddr string) int {
+		req := httptest.NewRequest("GET", "http://localhost:8080"+metricsPath, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		limiter.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := scrape("10.0.0.1:41234"); code != http.StatusOK {
+			t.Fatalf("expected scrape %d within burst to succeed, got status %d", i, code)
+		}
+	}
+	if code := scrape("10.0.0.1:41235"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected scrape exceeding the limit to be rejected, got status %d", code)
+	}
+	if code := scrape("10.0.0.2:41234"); code != http.StatusOK {
+		t.Fatalf("expected scrape of other client to succeed, got status %d", code)
+	}
+
+	c.Step(30 * time.Second)
+	if code := scrape("10.0.0.1:41234"); code != http.StatusOK {
+		t.Fatalf("expected scrape after refill to succeed, got status %d", code)
+	}
+
+	c.Step(2 * time.Minute)
+	scrape("10.0.0.1:41234")
+	if len(limiter.clients) != 1 {
+		t.Fatalf("expected idle client to be forgotten, got %d clients", len(limiter.clients))
+	}
+}
+
+func TestScrapeAuthorizer(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	reviews := 0
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		tr := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		reviews++
+		switch tr.Spec.Token {
+		case "tenant-a", "tenant-b", "admin":
+			tr.Status.Authenticated = true
+			tr.Status.User = authenticationv1.UserInfo{Username: tr.Spec.Token, Groups: []string{"tenants"}}
+		}
+		return true, tr, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sar := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		if attrs := sar.Spec.NonResourceAttributes; attrs != nil {
+			sar.Status.Allowed = sar.Spec.User == "admin" &&
+				(attrs.Verb == "post" && attrs.Path == reloadPath || attrs.Verb == "get" && attrs.Path == pprofPath)
+			return true, sar, nil
+		}
+		attrs := sar.Spec.ResourceAttributes
+		switch sar.Spec.User {
+		case "tenant-a":
+			sar.Status.Allowed = attrs.Verb == "list" && attrs.Namespace == "team-a" &&
+				(attrs.Group == "" && attrs.Resource == "pods" || attrs.Group == "apps" && attrs.Resource == "deployments" ||
+					attrs.Group == "policy" && attrs.Resource == "poddisruptionbudgets")
+		case "tenant-b":
+			sar.Status.Allowed = attrs.Verb == "list" && attrs.Namespace == "team-a" &&
+				attrs.Group == "policy" && attrs.Resource == "poddisruptionbudgets"
+		}
+		return true, sar, nil
+	})
+
+	builder := store.NewBuilder()
+	if err := builder.WithEnabledResources([]string{"deployments", "pods", "poddisruptionbudgets"}); err != nil {
+		t.Fatal(err)
+	}
+	optInList, err := optin.NewMetricFamilyFilter(options.MetricSet{"kube_poddisruptionbudget_pod": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.WithOptInList(optInList)
+
+	c := clock.NewFakeClock(time.Now())
+	authorizer := newScrapeAuthorizer(client, builder, c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		Method string
+		Token  string
+		Path   string
+		Want   int
+	}{
+		{"GET", "", metricsPath + "?namespace=team-a", http.StatusUnauthorized},
+		{"GET", "unknown", metricsPath + "?namespace=team-a", http.StatusUnauthorized},
+		{"GET", "tenant-a", metricsPath + "?namespace=team-a", http.StatusOK},
+		{"GET", "tenant-a", metricsPath + "?namespace=team-a&collect[]=pods", http.StatusOK},
+		{"GET", "tenant-a", metricsPath + "?namespace=team-b", http.StatusForbidden},
+		{"GET", "tenant-a", metricsPath, http.StatusForbidden},
+		{"GET", "tenant-a", debugObjectPath + "?kind=Pod&namespace=team-a&name=pod1", http.StatusOK},
+		{"GET", "tenant-a", debugObjectPath + "?kind=Secret&namespace=team-a&name=secret1", http.StatusForbidden},
+		{"GET", "tenant-a", streamPath + "?namespace=team-a", http.StatusForbidden},
+		// kube_poddisruptionbudget_pod exposes pods, so listing pod
+		// disruption budgets is not enough.
+		{"GET", "tenant-a", metricsPath + "?namespace=team-a&collect[]=poddisruptionbudgets", http.StatusOK},
+		{"GET", "tenant-b", metricsPath + "?namespace=team-a&collect[]=poddisruptionbudgets", http.StatusForbidden},
+		{"POST", "tenant-a", reloadPath, http.StatusForbidden},
+		{"POST", "admin", reloadPath, http.StatusOK},
+		{"GET", "tenant-a", pprofPath, http.StatusForbidden},
+		{"GET", "admin", pprofPath, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.Method, "http://localhost:8080"+test.Path, nil)
+		if test.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.Token)
+		}
+		w := httptest.NewRecorder()
+		authorizer.ServeHTTP(w, req)
+		if w.Code != test.Want {
+			t.Errorf("expected status %d for %s request to %s with token %q, got %d", test.Want, test.Method, test.Path, test.Token, w.Code)
+		}
+	}
+
+	// Decisions are cached until they expire.
+	reviewed := reviews
+	authorizer.authorize(context.Background(), "tenant-a", access{namespace: "team-a", resources: []string{"pods"}})
+	if reviews != reviewed {
+		t.Fatalf("expected cached decision to be reused, got %d new reviews", reviews-reviewed)
+	}
+	c.Step(2 * authorizationCacheTTL)
+	authorizer.authorize(context.Background(), "tenant-a", access{namespace: "team-a", resources: []string{"pods"}})
+	if reviews != reviewed+1 {
+		t.Fatalf("expected expired decision to be reviewed again, got %d new reviews", reviews-reviewed)
+	}
+}
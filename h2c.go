@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// h2cListener serves the connections of the wrapped listener which start
+// with the HTTP/2 client preface, i.e. HTTP/2 without TLS with prior
+// knowledge, with an HTTP/2 server, and returns all other connections from
+// Accept, so that they are served by the HTTP/1.1 server.
+type h2cListener struct {
+	net.Listener
+	server *http.Server
+	h2     *http2.Server
+
+	conns chan net.Conn
+	errs  chan error
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// newH2CListener wraps the given listener of the given server. The HTTP/2
+// server must have been configured for it with http2.ConfigureServer, so
+// that its connections are closed gracefully on shutdown.
+func newH2CListener(l net.Listener, server *http.Server, h2 *http2.Server) net.Listener {
+	hl := &h2cListener{
+		Listener: l,
+		server:   server,
+		h2:       h2,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		done:     make(chan struct{}),
+	}
+	go hl.accept()
+	return hl
+}
+
+// Accept implements the net.Listener interface.
+func (l *h2cListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.done:
+		return nil, http.ErrServerClosed
+	}
+}
+
+// Close implements the net.Listener interface.
+func (l *h2cListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+func (l *h2cListener) accept() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case l.errs <- err:
+			case <-l.done:
+				return
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return
+		}
+		go l.serve(conn)
+	}
+}
+
+// serve serves the given connection with the HTTP/2 server if it starts
+// with the HTTP/2 client preface, and passes it on to Accept otherwise.
+func (l *h2cListener) serve(conn net.Conn) {
+	r := bufio.NewReaderSize(conn, len(http2.ClientPreface))
+	if l.server.ReadTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(l.server.ReadTimeout))
+	}
+	h2 := isH2CPreface(r)
+	conn.SetReadDeadline(time.Time{})
+	conn = &bufferedConn{Conn: conn, r: r}
+
+	if h2 {
+		l.h2.ServeConn(conn, &http2.ServeConnOpts{Handler: l.server.Handler, BaseConfig: l.server})
+		return
+	}
+
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// isH2CPreface returns whether the given reader starts with the HTTP/2
+// client preface. It only reads further as long as the bytes read so far
+// match the preface, so that short HTTP/1.1 requests do not block it.
+func isH2CPreface(r *bufio.Reader) bool {
+	for n := 1; n <= len(http2.ClientPreface); n++ {
+		b, err := r.Peek(n)
+		if err != nil || b[n-1] != http2.ClientPreface[n-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// bufferedConn is a connection whose first bytes have been read into a
+// buffered reader.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -36,6 +37,12 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	policy "k8s.io/api/policy/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	watchapi "k8s.io/apimachinery/pkg/watch"
 	vpaautoscaling "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1beta2"
 	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
 	clientset "k8s.io/client-go/kubernetes"
@@ -51,30 +58,82 @@ import (
 	"k8s.io/kube-state-metrics/pkg/watch"
 )
 
+// Make sure the internal Builder implements the public BuilderInterface.
+var _ ksmtypes.BuilderInterface = &Builder{}
+
 // Builder helps to build store. It follows the builder pattern
 // (https://en.wikipedia.org/wiki/Builder_pattern).
 type Builder struct {
 	kubeClient       clientset.Interface
 	vpaClient        vpaclientset.Interface
-	namespaces       options.NamespaceList
+	namespaces       *listwatch.NamespaceSet
 	ctx              context.Context
 	enabledResources []string
 	allowDenyList    ksmtypes.AllowDenyLister
-	metrics          *watch.ListWatchMetrics
-	shard            int32
-	totalShards      int
-	buildStoreFunc   ksmtypes.BuildStoreFunc
+	optInList        ksmtypes.OptInLister
+	allowAnnotations map[string][]string
+	// trackUnscheduledPods controls whether pods that have not been
+	// scheduled to a node yet are exposed by the pod collector.
+	trackUnscheduledPods bool
+	// onlyCurrentConditionStatus controls whether condition metrics only
+	// expose the series of the current status of a condition.
+	onlyCurrentConditionStatus bool
+	// uidLabel controls whether info and created metrics carry the UID of
+	// their object as label.
+	uidLabel bool
+	// customLabels are added to every series.
+	customLabels map[string]string
+	// sampleTimestamps controls whether condition metrics expose the last
+	// transition times of their conditions as sample timestamps.
+	sampleTimestamps bool
+	// labelLimits bounds the labels and annotations exposed by the label
+	// and annotation metric families.
+	labelLimits labelLimits
+	// labelNameScheme names the labels and annotations exposed by the
+	// label and annotation metric families.
+	labelNameScheme string
+	// objectOptIn restricts the objects of some resources to the ones
+	// carrying an annotation.
+	objectOptIn objectOptIn
+	// labelExcludeSelectors hold the selectors of the objects which are not
+	// exposed, by resource.
+	labelExcludeSelectors map[string]labels.Selector
+	// namespaceSeriesLimit, if positive, is the maximum number of series of
+	// every resource exposed per namespace.
+	namespaceSeriesLimit int
+	// changeFunc is called with the changes of the metrics of the objects
+	// of every resource, along with their kind.
+	changeFunc func(kind string, c metricsstore.Change)
+	// rebuildFunc is called whenever the stores are about to be rebuilt
+	// with a new context.
+	rebuildFunc   func()
+	metrics       *watch.ListWatchMetrics
+	droppedSeries *prometheus.GaugeVec
+	// syncs tracks the first lists and the failures of the reflectors
+	// started with the current context.
+	syncs *syncTracker
+	// podFanOut feeds the opt-in metric families derived from pods from a
+	// single pod reflector started with the current context.
+	podFanOut      *podFanOut
+	shard          int32
+	totalShards    int
+	buildStoreFunc ksmtypes.BuildStoreFunc
 }
 
 // NewBuilder returns a new builder.
 func NewBuilder() *Builder {
-	b := &Builder{}
+	b := &Builder{
+		trackUnscheduledPods: true,
+		labelNameScheme:      labelNameSchemeUnderscore,
+		syncs:                &syncTracker{},
+	}
 	return b
 }
 
 // WithMetrics sets the metrics property of a Builder.
 func (b *Builder) WithMetrics(r *prometheus.Registry) {
 	b.metrics = watch.NewListWatchMetrics(r)
+	b.droppedSeries = metricsstore.NewNamespaceSeriesDroppedMetric(r)
 }
 
 // WithEnabledResources sets the enabledResources property of a Builder.
@@ -94,9 +153,15 @@ func (b *Builder) WithEnabledResources(r []string) error {
 	return nil
 }
 
-// WithNamespaces sets the namespaces property of a Builder.
+// WithNamespaces sets the namespaces property of a Builder. Once the stores
+// have been built, changing the namespaces makes their reflectors list and
+// watch the new namespaces without rebuilding the stores.
 func (b *Builder) WithNamespaces(n options.NamespaceList) {
-	b.namespaces = n
+	if b.namespaces == nil {
+		b.namespaces = listwatch.NewNamespaceSet(n)
+		return
+	}
+	b.namespaces.Set(n)
 }
 
 // WithSharding sets the shard and totalShards property of a Builder.
@@ -108,6 +173,17 @@ func (b *Builder) WithSharding(shard int32, totalShards int) {
 // WithContext sets the ctx property of a Builder.
 func (b *Builder) WithContext(ctx context.Context) {
 	b.ctx = ctx
+	// The reflectors of the previous context are stopped along with it.
+	b.syncs = &syncTracker{}
+	b.podFanOut = nil
+	// The stores of the previous context are rebuilt, and report their
+	// dropped series anew.
+	if b.droppedSeries != nil {
+		b.droppedSeries.Reset()
+	}
+	if b.rebuildFunc != nil {
+		b.rebuildFunc()
+	}
 }
 
 // WithKubeClient sets the kubeClient property of a Builder.
@@ -126,6 +202,145 @@ func (b *Builder) WithAllowDenyList(l ksmtypes.AllowDenyLister) {
 	b.allowDenyList = l
 }
 
+// WithOptInList configures which opt-in metrics are exposed by the store
+// build by the Builder.
+func (b *Builder) WithOptInList(l ksmtypes.OptInLister) {
+	b.optInList = l
+}
+
+// WithAllowAnnotations configures which annotations are exposed as labels in
+// the annotations metric of each resource.
+func (b *Builder) WithAllowAnnotations(annotations map[string][]string) {
+	b.allowAnnotations = annotations
+}
+
+// WithTrackUnscheduledPods configures whether pods that have not been
+// scheduled to a node yet are exposed by the pod collector.
+func (b *Builder) WithTrackUnscheduledPods(track bool) {
+	b.trackUnscheduledPods = track
+}
+
+// WithOnlyCurrentConditionStatus configures whether condition metrics only
+// expose the series of the current status of a condition, instead of one
+// series for each of true, false and unknown.
+func (b *Builder) WithOnlyCurrentConditionStatus(only bool) {
+	b.onlyCurrentConditionStatus = only
+}
+
+// WithUIDLabel configures whether info and created metrics carry the UID of
+// their object as uid label.
+func (b *Builder) WithUIDLabel(enabled bool) {
+	b.uidLabel = enabled
+}
+
+// WithCustomLabels configures static labels which are added to every series.
+func (b *Builder) WithCustomLabels(labels map[string]string) {
+	b.customLabels = labels
+}
+
+// WithSampleTimestamps configures whether condition metrics expose the last
+// transition times of their conditions as sample timestamps.
+func (b *Builder) WithSampleTimestamps(enabled bool) {
+	b.sampleTimestamps = enabled
+}
+
+// WithChangeFunc configures a function which is called with every change of
+// the metrics of an object, along with the kind of the object. Aggregates
+// computed by kube-state-metrics itself, e.g. the resources of nodes, are not
+// reported.
+func (b *Builder) WithChangeFunc(f func(kind string, c metricsstore.Change)) {
+	b.changeFunc = f
+}
+
+// WithRebuildFunc configures a function which is called whenever the stores
+// are about to be rebuilt with a new context, after which the objects which
+// are still present are reported as changes again.
+func (b *Builder) WithRebuildFunc(f func()) {
+	b.rebuildFunc = f
+}
+
+// WithNamespaceSeriesLimit configures the maximum number of series of every
+// resource exposed per namespace. The objects of a namespace beyond the limit,
+// in the order of their names, are not exposed. Zero disables the limit.
+func (b *Builder) WithNamespaceSeriesLimit(limit int) {
+	b.namespaceSeriesLimit = limit
+}
+
+// WithLabelLimits configures the maximum number of labels and annotations of
+// an object exposed by the label and annotation metrics, and the maximum
+// length of their values. Longer values are truncated or, if hashValues is
+// set, replaced by their hash. Zero disables the respective limit.
+func (b *Builder) WithLabelLimits(maxCount, maxValueLength int, hashValues bool) {
+	b.labelLimits = labelLimits{
+		maxCount:       maxCount,
+		maxValueLength: maxValueLength,
+		hashValues:     hashValues,
+	}
+}
+
+// WithLabelNameScheme configures how the labels and annotations of objects
+// are named by the label and annotation metrics. See the labelNameScheme
+// constants for the supported schemes.
+func (b *Builder) WithLabelNameScheme(scheme string) error {
+	for _, s := range labelNameSchemes {
+		if s == scheme {
+			b.labelNameScheme = scheme
+			return nil
+		}
+	}
+
+	return errors.Errorf("label name scheme %s does not exist. Available label name schemes: %s", scheme, strings.Join(labelNameSchemes, ","))
+}
+
+// objectOptIn is the annotation objects of the resources in it must carry to
+// be exposed.
+type objectOptIn struct {
+	key       string
+	value     string
+	resources map[string]struct{}
+}
+
+// WithObjectOptIn configures the given resources to only expose the objects
+// carrying the given annotation, in the form key=value, e.g.
+// metrics.example.com/scrape=true. All objects of the other resources are
+// exposed.
+func (b *Builder) WithObjectOptIn(annotation string, resources []string) error {
+	kv := strings.SplitN(annotation, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return errors.Errorf("object opt-in annotation %q is not of the form key=value", annotation)
+	}
+
+	optIn := objectOptIn{key: kv[0], value: kv[1], resources: map[string]struct{}{}}
+	for _, r := range resources {
+		if !resourceExists(r) {
+			return errors.Errorf("resource %s does not exist. Available resources: %s", r, strings.Join(availableResources(), ","))
+		}
+		optIn.resources[r] = struct{}{}
+	}
+	b.objectOptIn = optIn
+
+	return nil
+}
+
+// WithLabelExcludeSelectors configures the given resources not to expose the
+// objects matching the given label selectors, indexed by resource.
+func (b *Builder) WithLabelExcludeSelectors(selectors map[string]string) error {
+	parsed := make(map[string]labels.Selector, len(selectors))
+	for r, selector := range selectors {
+		if !resourceExists(r) {
+			return errors.Errorf("resource %s does not exist. Available resources: %s", r, strings.Join(availableResources(), ","))
+		}
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse label exclude selector of resource %s", r)
+		}
+		parsed[r] = s
+	}
+	b.labelExcludeSelectors = parsed
+
+	return nil
+}
+
 // WithGenerateStoreFunc configures a constom generate store function
 func (b *Builder) WithGenerateStoreFunc(f ksmtypes.BuildStoreFunc) {
 	b.buildStoreFunc = f
@@ -143,181 +358,447 @@ func (b *Builder) Build() []cache.Store {
 	}
 
 	stores := []cache.Store{}
-	activeStoreNames := []string{}
+	activeResources := []string{}
 
 	for _, c := range b.enabledResources {
-		constructor, ok := availableStores[c]
-		if ok {
-			store := constructor(b)
-			activeStoreNames = append(activeStoreNames, c)
-			stores = append(stores, store)
+		if !resourceExists(c) {
+			continue
 		}
+		stores = append(stores, b.BuildResource(c)...)
+		activeResources = append(activeResources, c)
 	}
 
-	klog.Infof("Active resources: %s", strings.Join(activeStoreNames, ","))
+	klog.Infof("Active resources: %s", strings.Join(activeResources, ","))
 
 	return stores
 }
 
-var availableStores = map[string]func(f *Builder) cache.Store{
-	"certificatesigningrequests":      func(b *Builder) cache.Store { return b.buildCsrStore() },
-	"configmaps":                      func(b *Builder) cache.Store { return b.buildConfigMapStore() },
-	"cronjobs":                        func(b *Builder) cache.Store { return b.buildCronJobStore() },
-	"daemonsets":                      func(b *Builder) cache.Store { return b.buildDaemonSetStore() },
-	"deployments":                     func(b *Builder) cache.Store { return b.buildDeploymentStore() },
-	"endpoints":                       func(b *Builder) cache.Store { return b.buildEndpointsStore() },
-	"horizontalpodautoscalers":        func(b *Builder) cache.Store { return b.buildHPAStore() },
-	"ingresses":                       func(b *Builder) cache.Store { return b.buildIngressStore() },
-	"jobs":                            func(b *Builder) cache.Store { return b.buildJobStore() },
-	"leases":                          func(b *Builder) cache.Store { return b.buildLeases() },
-	"limitranges":                     func(b *Builder) cache.Store { return b.buildLimitRangeStore() },
-	"mutatingwebhookconfigurations":   func(b *Builder) cache.Store { return b.buildMutatingWebhookConfigurationStore() },
-	"namespaces":                      func(b *Builder) cache.Store { return b.buildNamespaceStore() },
-	"networkpolicies":                 func(b *Builder) cache.Store { return b.buildNetworkPolicyStore() },
-	"nodes":                           func(b *Builder) cache.Store { return b.buildNodeStore() },
-	"persistentvolumeclaims":          func(b *Builder) cache.Store { return b.buildPersistentVolumeClaimStore() },
-	"persistentvolumes":               func(b *Builder) cache.Store { return b.buildPersistentVolumeStore() },
-	"poddisruptionbudgets":            func(b *Builder) cache.Store { return b.buildPodDisruptionBudgetStore() },
-	"pods":                            func(b *Builder) cache.Store { return b.buildPodStore() },
-	"replicasets":                     func(b *Builder) cache.Store { return b.buildReplicaSetStore() },
-	"replicationcontrollers":          func(b *Builder) cache.Store { return b.buildReplicationControllerStore() },
-	"resourcequotas":                  func(b *Builder) cache.Store { return b.buildResourceQuotaStore() },
-	"secrets":                         func(b *Builder) cache.Store { return b.buildSecretStore() },
-	"services":                        func(b *Builder) cache.Store { return b.buildServiceStore() },
-	"statefulsets":                    func(b *Builder) cache.Store { return b.buildStatefulSetStore() },
-	"storageclasses":                  func(b *Builder) cache.Store { return b.buildStorageClassStore() },
-	"validatingwebhookconfigurations": func(b *Builder) cache.Store { return b.buildValidatingWebhookConfigurationStore() },
-	"volumeattachments":               func(b *Builder) cache.Store { return b.buildVolumeAttachmentStore() },
-	"verticalpodautoscalers":          func(b *Builder) cache.Store { return b.buildVPAStore() },
+// EnabledResources returns the sorted names of the enabled resources.
+func (b *Builder) EnabledResources() []string {
+	resources := append([]string{}, b.enabledResources...)
+	sort.Strings(resources)
+	return resources
+}
+
+// ListedAPIResources returns the API resources listed and watched by the
+// stores of the given resource with the opt-in metric families of the Builder
+// enabled.
+func (b *Builder) ListedAPIResources(resource string) ([]CollectorAPIResource, bool) {
+	return ListedAPIResources(resource, b.optInList)
+}
+
+// Synced returns whether the reflectors of all stores built since the context
+// was last set have listed their objects.
+func (b *Builder) Synced() bool {
+	return b.syncs.synced()
+}
+
+// Stale returns whether the last list or watch of any reflector of the stores
+// built since the context was last set failed, e.g. as the apiserver is
+// unreachable, so that their metrics may be outdated.
+func (b *Builder) Stale() bool {
+	return b.syncs.stale()
+}
+
+// WatchesNamespace returns whether the objects in the given namespace are
+// currently listed and watched.
+func (b *Builder) WatchesNamespace(namespace string) bool {
+	if b.namespaces == nil {
+		return false
+	}
+	for _, ns := range b.namespaces.Get() {
+		if ns == metav1.NamespaceAll || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildResource initializes and registers the stores of the given resource.
+func (b *Builder) BuildResource(resource string) []cache.Store {
+	if b.allowDenyList == nil {
+		panic("allowDenyList should not be nil")
+	}
+
+	definition, ok := resourceDefinitions[resource]
+	if !ok {
+		return nil
+	}
+
+	return definition.buildStores(b)
 }
 
 func resourceExists(name string) bool {
-	_, ok := availableStores[name]
+	_, ok := resourceDefinitions[name]
 	return ok
 }
 
 func availableResources() []string {
 	c := []string{}
-	for name := range availableStores {
+	for name := range resourceDefinitions {
 		c = append(c, name)
 	}
 	return c
 }
 
 func (b *Builder) buildConfigMapStore() cache.Store {
-	return b.buildStoreFunc(configMapMetricFamilies, &v1.ConfigMap{}, createConfigMapListWatch)
+	return b.buildStoreFunc(b.withAnnotations("configmaps", configMapMetricFamilies, configMapAnnotationsMetricFamily), &v1.ConfigMap{}, createConfigMapListWatch)
 }
 
 func (b *Builder) buildCronJobStore() cache.Store {
-	return b.buildStoreFunc(cronJobMetricFamilies, &batchv1beta1.CronJob{}, createCronJobListWatch)
+	return b.buildStoreFunc(b.withAnnotations("cronjobs", cronJobMetricFamilies, cronJobAnnotationsMetricFamily), &batchv1beta1.CronJob{}, createCronJobListWatch)
 }
 
 func (b *Builder) buildDaemonSetStore() cache.Store {
-	return b.buildStoreFunc(daemonSetMetricFamilies, &appsv1.DaemonSet{}, createDaemonSetListWatch)
+	return b.buildStoreFunc(b.withAnnotations("daemonsets", daemonSetMetricFamilies, daemonSetAnnotationsMetricFamily), &appsv1.DaemonSet{}, createDaemonSetListWatch)
+}
+
+func (b *Builder) buildDeploymentStores() []cache.Store {
+	stores := []cache.Store{b.buildDeploymentStore()}
+	if store := b.buildWorkloadStore(); store != nil {
+		stores = append(stores, store)
+	}
+	return stores
 }
 
 func (b *Builder) buildDeploymentStore() cache.Store {
-	return b.buildStoreFunc(deploymentMetricFamilies, &appsv1.Deployment{}, createDeploymentListWatch)
+	drift := newReplicaDriftTracker()
+	return b.buildTrackingStore(b.withAnnotations("deployments", deploymentMetricFamilies(drift), deploymentAnnotationsMetricFamily), &appsv1.Deployment{}, createDeploymentListWatch, drift)
+}
+
+// buildWorkloadStore builds the store for the metrics shared by Deployments,
+// StatefulSets, DaemonSets and ReplicaSets. As these metrics are opt-in, no
+// store is built and no additional watches are started unless at least one
+// of them has been enabled.
+func (b *Builder) buildWorkloadStore() cache.Store {
+	filteredMetricFamilies := b.filterMetricFamilies(workloadMetricFamilies)
+	if len(filteredMetricFamilies) == 0 {
+		return nil
+	}
+
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(filteredMetricFamilies),
+		generator.ComposeMetricGenFuncs(filteredMetricFamilies),
+	)
+	workloadStore := newWorkloadStore(store)
+
+	b.reflectorPerNamespace(&appsv1.Deployment{}, workloadStore.kindStore("Deployment"), createDeploymentListWatch)
+	b.reflectorPerNamespace(&appsv1.StatefulSet{}, workloadStore.kindStore("StatefulSet"), createStatefulSetListWatch)
+	b.reflectorPerNamespace(&appsv1.DaemonSet{}, workloadStore.kindStore("DaemonSet"), createDaemonSetListWatch)
+	b.reflectorPerNamespace(&appsv1.ReplicaSet{}, workloadStore.kindStore("ReplicaSet"), createReplicaSetListWatch)
+
+	return store
 }
 
 func (b *Builder) buildEndpointsStore() cache.Store {
-	return b.buildStoreFunc(endpointMetricFamilies, &v1.Endpoints{}, createEndpointsListWatch)
+	return b.buildStoreFunc(b.withAnnotations("endpoints", endpointMetricFamilies, endpointAnnotationsMetricFamily), &v1.Endpoints{}, createEndpointsListWatch)
 }
 
 func (b *Builder) buildHPAStore() cache.Store {
-	return b.buildStoreFunc(hpaMetricFamilies, &autoscaling.HorizontalPodAutoscaler{}, createHPAListWatch)
+	return b.buildStoreFunc(b.withAnnotations("horizontalpodautoscalers", hpaMetricFamilies, hpaAnnotationsMetricFamily), &autoscaling.HorizontalPodAutoscaler{}, createHPAListWatch)
 }
 
 func (b *Builder) buildIngressStore() cache.Store {
-	return b.buildStoreFunc(ingressMetricFamilies, &extensions.Ingress{}, createIngressListWatch)
+	return b.buildStoreFunc(b.withAnnotations("ingresses", ingressMetricFamilies, ingressAnnotationsMetricFamily), &extensions.Ingress{}, createIngressListWatch)
 }
 
 func (b *Builder) buildJobStore() cache.Store {
-	return b.buildStoreFunc(jobMetricFamilies, &batchv1.Job{}, createJobListWatch)
+	return b.buildStoreFunc(b.withAnnotations("jobs", jobMetricFamilies, jobAnnotationsMetricFamily), &batchv1.Job{}, createJobListWatch)
 }
 
 func (b *Builder) buildLimitRangeStore() cache.Store {
-	return b.buildStoreFunc(limitRangeMetricFamilies, &v1.LimitRange{}, createLimitRangeListWatch)
+	return b.buildStoreFunc(b.withAnnotations("limitranges", limitRangeMetricFamilies, limitRangeAnnotationsMetricFamily), &v1.LimitRange{}, createLimitRangeListWatch)
 }
 
 func (b *Builder) buildMutatingWebhookConfigurationStore() cache.Store {
-	return b.buildStoreFunc(mutatingWebhookConfigurationMetricFamilies, &admissionregistration.MutatingWebhookConfiguration{}, createMutatingWebhookConfigurationListWatch)
+	return b.buildStoreFunc(b.withAnnotations("mutatingwebhookconfigurations", mutatingWebhookConfigurationMetricFamilies, mutatingWebhookConfigurationAnnotationsMetricFamily), &admissionregistration.MutatingWebhookConfiguration{}, createMutatingWebhookConfigurationListWatch)
+}
+
+func (b *Builder) buildNamespaceStores() []cache.Store {
+	stores := []cache.Store{b.buildNamespaceStore()}
+	if store := b.buildNamespaceRollupStore(); store != nil {
+		stores = append(stores, store)
+	}
+	return stores
 }
 
 func (b *Builder) buildNamespaceStore() cache.Store {
-	return b.buildStoreFunc(namespaceMetricFamilies, &v1.Namespace{}, createNamespaceListWatch)
+	return b.buildStoreFunc(b.withAnnotations("namespaces", namespaceMetricFamilies, namespaceAnnotationsMetricFamily), &v1.Namespace{}, createNamespaceListWatch)
+}
+
+// buildNamespaceRollupStore builds the store for the per namespace rollups of
+// pods and workloads. As all of these metrics are opt-in, no store is built
+// unless at least one of them has been enabled, and pods and workloads are
+// only watched if the metrics counting them have been enabled.
+func (b *Builder) buildNamespaceRollupStore() cache.Store {
+	filteredMetricFamilies := b.filterMetricFamilies(namespaceRollupMetricFamilies)
+	if len(filteredMetricFamilies) == 0 {
+		return nil
+	}
+
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(filteredMetricFamilies),
+		generator.ComposeMetricGenFuncs(filteredMetricFamilies),
+	)
+	rollupStore := newNamespaceRollupStore(store)
+
+	var watchPods, watchWorkloads bool
+	for _, f := range filteredMetricFamilies {
+		switch f.Name {
+		case namespacePodsMetricName, namespaceContainersCrashLoopBackOffMetricName:
+			watchPods = true
+		case namespaceWorkloadsMetricName:
+			watchWorkloads = true
+		}
+	}
+
+	if watchPods {
+		b.watchPods(rollupStore.podStore())
+	}
+	if watchWorkloads {
+		b.reflectorPerNamespace(&appsv1.DaemonSet{}, rollupStore.workloadStore("DaemonSet"), createDaemonSetListWatch)
+		b.reflectorPerNamespace(&appsv1.Deployment{}, rollupStore.workloadStore("Deployment"), createDeploymentListWatch)
+		b.reflectorPerNamespace(&appsv1.StatefulSet{}, rollupStore.workloadStore("StatefulSet"), createStatefulSetListWatch)
+	}
+
+	return store
 }
 
 func (b *Builder) buildNetworkPolicyStore() cache.Store {
-	return b.buildStore(networkpolicyMetricFamilies, &networkingv1.NetworkPolicy{}, createNetworkPolicyListWatch)
+	return b.buildStore(b.withAnnotations("networkpolicies", networkpolicyMetricFamilies, networkpolicyAnnotationsMetricFamily), &networkingv1.NetworkPolicy{}, createNetworkPolicyListWatch)
+}
+
+func (b *Builder) buildNodeStores() []cache.Store {
+	stores := []cache.Store{b.buildNodeStore()}
+	if store := b.buildNodeResourcesStore(); store != nil {
+		stores = append(stores, store)
+	}
+	return stores
 }
 
 func (b *Builder) buildNodeStore() cache.Store {
-	return b.buildStore(nodeMetricFamilies, &v1.Node{}, createNodeListWatch)
+	unschedulable := newUnschedulableTracker()
+	return b.buildTrackingStore(b.withAnnotations("nodes", nodeMetricFamilies(unschedulable), nodeAnnotationsMetricFamily), &v1.Node{}, createNodeListWatch, unschedulable)
+}
+
+// buildNodeResourcesStore builds the store for the per node resource
+// aggregates, which are computed from the scheduled pods of the shared pod watch. As all of
+// these metrics are opt-in, no store is built and no pods are watched unless
+// at least one of them has been enabled.
+func (b *Builder) buildNodeResourcesStore() cache.Store {
+	filteredMetricFamilies := b.filterMetricFamilies(nodeResourcesMetricFamilies)
+	if len(filteredMetricFamilies) == 0 {
+		return nil
+	}
+
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(filteredMetricFamilies),
+		generator.ComposeMetricGenFuncs(filteredMetricFamilies),
+	)
+	b.watchPods(newNodeResourcesStore(store))
+
+	return store
 }
 
 func (b *Builder) buildPersistentVolumeClaimStore() cache.Store {
-	return b.buildStoreFunc(persistentVolumeClaimMetricFamilies, &v1.PersistentVolumeClaim{}, createPersistentVolumeClaimListWatch)
+	return b.buildStoreFunc(b.withAnnotations("persistentvolumeclaims", persistentVolumeClaimMetricFamilies, persistentVolumeClaimAnnotationsMetricFamily), &v1.PersistentVolumeClaim{}, createPersistentVolumeClaimListWatch)
 }
 
 func (b *Builder) buildPersistentVolumeStore() cache.Store {
-	return b.buildStoreFunc(persistentVolumeMetricFamilies, &v1.PersistentVolume{}, createPersistentVolumeListWatch)
+	return b.buildStoreFunc(b.withAnnotations("persistentvolumes", persistentVolumeMetricFamilies, persistentVolumeAnnotationsMetricFamily), &v1.PersistentVolume{}, createPersistentVolumeListWatch)
+}
+
+func (b *Builder) buildPodDisruptionBudgetStores() []cache.Store {
+	stores := []cache.Store{b.buildPodDisruptionBudgetStore()}
+	if store := b.buildPodDisruptionBudgetPodStore(); store != nil {
+		stores = append(stores, store)
+	}
+	return stores
 }
 
 func (b *Builder) buildPodDisruptionBudgetStore() cache.Store {
-	return b.buildStoreFunc(podDisruptionBudgetMetricFamilies, &policy.PodDisruptionBudget{}, createPodDisruptionBudgetListWatch)
+	return b.buildStoreFunc(b.withAnnotations("poddisruptionbudgets", podDisruptionBudgetMetricFamilies, podDisruptionBudgetAnnotationsMetricFamily), &policy.PodDisruptionBudget{}, createPodDisruptionBudgetListWatch)
+}
+
+// buildPodDisruptionBudgetPodStore builds the store joining pods with the pod
+// disruption budgets selecting them. As its metric is opt-in, no store is
+// built and no additional watches are started unless it has been enabled.
+// Pods are sharded as usual, while every shard needs to see all pod
+// disruption budgets to evaluate their selectors.
+func (b *Builder) buildPodDisruptionBudgetPodStore() cache.Store {
+	filteredMetricFamilies := b.filterMetricFamilies(podDisruptionBudgetPodMetricFamilies)
+	if len(filteredMetricFamilies) == 0 {
+		return nil
+	}
+
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(filteredMetricFamilies),
+		generator.ComposeMetricGenFuncs(filteredMetricFamilies),
+	)
+	podStore := newPodDisruptionBudgetPodStore(store)
+
+	b.watchPods(podStore.podStore())
+	b.unshardedReflectorPerNamespace(&policy.PodDisruptionBudget{}, podStore.budgetStore(), createPodDisruptionBudgetListWatch)
+
+	return store
 }
 
 func (b *Builder) buildReplicaSetStore() cache.Store {
-	return b.buildStoreFunc(replicaSetMetricFamilies, &appsv1.ReplicaSet{}, createReplicaSetListWatch)
+	return b.buildStoreFunc(b.withAnnotations("replicasets", replicaSetMetricFamilies, replicaSetAnnotationsMetricFamily), &appsv1.ReplicaSet{}, createReplicaSetListWatch)
 }
 
 func (b *Builder) buildReplicationControllerStore() cache.Store {
-	return b.buildStoreFunc(replicationControllerMetricFamilies, &v1.ReplicationController{}, createReplicationControllerListWatch)
+	return b.buildStoreFunc(b.withAnnotations("replicationcontrollers", replicationControllerMetricFamilies, replicationControllerAnnotationsMetricFamily), &v1.ReplicationController{}, createReplicationControllerListWatch)
 }
 
 func (b *Builder) buildResourceQuotaStore() cache.Store {
-	return b.buildStoreFunc(resourceQuotaMetricFamilies, &v1.ResourceQuota{}, createResourceQuotaListWatch)
+	return b.buildStoreFunc(b.withAnnotations("resourcequotas", resourceQuotaMetricFamilies, resourceQuotaAnnotationsMetricFamily), &v1.ResourceQuota{}, createResourceQuotaListWatch)
 }
 
 func (b *Builder) buildSecretStore() cache.Store {
-	return b.buildStoreFunc(secretMetricFamilies, &v1.Secret{}, createSecretListWatch)
+	return b.buildStoreFunc(b.withAnnotations("secrets", secretMetricFamilies, secretAnnotationsMetricFamily), &v1.Secret{}, createSecretListWatch)
 }
 
 func (b *Builder) buildServiceStore() cache.Store {
-	return b.buildStoreFunc(serviceMetricFamilies, &v1.Service{}, createServiceListWatch)
+	return b.buildStoreFunc(b.withAnnotations("services", serviceMetricFamilies, serviceAnnotationsMetricFamily), &v1.Service{}, createServiceListWatch)
 }
 
 func (b *Builder) buildStatefulSetStore() cache.Store {
-	return b.buildStoreFunc(statefulSetMetricFamilies, &appsv1.StatefulSet{}, createStatefulSetListWatch)
+	drift := newReplicaDriftTracker()
+	return b.buildTrackingStore(b.withAnnotations("statefulsets", statefulSetMetricFamilies(drift), statefulSetAnnotationsMetricFamily), &appsv1.StatefulSet{}, createStatefulSetListWatch, drift)
 }
 
 func (b *Builder) buildStorageClassStore() cache.Store {
-	return b.buildStoreFunc(storageClassMetricFamilies, &storagev1.StorageClass{}, createStorageClassListWatch)
+	return b.buildStoreFunc(b.withAnnotations("storageclasses", storageClassMetricFamilies, storageClassAnnotationsMetricFamily), &storagev1.StorageClass{}, createStorageClassListWatch)
+}
+
+func (b *Builder) buildPodStores() []cache.Store {
+	stores := []cache.Store{b.buildPodStore()}
+	if store := b.buildPodWorkloadStore(); store != nil {
+		stores = append(stores, store)
+	}
+	if store := b.buildPodCrashLoopStore(); store != nil {
+		stores = append(stores, store)
+	}
+	return stores
 }
 
 func (b *Builder) buildPodStore() cache.Store {
-	return b.buildStoreFunc(podMetricFamilies, &v1.Pod{}, createPodListWatch)
+	listWatchFunc := createPodListWatch
+	if !b.trackUnscheduledPods {
+		listWatchFunc = createScheduledPodListWatch
+	}
+	return b.buildStoreFunc(b.withAnnotations("pods", podMetricFamilies, podAnnotationsMetricFamily), &v1.Pod{}, listWatchFunc)
+}
+
+// buildPodWorkloadStore builds the store resolving the top-level workloads of
+// pods. As its metric is opt-in, no store is built and no additional watches
+// are started unless it has been enabled. Pods are sharded as usual, while
+// every shard needs to see all ReplicaSets and Jobs to resolve their
+// controllers.
+func (b *Builder) buildPodWorkloadStore() cache.Store {
+	filteredMetricFamilies := b.filterMetricFamilies(podWorkloadMetricFamilies)
+	if len(filteredMetricFamilies) == 0 {
+		return nil
+	}
+
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(filteredMetricFamilies),
+		generator.ComposeMetricGenFuncs(filteredMetricFamilies),
+	)
+	workloadStore := newPodWorkloadStore(store)
+
+	b.watchPods(workloadStore.podStore())
+	b.unshardedReflectorPerNamespace(&appsv1.ReplicaSet{}, workloadStore.ownerStore("ReplicaSet"), createReplicaSetListWatch)
+	b.unshardedReflectorPerNamespace(&batchv1.Job{}, workloadStore.ownerStore("Job"), createJobListWatch)
+
+	return store
+}
+
+// buildPodCrashLoopStore builds the store tracking the restart streaks of
+// containers. As its metric is opt-in, no store is built and the shared pod
+// watch is not started for it unless it has been enabled.
+func (b *Builder) buildPodCrashLoopStore() cache.Store {
+	filteredMetricFamilies := b.filterMetricFamilies(podCrashLoopMetricFamilies)
+	if len(filteredMetricFamilies) == 0 {
+		return nil
+	}
+
+	store := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(filteredMetricFamilies),
+		generator.ComposeMetricGenFuncs(filteredMetricFamilies),
+	)
+	crashLoopStore := newPodCrashLoopStore(store)
+
+	b.watchPods(crashLoopStore.podStore())
+
+	return store
 }
 
 func (b *Builder) buildCsrStore() cache.Store {
-	return b.buildStoreFunc(csrMetricFamilies, &certv1beta1.CertificateSigningRequest{}, createCSRListWatch)
+	return b.buildStoreFunc(b.withAnnotations("certificatesigningrequests", csrMetricFamilies, csrAnnotationsMetricFamily), &certv1beta1.CertificateSigningRequest{}, createCSRListWatch)
 }
 
 func (b *Builder) buildValidatingWebhookConfigurationStore() cache.Store {
-	return b.buildStoreFunc(validatingWebhookConfigurationMetricFamilies, &admissionregistration.ValidatingWebhookConfiguration{}, createValidatingWebhookConfigurationListWatch)
+	return b.buildStoreFunc(b.withAnnotations("validatingwebhookconfigurations", validatingWebhookConfigurationMetricFamilies, validatingWebhookConfigurationAnnotationsMetricFamily), &admissionregistration.ValidatingWebhookConfiguration{}, createValidatingWebhookConfigurationListWatch)
 }
 
 func (b *Builder) buildVolumeAttachmentStore() cache.Store {
-	return b.buildStore(volumeAttachmentMetricFamilies, &storagev1.VolumeAttachment{}, createVolumeAttachmentListWatch)
+	return b.buildStore(b.withAnnotations("volumeattachments", volumeAttachmentMetricFamilies, volumeAttachmentAnnotationsMetricFamily), &storagev1.VolumeAttachment{}, createVolumeAttachmentListWatch)
 }
 
 func (b *Builder) buildVPAStore() cache.Store {
-	return b.buildStore(vpaMetricFamilies, &vpaautoscaling.VerticalPodAutoscaler{}, createVPAListWatchFunc(b.vpaClient))
+	return b.buildStore(b.withAnnotations("verticalpodautoscalers", vpaMetricFamilies, vpaAnnotationsMetricFamily), &vpaautoscaling.VerticalPodAutoscaler{}, createVPAListWatchFunc(b.vpaClient))
 }
 
 func (b *Builder) buildLeases() cache.Store {
-	return b.buildStore(leaseMetricFamilies, &coordinationv1.Lease{}, createLeaseListWatch)
+	return b.buildStore(b.withAnnotations("leases", leaseMetricFamilies, leaseAnnotationsMetricFamily), &coordinationv1.Lease{}, createLeaseListWatch)
+}
+
+// withAnnotations appends the annotations metric family of the given resource
+// to its metric families, if any annotations are allowed for the resource.
+func (b *Builder) withAnnotations(
+	resource string,
+	metricFamilies []generator.FamilyGenerator,
+	annotationsMetricFamily func(allowedAnnotations []string) generator.FamilyGenerator,
+) []generator.FamilyGenerator {
+	allowedAnnotations, ok := b.allowAnnotations[resource]
+	if !ok || len(allowedAnnotations) == 0 {
+		return metricFamilies
+	}
+
+	families := make([]generator.FamilyGenerator, 0, len(metricFamilies)+1)
+	families = append(families, metricFamilies...)
+	return append(families, annotationsMetricFamily(allowedAnnotations))
+}
+
+// filterMetricFamilies returns the enabled metric families among the given
+// ones, wrapped according to the configuration of the Builder.
+func (b *Builder) filterMetricFamilies(metricFamilies []generator.FamilyGenerator) []generator.FamilyGenerator {
+	mappers := []func(generator.FamilyGenerator) familyMapper{}
+	if b.onlyCurrentConditionStatus {
+		mappers = append(mappers, currentConditionStatusOnly)
+	}
+	if b.sampleTimestamps {
+		mappers = append(mappers, conditionSampleTimestamps)
+	}
+	if b.labelLimits.maxCount > 0 || b.labelLimits.maxValueLength > 0 {
+		mappers = append(mappers, limitLabels(b.labelLimits))
+	}
+	if b.labelNameScheme != labelNameSchemeUnderscore {
+		mappers = append(mappers, labelNameScheme(b.labelNameScheme))
+	}
+	if b.uidLabel {
+		mappers = append(mappers, uidLabel)
+	}
+	// Custom labels come last, so that they do not override labels of the
+	// object like its uid.
+	if len(b.customLabels) > 0 {
+		mappers = append(mappers, customLabels(b.customLabels))
+	}
+	metricFamilies = mapMetricFamilies(metricFamilies, mappers...)
+
+	return generator.FilterMetricFamilies(b.allowDenyList, generator.FilterOptInMetricFamilies(b.optInList, metricFamilies))
 }
 
 func (b *Builder) buildStore(
@@ -325,7 +806,37 @@ func (b *Builder) buildStore(
 	expectedType interface{},
 	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
 ) cache.Store {
-	filteredMetricFamilies := generator.FilterMetricFamilies(b.allowDenyList, metricFamilies)
+	store := b.newMetricsStore(metricFamilies, expectedType)
+	// The metric families built by buildStore only depend on the objects
+	// themselves, so unchanged objects need not be regenerated.
+	store.WithSkipUnchanged()
+	b.reflectorPerNamespace(expectedType, store, listWatchFunc)
+
+	return store
+}
+
+// buildTrackingStore is like buildStore, but makes the given tracker, which
+// keeps the state of the objects the given metric families depend on, forget
+// the objects which are deleted. As the metric families depend on more than
+// the objects themselves, unchanged objects are regenerated. As the tracker is
+// owned by the store, the store is not built with the configurable
+// buildStoreFunc.
+func (b *Builder) buildTrackingStore(
+	metricFamilies []generator.FamilyGenerator,
+	expectedType interface{},
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
+	tracker objectTracker,
+) cache.Store {
+	store := b.newMetricsStore(metricFamilies, expectedType)
+	b.reflectorPerNamespace(expectedType, &trackingStore{Store: store, tracker: tracker}, listWatchFunc)
+
+	return store
+}
+
+// newMetricsStore returns a MetricsStore for the enabled metric families
+// among the given ones, generated from objects of the given type.
+func (b *Builder) newMetricsStore(metricFamilies []generator.FamilyGenerator, expectedType interface{}) *metricsstore.MetricsStore {
+	filteredMetricFamilies := b.filterMetricFamilies(metricFamilies)
 	composedMetricGenFuncs := generator.ComposeMetricGenFuncs(filteredMetricFamilies)
 
 	familyHeaders := generator.ExtractMetricFamilyHeaders(filteredMetricFamilies)
@@ -334,11 +845,35 @@ func (b *Builder) buildStore(
 		familyHeaders,
 		composedMetricGenFuncs,
 	)
-	b.reflectorPerNamespace(expectedType, store, listWatchFunc)
+	if b.changeFunc != nil {
+		kind, changeFunc := reflect.TypeOf(expectedType).Elem().Name(), b.changeFunc
+		store.WithChangeFunc(func(c metricsstore.Change) { changeFunc(kind, c) })
+	}
+	if b.namespaceSeriesLimit > 0 {
+		store.WithNamespaceSeriesLimit(b.namespaceSeriesLimit, b.droppedSeriesFunc(reflect.TypeOf(expectedType).String()))
+	}
 
 	return store
 }
 
+// droppedSeriesFunc returns the function reporting the series of the given
+// resource dropped in a namespace by the namespace series limit. Once the
+// context of the store is done, e.g. while it is still served during a
+// rebuild, it no longer reports anything.
+func (b *Builder) droppedSeriesFunc(resource string) func(namespace string, series int) {
+	ctx, droppedSeries := b.ctx, b.droppedSeries
+	return func(namespace string, series int) {
+		if droppedSeries == nil || (ctx != nil && ctx.Err() != nil) {
+			return
+		}
+		if series == 0 {
+			droppedSeries.DeleteLabelValues(resource, namespace)
+			return
+		}
+		droppedSeries.WithLabelValues(resource, namespace).Set(float64(series))
+	}
+}
+
 // reflectorPerNamespace creates a Kubernetes client-go reflector with the given
 // listWatchFunc for each given namespace and registers it with the given store.
 func (b *Builder) reflectorPerNamespace(
@@ -346,9 +881,233 @@ func (b *Builder) reflectorPerNamespace(
 	store cache.Store,
 	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
 ) {
-	lwf := func(ns string) cache.ListerWatcher { return listWatchFunc(b.kubeClient, ns) }
-	lw := listwatch.MultiNamespaceListerWatcher(b.namespaces, nil, lwf)
+	lw := b.withObjectFilters(expectedType, b.namespacedListWatch(expectedType, listWatchFunc))
+	instrumentedListWatch := watch.NewInstrumentedListerWatcher(lw, b.metrics, reflect.TypeOf(expectedType).String())
+	trackedStore, trackedListWatch := b.syncs.track(store, instrumentedListWatch)
+	reflector := cache.NewReflector(sharding.NewShardedListWatch(b.shard, b.totalShards, trackedListWatch), expectedType, trackedStore, 0)
+	go watch.RunReflector(reflector, reflect.TypeOf(expectedType).String(), b.ctx.Done())
+}
+
+// watchPods feeds the given store from the pod reflector shared by the opt-in
+// metric families derived from pods, which is started with the first store.
+// Unscheduled pods are only passed on if they are tracked.
+func (b *Builder) watchPods(store cache.Store) {
+	if b.podFanOut == nil {
+		b.podFanOut = newPodFanOut()
+		listWatchFunc := createPodListWatch
+		if !b.trackUnscheduledPods {
+			listWatchFunc = createScheduledPodListWatch
+		}
+		b.reflectorPerNamespace(&v1.Pod{}, b.podFanOut, listWatchFunc)
+	}
+	if err := b.podFanOut.register(store); err != nil {
+		klog.Errorf("Failed to pass the current pods on to a new store: %v", err)
+	}
+}
+
+// unshardedReflectorPerNamespace is like reflectorPerNamespace, but passes all
+// objects on to the store regardless of the shard of this instance.
+func (b *Builder) unshardedReflectorPerNamespace(
+	expectedType interface{},
+	store cache.Store,
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
+) {
+	lw := b.withObjectFilters(expectedType, b.namespacedListWatch(expectedType, listWatchFunc))
 	instrumentedListWatch := watch.NewInstrumentedListerWatcher(lw, b.metrics, reflect.TypeOf(expectedType).String())
-	reflector := cache.NewReflector(sharding.NewShardedListWatch(b.shard, b.totalShards, instrumentedListWatch), expectedType, store, 0)
-	go reflector.Run(b.ctx.Done())
+	trackedStore, trackedListWatch := b.syncs.track(store, instrumentedListWatch)
+	reflector := cache.NewReflector(trackedListWatch, expectedType, trackedStore, 0)
+	go watch.RunReflector(reflector, reflect.TypeOf(expectedType).String(), b.ctx.Done())
+}
+
+// namespacedListWatch returns the ListerWatcher of the objects of the given
+// type in the namespaces of the Builder. Cluster-scoped objects do not belong
+// to any namespace, so they are listed and watched once in all namespaces
+// regardless of the namespaces of the Builder.
+func (b *Builder) namespacedListWatch(
+	expectedType interface{},
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
+) cache.ListerWatcher {
+	if resource, ok := ResourceForKind(reflect.TypeOf(expectedType).Elem().Name()); ok && resourceDefinitions[resource].clusterScoped {
+		return listWatchFunc(b.kubeClient, metav1.NamespaceAll)
+	}
+
+	lwf := func(ns string) cache.ListerWatcher { return listWatchFunc(b.kubeClient, ns) }
+	return listwatch.DynamicMultiNamespaceListerWatcher(b.namespaces, nil, lwf)
+}
+
+// withObjectFilters wraps the given ListerWatcher of objects of the given
+// type to only pass on the objects carrying the object opt-in annotation and
+// not matching the label exclude selector, if they are configured for their
+// resource.
+func (b *Builder) withObjectFilters(expectedType interface{}, lw cache.ListerWatcher) cache.ListerWatcher {
+	if len(b.objectOptIn.resources) == 0 && len(b.labelExcludeSelectors) == 0 {
+		return lw
+	}
+
+	resource, ok := ResourceForKind(reflect.TypeOf(expectedType).Elem().Name())
+	if !ok {
+		return lw
+	}
+
+	_, optIn := b.objectOptIn.resources[resource]
+	exclude, hasExclude := b.labelExcludeSelectors[resource]
+	if !optIn && !hasExclude {
+		return lw
+	}
+
+	key, value := b.objectOptIn.key, b.objectOptIn.value
+	return listwatch.NewFilterListerWatcher(func(o metav1.Object) bool {
+		if optIn {
+			if v, ok := o.GetAnnotations()[key]; !ok || v != value {
+				return false
+			}
+		}
+		return !hasExclude || !exclude.Matches(labels.Set(o.GetLabels()))
+	}, lw)
+}
+
+// objectTracker keeps the state of objects across their updates which the
+// metric families of a store depend on, e.g. since when a node has been
+// unschedulable.
+type objectTracker interface {
+	// forget drops the state of the object with the given UID.
+	forget(uid types.UID)
+	// retain drops the state of all objects but the ones with the given
+	// UIDs.
+	retain(uids map[types.UID]struct{})
+}
+
+// trackingStore passes the objects on to the wrapped store and makes its
+// tracker forget the objects which are deleted or no longer listed.
+type trackingStore struct {
+	cache.Store
+	tracker objectTracker
+}
+
+// Delete implements the Delete method of the store interface.
+func (s *trackingStore) Delete(obj interface{}) error {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	s.tracker.forget(o.GetUID())
+
+	return s.Store.Delete(obj)
+}
+
+// Replace implements the Replace method of the store interface.
+func (s *trackingStore) Replace(list []interface{}, resourceVersion string) error {
+	uids := make(map[types.UID]struct{}, len(list))
+	for _, obj := range list {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		uids[o.GetUID()] = struct{}{}
+	}
+	s.tracker.retain(uids)
+
+	return s.Store.Replace(list, resourceVersion)
+}
+
+// syncTracker tracks whether the reflectors of a set of stores have listed
+// their objects, and whether their last list or watch failed.
+type syncTracker struct {
+	mtx     sync.Mutex
+	pending int
+	failing int
+}
+
+// track returns the given store and ListerWatcher of a reflector wrapped to
+// report the first replacement of the objects of the store, i.e. the first
+// list of the reflector, and the failures of its lists and watches to the
+// tracker.
+func (t *syncTracker) track(store cache.Store, lw cache.ListerWatcher) (cache.Store, cache.ListerWatcher) {
+	t.mtx.Lock()
+	t.pending++
+	t.mtx.Unlock()
+
+	r := &trackedReflector{tracker: t}
+	return &syncedStore{Store: store, reflector: r}, &healthListerWatcher{next: lw, reflector: r}
+}
+
+func (t *syncTracker) synced() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.pending == 0
+}
+
+func (t *syncTracker) stale() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.failing > 0
+}
+
+// trackedReflector is a reflector tracked by a syncTracker.
+type trackedReflector struct {
+	tracker *syncTracker
+	once    sync.Once
+	failing bool
+}
+
+func (r *trackedReflector) synced() {
+	r.once.Do(func() {
+		r.tracker.mtx.Lock()
+		r.tracker.pending--
+		r.tracker.mtx.Unlock()
+	})
+}
+
+func (r *trackedReflector) result(err error) {
+	r.tracker.mtx.Lock()
+	defer r.tracker.mtx.Unlock()
+
+	if failing := err != nil; failing != r.failing {
+		r.failing = failing
+		if failing {
+			r.tracker.failing++
+		} else {
+			r.tracker.failing--
+		}
+	}
+}
+
+// syncedStore reports the first successful replacement of the objects of
+// the wrapped store to its reflector.
+type syncedStore struct {
+	cache.Store
+	reflector *trackedReflector
+}
+
+// Replace implements the Replace method of the store interface.
+func (s *syncedStore) Replace(list []interface{}, resourceVersion string) error {
+	if err := s.Store.Replace(list, resourceVersion); err != nil {
+		return err
+	}
+
+	s.reflector.synced()
+	return nil
+}
+
+// healthListerWatcher reports the results of the lists and watches of the
+// wrapped ListerWatcher to its reflector.
+type healthListerWatcher struct {
+	next      cache.ListerWatcher
+	reflector *trackedReflector
+}
+
+// List implements the ListerWatcher interface.
+func (lw *healthListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	res, err := lw.next.List(options)
+	lw.reflector.result(err)
+	return res, err
+}
+
+// Watch implements the ListerWatcher interface.
+func (lw *healthListerWatcher) Watch(options metav1.ListOptions) (watchapi.Interface, error) {
+	res, err := lw.next.Watch(options)
+	lw.reflector.result(err)
+	return res, err
 }
@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s.io/kube-state-metrics/pkg/allowdenylist"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestClusterScopedResourcesIgnoreNamespaces(t *testing.T) {
+	tests := []struct {
+		namespaces []string
+		want       []string
+		dontWant   []string
+	}{
+		{
+			// No namespace matches, e.g. a namespace label selector.
+			namespaces: []string{},
+			want:       []string{`kube_node_info{node="node1"`},
+			dontWant:   []string{`kube_configmap_info{`},
+		},
+		{
+			namespaces: []string{"ns1", "ns2"},
+			want:       []string{`kube_node_info{node="node1"`, `kube_configmap_info{namespace="ns1"`},
+			dontWant:   []string{`kube_configmap_info{namespace="ns3"`},
+		},
+	}
+
+	for i, test := range tests {
+		l, err := allowdenylist.New(options.MetricSet{}, options.MetricSet{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := fake.NewSimpleClientset(
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+			&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "configmap1"}},
+			&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: "configmap3"}},
+		)
+		var nodeLists int32
+		client.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+			atomic.AddInt32(&nodeLists, 1)
+			return false, nil, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		b := NewBuilder()
+		b.WithMetrics(prometheus.NewRegistry())
+		if err := b.WithEnabledResources([]string{"configmaps", "nodes"}); err != nil {
+			t.Fatal(err)
+		}
+		b.WithKubeClient(client)
+		b.WithSharding(0, 1)
+		b.WithNamespaces(test.namespaces)
+		b.WithAllowDenyList(l)
+		b.WithGenerateStoreFunc(b.DefaultGenerateStoreFunc())
+		b.WithContext(ctx)
+		stores := b.Build()
+
+		err = wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return b.Synced(), nil
+		})
+		if err != nil {
+			t.Fatalf("%d: stores did not sync: %v", i, err)
+		}
+
+		var out strings.Builder
+		for _, s := range stores {
+			s.(*metricsstore.MetricsStore).WriteAll(&out)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%d: expected metrics to contain %s, got:\n%s", i, want, out.String())
+			}
+		}
+		for _, dontWant := range test.dontWant {
+			if strings.Contains(out.String(), dontWant) {
+				t.Errorf("%d: expected metrics not to contain %s, got:\n%s", i, dontWant, out.String())
+			}
+		}
+		if n := atomic.LoadInt32(&nodeLists); n != 1 {
+			t.Errorf("%d: expected nodes to be listed once, got %d lists", i, n)
+		}
+
+		cancel()
+	}
+}
+
+func TestEnabledResources(t *testing.T) {
+	b := NewBuilder()
+	if err := b.WithEnabledResources([]string{"pods", "configmaps", "nodes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := b.EnabledResources()
+	want := []string{"configmaps", "nodes", "pods"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected enabled resources %v, got %v", want, got)
+	}
+
+	// The returned slice is a copy.
+	got[0] = "secrets"
+	if got := b.EnabledResources(); got[0] != "configmaps" {
+		t.Fatalf("expected enabled resources not to change, got %v", got)
+	}
+}
+
+func TestDroppedSeriesResetOnRebuild(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	b := NewBuilder()
+	b.WithMetrics(registry)
+	count := func() int {
+		mfs, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, mf := range mfs {
+			if mf.GetName() == "kube_state_metrics_namespace_series_dropped" {
+				n += len(mf.GetMetric())
+			}
+		}
+		return n
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.WithContext(ctx)
+	previous := b.droppedSeriesFunc("*v1.Pod")
+	previous("ns1", 3)
+	if got := count(); got != 1 {
+		t.Fatalf("expected 1 dropped series gauge, got %d", got)
+	}
+
+	cancel()
+	b.WithContext(context.Background())
+	if got := count(); got != 0 {
+		t.Fatalf("expected the dropped series gauges to be reset, got %d", got)
+	}
+
+	// Stores of the previous context still served during the rebuild do
+	// not report again.
+	previous("ns1", 4)
+	if got := count(); got != 0 {
+		t.Fatalf("expected stores of the previous context not to report, got %d", got)
+	}
+
+	b.droppedSeriesFunc("*v1.Pod")("ns2", 5)
+	if got := count(); got != 1 {
+		t.Fatalf("expected 1 dropped series gauge, got %d", got)
+	}
+}
+
+func TestRebuildFunc(t *testing.T) {
+	b := NewBuilder()
+	rebuilds := 0
+	b.WithRebuildFunc(func() { rebuilds++ })
+
+	b.WithContext(context.Background())
+	b.WithContext(context.Background())
+	if rebuilds != 2 {
+		t.Fatalf("expected 2 rebuilds, got %d", rebuilds)
+	}
+}
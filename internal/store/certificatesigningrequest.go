@@ -56,17 +56,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapCSRFunc(func(csr *certv1beta1.CertificateSigningRequest) *metric.Family {
-				ms := []*metric.Metric{}
-				if !csr.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(csr.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(csr.CreationTimestamp),
 				}
 			}),
 		},
@@ -96,9 +87,34 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_certificatesigningrequest_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the CertificateSigningRequest.",
+			GenerateFunc: wrapCSRFunc(func(j *certv1beta1.CertificateSigningRequest) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(j.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// csrAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a CertificateSigningRequest as Prometheus labels.
+func csrAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_certificatesigningrequest_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapCSRFunc(func(j *certv1beta1.CertificateSigningRequest) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(j.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapCSRFunc(f func(*certv1beta1.CertificateSigningRequest) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		csr := obj.(*certv1beta1.CertificateSigningRequest)
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+)
+
+// Collector describes the collector of a resource.
+type Collector struct {
+	Name           string                 `json:"name"`
+	APIResources   []CollectorAPIResource `json:"apiResources"`
+	MetricFamilies []CollectorMetric      `json:"metricFamilies"`
+}
+
+// CollectorAPIResource is a Kubernetes API resource listed and watched by a
+// collector. Opt-in API resources are only listed and watched if an opt-in
+// metric family of the collector needing them is enabled.
+type CollectorAPIResource struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+	OptIn    bool   `json:"optIn,omitempty"`
+}
+
+// CollectorMetric is a metric family exposed by a collector.
+type CollectorMetric struct {
+	Name  string      `json:"name"`
+	Type  metric.Type `json:"type"`
+	Help  string      `json:"help"`
+	OptIn bool        `json:"optIn,omitempty"`
+}
+
+// ResourceForKind returns the resource of the objects of the given kind, e.g.
+// pods for Pod. The kind is matched case-insensitively and may also be given
+// as the resource itself.
+func ResourceForKind(kind string) (string, bool) {
+	for resource, d := range resourceDefinitions {
+		if strings.EqualFold(kind, d.kind) || strings.EqualFold(kind, resource) {
+			return resource, true
+		}
+	}
+	return "", false
+}
+
+// collectorMetricFamilies returns a new slice holding the given metric
+// families and annotations metric family.
+func collectorMetricFamilies(annotationsMetricFamily generator.FamilyGenerator, families ...[]generator.FamilyGenerator) []generator.FamilyGenerator {
+	all := []generator.FamilyGenerator{}
+	for _, f := range families {
+		all = append(all, f...)
+	}
+	return append(all, annotationsMetricFamily)
+}
+
+// Collectors returns the descriptions of all available collectors, sorted by
+// name.
+func Collectors() []Collector {
+	names := availableResources()
+	sort.Strings(names)
+
+	collectors := make([]Collector, 0, len(names))
+	for _, name := range names {
+		c := Collector{
+			Name:           name,
+			APIResources:   []CollectorAPIResource{},
+			MetricFamilies: []CollectorMetric{},
+		}
+		d := resourceDefinitions[name]
+		for _, a := range d.apiResources {
+			c.APIResources = append(c.APIResources, CollectorAPIResource{
+				Group:    a.group,
+				Version:  a.version,
+				Resource: a.resource,
+				OptIn:    len(a.optInMetricFamilies) > 0,
+			})
+		}
+		for _, f := range d.metricFamilies {
+			c.MetricFamilies = append(c.MetricFamilies, CollectorMetric{
+				Name:  f.Name,
+				Type:  f.Type,
+				Help:  f.Help,
+				OptIn: f.OptIn,
+			})
+		}
+		sort.Slice(c.MetricFamilies, func(i, j int) bool {
+			return c.MetricFamilies[i].Name < c.MetricFamilies[j].Name
+		})
+
+		collectors = append(collectors, c)
+	}
+
+	return collectors
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+)
+
+func TestCollectors(t *testing.T) {
+	collectors := Collectors()
+	if len(collectors) != len(resourceDefinitions) {
+		t.Fatalf("expected %d collectors, got %d", len(resourceDefinitions), len(collectors))
+	}
+
+	for i, c := range collectors {
+		if i > 0 && collectors[i-1].Name >= c.Name {
+			t.Errorf("expected collectors sorted by name, got %s before %s", collectors[i-1].Name, c.Name)
+		}
+		if len(c.APIResources) == 0 {
+			t.Errorf("expected API resources of collector %s", c.Name)
+		}
+		if len(c.MetricFamilies) == 0 {
+			t.Errorf("expected metric families of collector %s", c.Name)
+		}
+	}
+
+	for _, c := range collectors {
+		if c.Name != "nodes" {
+			continue
+		}
+		names := map[string]bool{}
+		for _, m := range c.MetricFamilies {
+			names[m.Name] = true
+		}
+		for _, name := range []string{"kube_node_info", "kube_node_annotations", "kube_node_resource_requests"} {
+			if !names[name] {
+				t.Errorf("expected metric family %s in collector nodes", name)
+			}
+		}
+	}
+}
+
+func TestResourceForKind(t *testing.T) {
+	tests := []struct {
+		Kind     string
+		Resource string
+		Found    bool
+	}{
+		{"Pod", "pods", true},
+		{"pod", "pods", true},
+		{"pods", "pods", true},
+		{"NetworkPolicy", "networkpolicies", true},
+		{"Endpoints", "endpoints", true},
+		{"Frobnicator", "", false},
+	}
+
+	for _, test := range tests {
+		resource, found := ResourceForKind(test.Kind)
+		if resource != test.Resource || found != test.Found {
+			t.Errorf("expected %q, %v for kind %s, got %q, %v", test.Resource, test.Found, test.Kind, resource, found)
+		}
+	}
+}
@@ -51,28 +51,64 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
-				ms := []*metric.Metric{}
+				return &metric.Family{
+					Metrics: createdMetric(c.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_configmap_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the configmap.",
+			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(c.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
+		{
+			Name: "kube_configmap_data_bytes",
+			Type: metric.Gauge,
+			Help: "Total size in bytes of the keys and values stored in the configmap.",
+			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
+				var size int
+				for k, v := range c.Data {
+					size += len(k) + len(v)
+				}
+				for k, v := range c.BinaryData {
+					size += len(k) + len(v)
+				}
 
-				if !c.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
+				return &metric.Family{
+					Metrics: []*metric.Metric{{
 						LabelKeys:   []string{},
 						LabelValues: []string{},
-						Value:       float64(c.CreationTimestamp.Unix()),
-					})
+						Value:       float64(size),
+					}},
 				}
-
+			}),
+		},
+		{
+			Name: "kube_configmap_data_keys",
+			Type: metric.Gauge,
+			Help: "Number of keys stored in the configmap.",
+			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: []*metric.Metric{{
+						LabelKeys:   []string{},
+						LabelValues: []string{},
+						Value:       float64(len(c.Data) + len(c.BinaryData)),
+					}},
 				}
 			}),
 		},
 		{
-			Name: "kube_configmap_metadata_resource_version",
+			Name: "kube_configmap_owner",
 			Type: metric.Gauge,
-			Help: "Resource version representing a specific version of the configmap.",
+			Help: "Information about the ConfigMap's owner.",
 			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
 				return &metric.Family{
-					Metrics: resourceVersionMetric(c.ObjectMeta.ResourceVersion),
+					Metrics: ownerMetrics(c.GetOwnerReferences()),
 				}
 			}),
 		},
@@ -90,6 +126,21 @@ func createConfigMapListWatch(kubeClient clientset.Interface, ns string) cache.L
 	}
 }
 
+// configMapAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a ConfigMap as Prometheus labels.
+func configMapAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_configmap_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(c.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapConfigMapFunc(f func(*v1.ConfigMap) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		configMap := obj.(*v1.ConfigMap)
@@ -69,6 +69,30 @@ func TestConfigMapStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_configmap_info", "kube_configmap_created", "kube_configmap_metadata_resource_version"},
 		},
+		{
+			Obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap3",
+					Namespace: "ns3",
+				},
+				Data: map[string]string{
+					"config.yaml": "foo: bar",
+					"empty":       "",
+				},
+				BinaryData: map[string][]byte{
+					"blob": {0x00, 0x01, 0x02},
+				},
+			},
+			Want: `
+				# HELP kube_configmap_data_bytes Total size in bytes of the keys and values stored in the configmap.
+				# HELP kube_configmap_data_keys Number of keys stored in the configmap.
+				# TYPE kube_configmap_data_bytes gauge
+				# TYPE kube_configmap_data_keys gauge
+				kube_configmap_data_bytes{configmap="configmap3",namespace="ns3"} 31
+				kube_configmap_data_keys{configmap="configmap3",namespace="ns3"} 3
+				`,
+			MetricNames: []string{"kube_configmap_data_bytes", "kube_configmap_data_keys"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(configMapMetricFamilies)
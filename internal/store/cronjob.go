@@ -76,17 +76,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
-				ms := []*metric.Metric{}
-				if !j.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(j.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(j.CreationTimestamp),
 				}
 			}),
 		},
@@ -167,6 +158,42 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_cronjob_spec_successful_job_history_limit",
+			Type: metric.Gauge,
+			Help: "Successful job history limit tells the controller how many successful jobs to retain.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if j.Spec.SuccessfulJobsHistoryLimit != nil {
+					ms = append(ms, &metric.Metric{
+						Value: float64(*j.Spec.SuccessfulJobsHistoryLimit),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name: "kube_cronjob_spec_failed_job_history_limit",
+			Type: metric.Gauge,
+			Help: "Failed job history limit tells the controller how many failed jobs to retain.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if j.Spec.FailedJobsHistoryLimit != nil {
+					ms = append(ms, &metric.Metric{
+						Value: float64(*j.Spec.FailedJobsHistoryLimit),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_cronjob_next_schedule_time",
 			Type: metric.Gauge,
@@ -191,9 +218,58 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_cronjob_owner",
+			Type: metric.Gauge,
+			Help: "Information about the CronJob's owner.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(j.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_cronjob_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the CronJob.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(j.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
+		{
+			Name: "kube_cronjob_metadata_generation",
+			Type: metric.Gauge,
+			Help: "Sequence number representing a specific generation of the desired state.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(j.ObjectMeta.Generation),
+						},
+					},
+				}
+			}),
+		},
 	}
 )
 
+// cronJobAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a CronJob as Prometheus labels.
+func cronJobAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_cronjob_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(j.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapCronJobFunc(f func(*batchv1beta1.CronJob) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		cronJob := obj.(*batchv1beta1.CronJob)
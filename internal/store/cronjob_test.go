@@ -30,9 +30,11 @@ import (
 )
 
 var (
-	SuspendTrue                      = true
-	SuspendFalse                     = false
-	StartingDeadlineSeconds300 int64 = 300
+	SuspendTrue                       = true
+	SuspendFalse                      = false
+	StartingDeadlineSeconds300  int64 = 300
+	SuccessfulJobsHistoryLimit3 int32 = 3
+	FailedJobsHistoryLimit1     int32 = 1
 
 	// "1520742896" is "2018/3/11 12:34:56" in "Asia/Shanghai".
 	ActiveRunningCronJob1LastScheduleTime          = time.Unix(1520742896, 0)
@@ -241,6 +243,31 @@ func TestCronJobStore(t *testing.T) {
 					float64(ActiveCronJob1NoLastScheduledNextScheduleTime.Unix())/math.Pow10(9)),
 			MetricNames: []string{"kube_cronjob_next_schedule_time", "kube_cronjob_spec_starting_deadline_seconds", "kube_cronjob_status_active", "kube_cronjob_spec_suspend", "kube_cronjob_info", "kube_cronjob_created", "kube_cronjob_labels"},
 		},
+		{
+			Obj: &batchv1beta1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "HistoryLimitCronJob1",
+					Namespace:         "ns1",
+					CreationTimestamp: metav1.Time{Time: ActiveCronJob1NoLastScheduledCreationTimestamp},
+				},
+				Spec: batchv1beta1.CronJobSpec{
+					ConcurrencyPolicy:          "Replace",
+					SuccessfulJobsHistoryLimit: &SuccessfulJobsHistoryLimit3,
+					FailedJobsHistoryLimit:     &FailedJobsHistoryLimit1,
+					Schedule:                   "0 */6 * * *",
+					Suspend:                    &SuspendTrue,
+				},
+			},
+			Want: `
+				# HELP kube_cronjob_spec_failed_job_history_limit Failed job history limit tells the controller how many failed jobs to retain.
+				# HELP kube_cronjob_spec_successful_job_history_limit Successful job history limit tells the controller how many successful jobs to retain.
+				# TYPE kube_cronjob_spec_failed_job_history_limit gauge
+				# TYPE kube_cronjob_spec_successful_job_history_limit gauge
+				kube_cronjob_spec_failed_job_history_limit{cronjob="HistoryLimitCronJob1",namespace="ns1"} 1
+				kube_cronjob_spec_successful_job_history_limit{cronjob="HistoryLimitCronJob1",namespace="ns1"} 3
+`,
+			MetricNames: []string{"kube_cronjob_spec_failed_job_history_limit", "kube_cronjob_spec_successful_job_history_limit"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(cronJobMetricFamilies)
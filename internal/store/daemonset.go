@@ -20,9 +20,11 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
 
 	"k8s.io/kube-state-metrics/pkg/metric"
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
@@ -39,18 +41,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !d.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(d.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(d.CreationTimestamp),
 				}
 			}),
 		},
@@ -150,6 +142,31 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_daemonset_status_condition",
+			Type: metric.Gauge,
+			Help: "The current status conditions of a daemonset.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				ms := make([]*metric.Metric, len(d.Status.Conditions)*len(conditionStatuses))
+
+				for i, c := range d.Status.Conditions {
+					conditionMetrics := addConditionMetrics(c.Status)
+
+					for j, m := range conditionMetrics {
+						metric := m
+
+						metric.LabelKeys = []string{"condition", "status", "reason"}
+						metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
+						metric.LabelValues = append(metric.LabelValues, c.Reason)
+						ms[i*len(conditionStatuses)+j] = metric
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_daemonset_updated_number_scheduled",
 			Type: metric.Gauge,
@@ -164,6 +181,46 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_daemonset_spec_update_strategy",
+			Type: metric.Gauge,
+			Help: "The update strategy used to replace existing daemon pods with new pods.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"type"},
+							LabelValues: []string{string(d.Spec.UpdateStrategy.Type)},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name: "kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable",
+			Type: metric.Gauge,
+			Help: "Maximum number of nodes with unavailable daemon pods during a rolling update of a daemonset.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				if d.Spec.UpdateStrategy.RollingUpdate == nil || d.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable == nil {
+					return &metric.Family{}
+				}
+
+				maxUnavailable, err := intstr.GetValueFromIntOrPercent(d.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable, int(d.Status.DesiredNumberScheduled), true)
+				if err != nil {
+					klog.Errorf("Failed to get max unavailable of daemonset %s/%s: %v", d.Namespace, d.Name, err)
+					return &metric.Family{}
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(maxUnavailable),
+						},
+					},
+				}
+			}),
+		},
 		{
 			Name: "kube_daemonset_metadata_generation",
 			Type: metric.Gauge,
@@ -180,6 +237,20 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_daemonset_spec_generation_mismatch",
+			Type: metric.Gauge,
+			Help: "Whether the generation observed by the daemonset controller differs from the desired generation.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: boolFloat64(d.ObjectMeta.Generation != d.Status.ObservedGeneration),
+						},
+					},
+				}
+			}),
+		},
 		{
 			Name: descDaemonSetLabelsName,
 			Type: metric.Gauge,
@@ -197,9 +268,44 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_daemonset_owner",
+			Type: metric.Gauge,
+			Help: "Information about the DaemonSet's owner.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(d.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_daemonset_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the DaemonSet.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(d.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// daemonSetAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a DaemonSet as Prometheus labels.
+func daemonSetAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_daemonset_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(d.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapDaemonSetFunc(f func(*v1.DaemonSet) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		daemonSet := obj.(*v1.DaemonSet)
@@ -225,3 +331,20 @@ func createDaemonSetListWatch(kubeClient clientset.Interface, ns string) cache.L
 		},
 	}
 }
+
+// daemonSetConditionTransitionTime returns the last transition time of the
+// condition the given kube_daemonset_status_condition series was generated for.
+func daemonSetConditionTransitionTime(obj interface{}, m *metric.Metric) metav1.Time {
+	o, ok := obj.(*v1.DaemonSet)
+	if !ok {
+		return metav1.Time{}
+	}
+
+	condition := conditionLabelValue(m)
+	for _, c := range o.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.LastTransitionTime
+		}
+	}
+	return metav1.Time{}
+}
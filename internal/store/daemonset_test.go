@@ -21,11 +21,15 @@ import (
 	"time"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 )
 
+var ds5MaxUnavailable = intstr.FromString("25%")
+
 func TestDaemonSetStore(t *testing.T) {
 	cases := []generateMetricsTestCase{
 		{
@@ -215,6 +219,126 @@ func TestDaemonSetStore(t *testing.T) {
 				"kube_daemonset_updated_number_scheduled",
 			},
 		},
+		{
+			Obj: &v1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ds4",
+					Namespace: "ns4",
+				},
+				Status: v1.DaemonSetStatus{
+					Conditions: []v1.DaemonSetCondition{
+						{Type: v1.DaemonSetConditionType("Progressing"), Status: corev1.ConditionFalse, Reason: "RolloutStuck"},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_daemonset_status_condition The current status conditions of a daemonset.
+				# TYPE kube_daemonset_status_condition gauge
+				kube_daemonset_status_condition{condition="Progressing",daemonset="ds4",namespace="ns4",reason="RolloutStuck",status="false"} 1
+				kube_daemonset_status_condition{condition="Progressing",daemonset="ds4",namespace="ns4",reason="RolloutStuck",status="true"} 0
+				kube_daemonset_status_condition{condition="Progressing",daemonset="ds4",namespace="ns4",reason="RolloutStuck",status="unknown"} 0
+`,
+			MetricNames: []string{
+				"kube_daemonset_status_condition",
+			},
+		},
+		{
+			Obj: &v1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ds5",
+					Namespace: "ns5",
+				},
+				Spec: v1.DaemonSetSpec{
+					UpdateStrategy: v1.DaemonSetUpdateStrategy{
+						Type: v1.RollingUpdateDaemonSetStrategyType,
+						RollingUpdate: &v1.RollingUpdateDaemonSet{
+							MaxUnavailable: &ds5MaxUnavailable,
+						},
+					},
+				},
+				Status: v1.DaemonSetStatus{
+					DesiredNumberScheduled: 20,
+				},
+			},
+			Want: `
+				# HELP kube_daemonset_spec_update_strategy The update strategy used to replace existing daemon pods with new pods.
+				# HELP kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable Maximum number of nodes with unavailable daemon pods during a rolling update of a daemonset.
+				# TYPE kube_daemonset_spec_update_strategy gauge
+				# TYPE kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable gauge
+				kube_daemonset_spec_update_strategy{daemonset="ds5",namespace="ns5",type="RollingUpdate"} 1
+				kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable{daemonset="ds5",namespace="ns5"} 5
+`,
+			MetricNames: []string{
+				"kube_daemonset_spec_update_strategy",
+			},
+		},
+		{
+			Obj: &v1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ds6",
+					Namespace: "ns6",
+				},
+				Spec: v1.DaemonSetSpec{
+					UpdateStrategy: v1.DaemonSetUpdateStrategy{
+						Type: v1.OnDeleteDaemonSetStrategyType,
+					},
+				},
+			},
+			Want: `
+				# HELP kube_daemonset_spec_update_strategy The update strategy used to replace existing daemon pods with new pods.
+				# HELP kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable Maximum number of nodes with unavailable daemon pods during a rolling update of a daemonset.
+				# TYPE kube_daemonset_spec_update_strategy gauge
+				# TYPE kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable gauge
+				kube_daemonset_spec_update_strategy{daemonset="ds6",namespace="ns6",type="OnDelete"} 1
+`,
+			MetricNames: []string{
+				"kube_daemonset_spec_update_strategy",
+			},
+		},
+		{
+			Obj: &v1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "ds7",
+					Namespace:  "ns7",
+					Generation: 2,
+				},
+				Status: v1.DaemonSetStatus{
+					ObservedGeneration: 1,
+				},
+			},
+			Want: `
+				# HELP kube_daemonset_spec_generation_mismatch Whether the generation observed by the daemonset controller differs from the desired generation.
+				# TYPE kube_daemonset_spec_generation_mismatch gauge
+				kube_daemonset_spec_generation_mismatch{daemonset="ds7",namespace="ns7"} 1
+`,
+			MetricNames: []string{
+				"kube_daemonset_spec_generation_mismatch",
+			},
+		},
+		// Verify malformed max unavailable values are skipped.
+		{
+			Obj: &v1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ds8",
+					Namespace: "ns8",
+				},
+				Spec: v1.DaemonSetSpec{
+					UpdateStrategy: v1.DaemonSetUpdateStrategy{
+						Type: v1.RollingUpdateDaemonSetStrategyType,
+						RollingUpdate: &v1.RollingUpdateDaemonSet{
+							MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "many"},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable Maximum number of nodes with unavailable daemon pods during a rolling update of a daemonset.
+				# TYPE kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable gauge
+`,
+			MetricNames: []string{
+				"kube_daemonset_spec_update_strategy_rollingupdate_max_unavailable",
+			},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(daemonSetMetricFamilies)
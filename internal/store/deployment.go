@@ -17,39 +17,45 @@ limitations under the License.
 package store
 
 import (
+	"sync"
+	"time"
+
 	"k8s.io/kube-state-metrics/pkg/metric"
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 
 	v1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
+// deploymentPausedReason is the reason the deployment controller sets on the
+// Progressing condition of a paused deployment.
+const deploymentPausedReason = "DeploymentPaused"
+
 var (
 	descDeploymentLabelsName          = "kube_deployment_labels"
 	descDeploymentLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descDeploymentLabelsDefaultLabels = []string{"namespace", "deployment"}
+)
 
-	deploymentMetricFamilies = []generator.FamilyGenerator{
+// deploymentMetricFamilies returns the metric families of deployments. The
+// given tracker remembers since when deployments have had fewer available
+// replicas than desired, and is owned by the store the families are generated
+// for.
+func deploymentMetricFamilies(drift *replicaDriftTracker) []generator.FamilyGenerator {
+	return []generator.FamilyGenerator{
 		{
 			Name: "kube_deployment_created",
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !d.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(d.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(d.CreationTimestamp),
 				}
 			}),
 		},
@@ -95,6 +101,25 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_deployment_status_replicas_unavailable_since",
+			Type: metric.Gauge,
+			Help: "Unix timestamp since when the deployment has had fewer available replicas than desired.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+				ms := []*metric.Metric{}
+
+				drifting := d.Spec.Replicas != nil && d.Status.AvailableReplicas < *d.Spec.Replicas
+				if since, ok := drift.since(d.UID, drifting); ok {
+					ms = append(ms, &metric.Metric{
+						Value: float64(since.Unix()),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_deployment_status_replicas_updated",
 			Type: metric.Gauge,
@@ -136,8 +161,9 @@ var (
 					for j, m := range conditionMetrics {
 						metric := m
 
-						metric.LabelKeys = []string{"condition", "status"}
+						metric.LabelKeys = []string{"condition", "status", "reason"}
 						metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
+						metric.LabelValues = append(metric.LabelValues, c.Reason)
 						ms[i*len(conditionStatuses)+j] = metric
 					}
 				}
@@ -175,6 +201,50 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_deployment_spec_paused_since",
+			Type: metric.Gauge,
+			Help: "Unix timestamp since when the deployment has been paused.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if !d.Spec.Paused {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for _, c := range d.Status.Conditions {
+					if c.Type == v1.DeploymentProgressing && c.Reason == deploymentPausedReason && !c.LastTransitionTime.IsZero() {
+						ms = append(ms, &metric.Metric{
+							Value: float64(c.LastTransitionTime.Unix()),
+						})
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name: "kube_deployment_spec_progress_deadline_seconds",
+			Type: metric.Gauge,
+			Help: "The maximum time in seconds for a deployment to make progress before it is considered to be failed.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if d.Spec.ProgressDeadlineSeconds != nil {
+					ms = append(ms, &metric.Metric{
+						Value: float64(*d.Spec.ProgressDeadlineSeconds),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_deployment_spec_strategy_rollingupdate_max_unavailable",
 			Type: metric.Gauge,
@@ -235,6 +305,20 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_deployment_spec_generation_mismatch",
+			Type: metric.Gauge,
+			Help: "Whether the generation observed by the deployment controller differs from the desired generation.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: boolFloat64(d.ObjectMeta.Generation != d.Status.ObservedGeneration),
+						},
+					},
+				}
+			}),
+		},
 		{
 			Name: descDeploymentLabelsName,
 			Type: metric.Gauge,
@@ -252,8 +336,43 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_deployment_owner",
+			Type: metric.Gauge,
+			Help: "Information about the Deployment's owner.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(d.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_deployment_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(d.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
-)
+}
+
+// deploymentAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Deployment as Prometheus labels.
+func deploymentAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_deployment_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(d.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
 
 func wrapDeploymentFunc(f func(*v1.Deployment) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
@@ -270,6 +389,63 @@ func wrapDeploymentFunc(f func(*v1.Deployment) *metric.Family) func(interface{})
 	}
 }
 
+// replicaDriftTracker remembers when a workload was first seen with fewer
+// replicas than desired, as workloads do not record when their replicas
+// started to drift.
+type replicaDriftTracker struct {
+	mtx   sync.Mutex
+	first map[types.UID]time.Time
+	now   func() time.Time
+}
+
+func newReplicaDriftTracker() *replicaDriftTracker {
+	return &replicaDriftTracker{
+		first: map[types.UID]time.Time{},
+		now:   time.Now,
+	}
+}
+
+// since returns the time since when the workload with the given UID has been
+// drifting, i.e. since when it was first observed to be drifting by
+// kube-state-metrics.
+func (t *replicaDriftTracker) since(uid types.UID, drifting bool) (time.Time, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !drifting {
+		delete(t.first, uid)
+		return time.Time{}, false
+	}
+
+	first, ok := t.first[uid]
+	if !ok {
+		first = t.now()
+		t.first[uid] = first
+	}
+
+	return first, true
+}
+
+// forget implements the objectTracker interface.
+func (t *replicaDriftTracker) forget(uid types.UID) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	delete(t.first, uid)
+}
+
+// retain implements the objectTracker interface.
+func (t *replicaDriftTracker) retain(uids map[types.UID]struct{}) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for uid := range t.first {
+		if _, ok := uids[uid]; !ok {
+			delete(t.first, uid)
+		}
+	}
+}
+
 func createDeploymentListWatch(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
 	return &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -280,3 +456,21 @@ func createDeploymentListWatch(kubeClient clientset.Interface, ns string) cache.
 		},
 	}
 }
+
+// deploymentConditionTransitionTime returns the last transition time of the
+// condition the given kube_deployment_status_condition series was generated
+// for.
+func deploymentConditionTransitionTime(obj interface{}, m *metric.Metric) metav1.Time {
+	o, ok := obj.(*v1.Deployment)
+	if !ok {
+		return metav1.Time{}
+	}
+
+	condition := conditionLabelValue(m)
+	for _, c := range o.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.LastTransitionTime
+		}
+	}
+	return metav1.Time{}
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 )
 
 var (
@@ -35,6 +36,10 @@ var (
 	depl1MaxUnavailable = intstr.FromInt(10)
 	depl2MaxUnavailable = intstr.FromString("20%")
 
+	depl1ProgressDeadlineSeconds int32 = 600
+
+	depl4OwnerIsController = true
+
 	depl1MaxSurge = intstr.FromInt(10)
 	depl2MaxSurge = intstr.FromString("20%")
 )
@@ -45,10 +50,18 @@ func TestDeploymentStore(t *testing.T) {
 	const metadata = `
 		# HELP kube_deployment_created Unix creation timestamp
 		# TYPE kube_deployment_created gauge
+		# HELP kube_deployment_metadata_resource_version Resource version representing a specific version of the Deployment.
+		# TYPE kube_deployment_metadata_resource_version gauge
 		# HELP kube_deployment_metadata_generation Sequence number representing a specific generation of the desired state.
 		# TYPE kube_deployment_metadata_generation gauge
+		# HELP kube_deployment_spec_generation_mismatch Whether the generation observed by the deployment controller differs from the desired generation.
+		# TYPE kube_deployment_spec_generation_mismatch gauge
 		# HELP kube_deployment_spec_paused Whether the deployment is paused and will not be processed by the deployment controller.
 		# TYPE kube_deployment_spec_paused gauge
+		# HELP kube_deployment_spec_paused_since Unix timestamp since when the deployment has been paused.
+		# TYPE kube_deployment_spec_paused_since gauge
+		# HELP kube_deployment_spec_progress_deadline_seconds The maximum time in seconds for a deployment to make progress before it is considered to be failed.
+		# TYPE kube_deployment_spec_progress_deadline_seconds gauge
 		# HELP kube_deployment_spec_replicas Number of desired pods for a deployment.
 		# TYPE kube_deployment_spec_replicas gauge
 		# HELP kube_deployment_status_replicas The number of replicas per deployment.
@@ -57,6 +70,8 @@ func TestDeploymentStore(t *testing.T) {
 		# TYPE kube_deployment_status_replicas_available gauge
 		# HELP kube_deployment_status_replicas_unavailable The number of unavailable replicas per deployment.
 		# TYPE kube_deployment_status_replicas_unavailable gauge
+		# HELP kube_deployment_status_replicas_unavailable_since Unix timestamp since when the deployment has had fewer available replicas than desired.
+		# TYPE kube_deployment_status_replicas_unavailable_since gauge
 		# HELP kube_deployment_status_replicas_updated The number of updated replicas per deployment.
 		# TYPE kube_deployment_status_replicas_updated gauge
 		# HELP kube_deployment_status_observed_generation The generation observed by the deployment controller.
@@ -69,6 +84,8 @@ func TestDeploymentStore(t *testing.T) {
 		# TYPE kube_deployment_spec_strategy_rollingupdate_max_surge gauge
 		# HELP kube_deployment_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_deployment_labels gauge
+		# HELP kube_deployment_owner Information about the Deployment's owner.
+		# TYPE kube_deployment_owner gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -94,7 +111,8 @@ func TestDeploymentStore(t *testing.T) {
 					},
 				},
 				Spec: v1.DeploymentSpec{
-					Replicas: &depl1Replicas,
+					Replicas:                &depl1Replicas,
+					ProgressDeadlineSeconds: &depl1ProgressDeadlineSeconds,
 					Strategy: v1.DeploymentStrategy{
 						RollingUpdate: &v1.RollingUpdateDeployment{
 							MaxUnavailable: &depl1MaxUnavailable,
@@ -107,21 +125,25 @@ func TestDeploymentStore(t *testing.T) {
         kube_deployment_created{deployment="depl1",namespace="ns1"} 1.5e+09
         kube_deployment_labels{deployment="depl1",label_app="example1",namespace="ns1"} 1
         kube_deployment_metadata_generation{deployment="depl1",namespace="ns1"} 21
+        kube_deployment_spec_generation_mismatch{deployment="depl1",namespace="ns1"} 1
         kube_deployment_spec_paused{deployment="depl1",namespace="ns1"} 0
+        kube_deployment_spec_progress_deadline_seconds{deployment="depl1",namespace="ns1"} 600
         kube_deployment_spec_replicas{deployment="depl1",namespace="ns1"} 200
         kube_deployment_spec_strategy_rollingupdate_max_surge{deployment="depl1",namespace="ns1"} 10
         kube_deployment_spec_strategy_rollingupdate_max_unavailable{deployment="depl1",namespace="ns1"} 10
         kube_deployment_status_observed_generation{deployment="depl1",namespace="ns1"} 111
         kube_deployment_status_replicas_available{deployment="depl1",namespace="ns1"} 10
         kube_deployment_status_replicas_unavailable{deployment="depl1",namespace="ns1"} 5
+        kube_deployment_status_replicas_unavailable_since{deployment="depl1",namespace="ns1"} 1.6e+09
         kube_deployment_status_replicas_updated{deployment="depl1",namespace="ns1"} 2
         kube_deployment_status_replicas{deployment="depl1",namespace="ns1"} 15
-        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Available",status="true"} 1
-        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Progressing",status="true"} 1
-        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Available",status="false"} 0
-        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Progressing",status="false"} 0
-        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Available",status="unknown"} 0
-        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Progressing",status="unknown"} 0
+        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Available",status="true",reason=""} 1
+        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Progressing",status="true",reason=""} 1
+        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Available",status="false",reason=""} 0
+        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Progressing",status="false",reason=""} 0
+        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Available",status="unknown",reason=""} 0
+        kube_deployment_status_condition{deployment="depl1",namespace="ns1",condition="Progressing",status="unknown",reason=""} 0
+        kube_deployment_owner{deployment="depl1",namespace="ns1",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 `,
 		},
 		{
@@ -142,8 +164,8 @@ func TestDeploymentStore(t *testing.T) {
 					ObservedGeneration:  1111,
 					Conditions: []v1.DeploymentCondition{
 						{Type: v1.DeploymentAvailable, Status: corev1.ConditionFalse},
-						{Type: v1.DeploymentProgressing, Status: corev1.ConditionFalse},
-						{Type: v1.DeploymentReplicaFailure, Status: corev1.ConditionTrue},
+						{Type: v1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+						{Type: v1.DeploymentReplicaFailure, Status: corev1.ConditionTrue, Reason: "ReplicaSetCreateError"},
 					},
 				},
 				Spec: v1.DeploymentSpec{
@@ -160,6 +182,7 @@ func TestDeploymentStore(t *testing.T) {
 			Want: metadata + `
        	kube_deployment_labels{deployment="depl2",label_app="example2",namespace="ns2"} 1
         kube_deployment_metadata_generation{deployment="depl2",namespace="ns2"} 14
+        kube_deployment_spec_generation_mismatch{deployment="depl2",namespace="ns2"} 1
         kube_deployment_spec_paused{deployment="depl2",namespace="ns2"} 1
         kube_deployment_spec_replicas{deployment="depl2",namespace="ns2"} 5
         kube_deployment_spec_strategy_rollingupdate_max_surge{deployment="depl2",namespace="ns2"} 1
@@ -169,24 +192,147 @@ func TestDeploymentStore(t *testing.T) {
         kube_deployment_status_replicas_unavailable{deployment="depl2",namespace="ns2"} 0
         kube_deployment_status_replicas_updated{deployment="depl2",namespace="ns2"} 1
         kube_deployment_status_replicas{deployment="depl2",namespace="ns2"} 10
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Available",status="true"} 0
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Progressing",status="true"} 0
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="ReplicaFailure",status="true"} 1
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Available",status="false"} 1
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Progressing",status="false"} 1
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="ReplicaFailure",status="false"} 0
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Available",status="unknown"} 0
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Progressing",status="unknown"} 0
-        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="ReplicaFailure",status="unknown"} 0
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Available",status="true",reason=""} 0
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Progressing",status="true",reason="ProgressDeadlineExceeded"} 0
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="ReplicaFailure",status="true",reason="ReplicaSetCreateError"} 1
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Available",status="false",reason=""} 1
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Progressing",status="false",reason="ProgressDeadlineExceeded"} 1
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="ReplicaFailure",status="false",reason="ReplicaSetCreateError"} 0
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Available",status="unknown",reason=""} 0
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="Progressing",status="unknown",reason="ProgressDeadlineExceeded"} 0
+        kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="ReplicaFailure",status="unknown",reason="ReplicaSetCreateError"} 0
+       	kube_deployment_owner{deployment="depl2",namespace="ns2",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
+`,
+		},
+		{
+			Obj: &v1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "depl3",
+					Namespace: "ns3",
+				},
+				Status: v1.DeploymentStatus{
+					Conditions: []v1.DeploymentCondition{
+						{Type: v1.DeploymentAvailable, Status: corev1.ConditionTrue},
+						{Type: v1.DeploymentProgressing, Status: corev1.ConditionUnknown, Reason: "DeploymentPaused", LastTransitionTime: metav1.Time{Time: time.Unix(1500000000, 0)}},
+					},
+				},
+				Spec: v1.DeploymentSpec{
+					Paused:   true,
+					Replicas: &depl2Replicas,
+				},
+			},
+			Want: `
+				# HELP kube_deployment_spec_paused Whether the deployment is paused and will not be processed by the deployment controller.
+				# HELP kube_deployment_spec_paused_since Unix timestamp since when the deployment has been paused.
+				# TYPE kube_deployment_spec_paused gauge
+				# TYPE kube_deployment_spec_paused_since gauge
+				kube_deployment_spec_paused{deployment="depl3",namespace="ns3"} 1
+				kube_deployment_spec_paused_since{deployment="depl3",namespace="ns3"} 1.5e+09
 `,
+			MetricNames: []string{"kube_deployment_spec_paused"},
+		},
+		{
+			Obj: &v1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "depl-owned",
+					Namespace: "ns4",
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							Kind:       "Application",
+							Name:       "app1",
+							Controller: &depl4OwnerIsController,
+						},
+						{
+							Kind: "ConfigMap",
+							Name: "cm1",
+						},
+					},
+				},
+				Spec: v1.DeploymentSpec{
+					Replicas: &depl2Replicas,
+				},
+			},
+			Want: `
+				# HELP kube_deployment_owner Information about the Deployment's owner.
+				# TYPE kube_deployment_owner gauge
+				kube_deployment_owner{deployment="depl-owned",namespace="ns4",owner_is_controller="true",owner_kind="Application",owner_name="app1"} 1
+				kube_deployment_owner{deployment="depl-owned",namespace="ns4",owner_is_controller="false",owner_kind="ConfigMap",owner_name="cm1"} 1
+`,
+			MetricNames: []string{"kube_deployment_owner"},
+		},
+		{
+			Obj: &v1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "depl5",
+					Namespace:  "ns5",
+					Generation: 3,
+				},
+				Spec: v1.DeploymentSpec{
+					Replicas: &depl2Replicas,
+				},
+				Status: v1.DeploymentStatus{
+					ObservedGeneration: 3,
+				},
+			},
+			Want: `
+				# HELP kube_deployment_spec_generation_mismatch Whether the generation observed by the deployment controller differs from the desired generation.
+				# TYPE kube_deployment_spec_generation_mismatch gauge
+				kube_deployment_spec_generation_mismatch{deployment="depl5",namespace="ns5"} 0
+`,
+			MetricNames: []string{"kube_deployment_spec_generation_mismatch"},
 		},
 	}
 
 	for i, c := range cases {
-		c.Func = generator.ComposeMetricGenFuncs(deploymentMetricFamilies)
-		c.Headers = generator.ExtractMetricFamilyHeaders(deploymentMetricFamilies)
+		drift := newReplicaDriftTracker()
+		drift.now = func() time.Time { return time.Unix(1600000000, 0) }
+		families := deploymentMetricFamilies(drift)
+		c.Func = generator.ComposeMetricGenFuncs(families)
+		c.Headers = generator.ExtractMetricFamilyHeaders(families)
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+func TestReplicaDriftTracker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	drift := newReplicaDriftTracker()
+	drift.now = func() time.Time { return now }
+
+	if _, ok := drift.since("uid-1", false); ok {
+		t.Fatal("expected workload with all replicas not to report a drift")
+	}
+	since, ok := drift.since("uid-1", true)
+	if !ok || !since.Equal(now) {
+		t.Fatalf("expected workload to be drifting since %v, got %v (%v)", now, since, ok)
+	}
+	drift.now = func() time.Time { return now.Add(time.Hour) }
+	if since, _ := drift.since("uid-1", true); !since.Equal(now) {
+		t.Fatalf("expected workload to keep drifting since %v, got %v", now, since)
+	}
+	drift.since("uid-2", true)
+
+	// Workloads deleted or no longer listed while drifting are forgotten by
+	// the store owning the tracker.
+	families := deploymentMetricFamilies(drift)
+	store := &trackingStore{
+		Store: metricsstore.NewMetricsStore(
+			generator.ExtractMetricFamilyHeaders(families),
+			generator.ComposeMetricGenFuncs(families),
+		),
+		tracker: drift,
+	}
+	if err := store.Delete(&v1.Deployment{ObjectMeta: metav1.ObjectMeta{UID: "uid-1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := drift.first["uid-1"]; ok || len(drift.first) != 1 {
+		t.Fatalf("expected tracker to forget deleted deployment, got %v", drift.first)
+	}
+	if err := store.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(drift.first) != 0 {
+		t.Fatalf("expected tracker to forget deployments which are no longer listed, got %v", drift.first)
+	}
+}
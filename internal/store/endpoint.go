@@ -17,6 +17,8 @@ limitations under the License.
 package store
 
 import (
+	"strconv"
+
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -53,17 +55,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !e.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(e.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(e.CreationTimestamp),
 				}
 			}),
 		},
@@ -121,9 +114,69 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_endpoint_ports",
+			Type: metric.Gauge,
+			Help: "Information about the Endpoint ports.",
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) *metric.Family {
+				ms := []*metric.Metric{}
+				seen := map[v1.EndpointPort]bool{}
+				for _, s := range e.Subsets {
+					for _, port := range s.Ports {
+						if seen[port] {
+							continue
+						}
+						seen[port] = true
+						ms = append(ms, &metric.Metric{
+							LabelKeys:   []string{"port_name", "port_protocol", "port_number"},
+							LabelValues: []string{port.Name, string(port.Protocol), strconv.FormatInt(int64(port.Port), 10)},
+							Value:       1,
+						})
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name: "kube_endpoint_owner",
+			Type: metric.Gauge,
+			Help: "Information about the Endpoint's owner.",
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(e.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_endpoint_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Endpoint.",
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(e.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// endpointAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of an Endpoints as Prometheus labels.
+func endpointAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_endpoint_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(e.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapEndpointFunc(f func(*v1.Endpoints) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		endpoint := obj.(*v1.Endpoints)
@@ -36,10 +36,16 @@ func TestEndpointStore(t *testing.T) {
 		# TYPE kube_endpoint_address_not_ready gauge
 		# HELP kube_endpoint_created Unix creation timestamp
 		# TYPE kube_endpoint_created gauge
+		# HELP kube_endpoint_metadata_resource_version Resource version representing a specific version of the Endpoint.
+		# TYPE kube_endpoint_metadata_resource_version gauge
 		# HELP kube_endpoint_info Information about endpoint.
 		# TYPE kube_endpoint_info gauge
 		# HELP kube_endpoint_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_endpoint_labels gauge
+		# HELP kube_endpoint_ports Information about the Endpoint ports.
+		# TYPE kube_endpoint_ports gauge
+		# HELP kube_endpoint_owner Information about the Endpoint's owner.
+		# TYPE kube_endpoint_owner gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -89,8 +95,58 @@ func TestEndpointStore(t *testing.T) {
 				kube_endpoint_created{endpoint="test-endpoint",namespace="default"} 1.5e+09
 				kube_endpoint_info{endpoint="test-endpoint",namespace="default"} 1
 				kube_endpoint_labels{endpoint="test-endpoint",label_app="foobar",namespace="default"} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="8080",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="8081",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="8443",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="9090",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="1234",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="5678",port_protocol=""} 1
+				kube_endpoint_owner{endpoint="test-endpoint",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
+		{
+			Obj: &v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-endpoint-empty",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Namespace:         "default",
+				},
+			},
+			Want: metadata + `
+				kube_endpoint_address_available{endpoint="test-endpoint-empty",namespace="default"} 0
+				kube_endpoint_address_not_ready{endpoint="test-endpoint-empty",namespace="default"} 0
+				kube_endpoint_created{endpoint="test-endpoint-empty",namespace="default"} 1.5e+09
+				kube_endpoint_info{endpoint="test-endpoint-empty",namespace="default"} 1
+				kube_endpoint_labels{endpoint="test-endpoint-empty",namespace="default"} 1
+				kube_endpoint_owner{endpoint="test-endpoint-empty",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
+			`,
+		},
+		{
+			Obj: &v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-endpoint-ports",
+					Namespace: "default",
+				},
+				Subsets: []v1.EndpointSubset{
+					{
+						Addresses: []v1.EndpointAddress{
+							{IP: "10.0.0.1"},
+						},
+						Ports: []v1.EndpointPort{
+							{Name: "http", Port: 80, Protocol: v1.ProtocolTCP},
+							{Name: "dns", Port: 53, Protocol: v1.ProtocolUDP},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_endpoint_ports Information about the Endpoint ports.
+				# TYPE kube_endpoint_ports gauge
+				kube_endpoint_ports{endpoint="test-endpoint-ports",namespace="default",port_name="http",port_number="80",port_protocol="TCP"} 1
+				kube_endpoint_ports{endpoint="test-endpoint-ports",namespace="default",port_name="dns",port_number="53",port_protocol="UDP"} 1
+			`,
+			MetricNames: []string{"kube_endpoint_ports"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(endpointMetricFamilies)
@@ -154,6 +154,63 @@ var (
 				return &metric.Family{Metrics: ms}
 			}),
 		},
+		{
+			Name: "kube_horizontalpodautoscaler_status_current_metric",
+			Type: metric.Gauge,
+			Help: "The current metric values last observed by this autoscaler.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
+				ms := make([]*metric.Metric, 0, len(a.Status.CurrentMetrics))
+				for _, m := range a.Status.CurrentMetrics {
+					var metricName string
+
+					var v [metricTargetTypeCount]float64
+					var ok [metricTargetTypeCount]bool
+
+					switch m.Type {
+					case autoscaling.ObjectMetricSourceType:
+						metricName = m.Object.MetricName
+
+						v[value], ok[value] = float64(m.Object.CurrentValue.MilliValue())/1000, true
+						if m.Object.AverageValue != nil {
+							v[average], ok[average] = float64(m.Object.AverageValue.MilliValue())/1000, true
+						}
+					case autoscaling.PodsMetricSourceType:
+						metricName = m.Pods.MetricName
+
+						v[average], ok[average] = float64(m.Pods.CurrentAverageValue.MilliValue())/1000, true
+					case autoscaling.ResourceMetricSourceType:
+						metricName = string(m.Resource.Name)
+
+						if ok[utilization] = (m.Resource.CurrentAverageUtilization != nil); ok[utilization] {
+							v[utilization] = float64(*m.Resource.CurrentAverageUtilization)
+						}
+
+						v[average], ok[average] = float64(m.Resource.CurrentAverageValue.MilliValue())/1000, true
+					case autoscaling.ExternalMetricSourceType:
+						metricName = m.External.MetricName
+
+						v[value], ok[value] = float64(m.External.CurrentValue.MilliValue())/1000, true
+						if m.External.CurrentAverageValue != nil {
+							v[average], ok[average] = float64(m.External.CurrentAverageValue.MilliValue())/1000, true
+						}
+					default:
+						// Skip unsupported metric type
+						continue
+					}
+
+					for i := range ok {
+						if ok[i] {
+							ms = append(ms, &metric.Metric{
+								LabelKeys:   targetMetricLabels,
+								LabelValues: []string{metricName, metricTargetType(i).String()},
+								Value:       v[i],
+							})
+						}
+					}
+				}
+				return &metric.Family{Metrics: ms}
+			}),
+		},
 		{
 			Name: "kube_horizontalpodautoscaler_status_current_replicas",
 			Type: metric.Gauge,
@@ -222,9 +279,54 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_horizontalpodautoscaler_owner",
+			Type: metric.Gauge,
+			Help: "Information about the HorizontalPodAutoscaler's owner.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(a.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_horizontalpodautoscaler_created",
+			Type: metric.Gauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(a.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_horizontalpodautoscaler_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the HorizontalPodAutoscaler.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(a.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// hpaAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a HorizontalPodAutoscaler as Prometheus labels.
+func hpaAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_horizontalpodautoscaler_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(a.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapHPAFunc(f func(*autoscaling.HorizontalPodAutoscaler) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		hpa := obj.(*autoscaling.HorizontalPodAutoscaler)
@@ -250,3 +352,21 @@ func createHPAListWatch(kubeClient clientset.Interface, ns string) cache.ListerW
 		},
 	}
 }
+
+// hpaConditionTransitionTime returns the last transition time of the condition
+// the given kube_horizontalpodautoscaler_status_condition series was generated
+// for.
+func hpaConditionTransitionTime(obj interface{}, m *metric.Metric) metav1.Time {
+	o, ok := obj.(*autoscaling.HorizontalPodAutoscaler)
+	if !ok {
+		return metav1.Time{}
+	}
+
+	condition := conditionLabelValue(m)
+	for _, c := range o.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.LastTransitionTime
+		}
+	}
+	return metav1.Time{}
+}
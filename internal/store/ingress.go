@@ -70,16 +70,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !i.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(i.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(i.CreationTimestamp),
 				}
 			}),
 		},
@@ -122,7 +114,13 @@ var (
 			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) *metric.Family {
 				ms := []*metric.Metric{}
 				for _, tls := range i.Spec.TLS {
-					for _, host := range tls.Hosts {
+					// TLS entries without hosts configure the default
+					// certificate of the ingress controller.
+					hosts := tls.Hosts
+					if len(hosts) == 0 {
+						hosts = []string{""}
+					}
+					for _, host := range hosts {
 						ms = append(ms, &metric.Metric{
 							LabelKeys:   []string{"tls_host", "secret"},
 							LabelValues: []string{host, tls.SecretName},
@@ -135,9 +133,65 @@ var (
 				}
 			}),
 		},
+		{
+			Name:  "kube_ingress_backend",
+			Type:  metric.Gauge,
+			Help:  "Backend service of every path of the rules of an ingress, including the default backend.",
+			OptIn: true,
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) *metric.Family {
+				ms := []*metric.Metric{}
+				if b := i.Spec.Backend; b != nil {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"host", "path", "service_name", "service_port", "default"},
+						LabelValues: []string{"", "", b.ServiceName, b.ServicePort.String(), "true"},
+						Value:       1,
+					})
+				}
+				for _, rule := range i.Spec.Rules {
+					if rule.HTTP == nil {
+						continue
+					}
+					for _, path := range rule.HTTP.Paths {
+						ms = append(ms, &metric.Metric{
+							LabelKeys:   []string{"host", "path", "service_name", "service_port", "default"},
+							LabelValues: []string{rule.Host, path.Path, path.Backend.ServiceName, path.Backend.ServicePort.String(), "false"},
+							Value:       1,
+						})
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name: "kube_ingress_owner",
+			Type: metric.Gauge,
+			Help: "Information about the Ingress's owner.",
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(i.GetOwnerReferences()),
+				}
+			}),
+		},
 	}
 )
 
+// ingressAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of an Ingress as Prometheus labels.
+func ingressAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_ingress_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapIngressFunc(func(s *v1beta1.Ingress) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(s.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapIngressFunc(f func(*v1beta1.Ingress) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		ingress := obj.(*v1beta1.Ingress)
@@ -164,6 +164,56 @@ func TestIngressStore(t *testing.T) {
 `,
 			MetricNames: []string{"kube_ingress_info", "kube_ingress_metadata_resource_version", "kube_ingress_created", "kube_ingress_labels", "kube_ingress_path", "kube_ingress_tls"},
 		},
+		{
+			Obj: &v1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress6",
+					Namespace: "ns6",
+				},
+				Spec: v1beta1.IngressSpec{
+					Backend: &v1beta1.IngressBackend{
+						ServiceName: "defaultservice",
+						ServicePort: intstr.FromString("http"),
+					},
+					TLS: []v1beta1.IngressTLS{
+						{
+							SecretName: "defaultsecret",
+						},
+					},
+					Rules: []v1beta1.IngressRule{
+						{
+							Host: "somehost",
+							IngressRuleValue: v1beta1.IngressRuleValue{
+								HTTP: &v1beta1.HTTPIngressRuleValue{
+									Paths: []v1beta1.HTTPIngressPath{
+										{
+											Path: "/somepath",
+											Backend: v1beta1.IngressBackend{
+												ServiceName: "someservice",
+												ServicePort: intstr.FromInt(1234),
+											},
+										},
+									},
+								},
+							},
+						},
+						{
+							Host: "somehost2",
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_ingress_backend Backend service of every path of the rules of an ingress, including the default backend.
+				# HELP kube_ingress_tls Ingress TLS host and secret information.
+				# TYPE kube_ingress_backend gauge
+				# TYPE kube_ingress_tls gauge
+				kube_ingress_backend{namespace="ns6",ingress="ingress6",host="",path="",service_name="defaultservice",service_port="http",default="true"} 1
+				kube_ingress_backend{namespace="ns6",ingress="ingress6",host="somehost",path="/somepath",service_name="someservice",service_port="1234",default="false"} 1
+				kube_ingress_tls{namespace="ns6",ingress="ingress6",tls_host="",secret="defaultsecret"} 1
+`,
+			MetricNames: []string{"kube_ingress_backend", "kube_ingress_tls"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(ingressMetricFamilies)
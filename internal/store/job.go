@@ -17,8 +17,6 @@ limitations under the License.
 package store
 
 import (
-	"strconv"
-
 	"k8s.io/kube-state-metrics/pkg/metric"
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 
@@ -72,16 +70,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !j.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(j.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(j.CreationTimestamp),
 				}
 			}),
 		},
@@ -139,6 +129,24 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_job_spec_backoff_limit",
+			Type: metric.Gauge,
+			Help: "The number of retries before marking the job as failed.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if j.Spec.BackoffLimit != nil {
+					ms = append(ms, &metric.Metric{
+						Value: float64(*j.Spec.BackoffLimit),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_job_status_succeeded",
 			Type: metric.Gauge,
@@ -215,7 +223,8 @@ var (
 						metrics := addConditionMetrics(c.Status)
 						for _, m := range metrics {
 							metric := m
-							metric.LabelKeys = []string{"condition"}
+							metric.LabelKeys = []string{"condition", "reason"}
+							metric.LabelValues = append(metric.LabelValues, c.Reason)
 							ms = append(ms, metric)
 						}
 					}
@@ -268,48 +277,53 @@ var (
 			Type: metric.Gauge,
 			Help: "Information about the Job's owner.",
 			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
-				labelKeys := []string{"owner_kind", "owner_name", "owner_is_controller"}
-
-				owners := j.GetOwnerReferences()
-
-				if len(owners) == 0 {
-					return &metric.Family{
-						Metrics: []*metric.Metric{
-							{
-								LabelKeys:   labelKeys,
-								LabelValues: []string{"<none>", "<none>", "<none>"},
-								Value:       1,
-							},
-						},
-					}
+				return &metric.Family{
+					Metrics: ownerMetrics(j.GetOwnerReferences()),
 				}
-
-				ms := make([]*metric.Metric, len(owners))
-
-				for i, owner := range owners {
-					if owner.Controller != nil {
-						ms[i] = &metric.Metric{
-							LabelKeys:   labelKeys,
-							LabelValues: []string{owner.Kind, owner.Name, strconv.FormatBool(*owner.Controller)},
-							Value:       1,
-						}
-					} else {
-						ms[i] = &metric.Metric{
-							LabelKeys:   labelKeys,
-							LabelValues: []string{owner.Kind, owner.Name, "false"},
-							Value:       1,
-						}
-					}
+			}),
+		},
+		{
+			Name: "kube_job_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Job.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(j.ObjectMeta.ResourceVersion),
 				}
-
+			}),
+		},
+		{
+			Name: "kube_job_metadata_generation",
+			Type: metric.Gauge,
+			Help: "Sequence number representing a specific generation of the desired state.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(j.ObjectMeta.Generation),
+						},
+					},
 				}
 			}),
 		},
 	}
 )
 
+// jobAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Job as Prometheus labels.
+func jobAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_job_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(j.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapJobFunc(f func(*v1batch.Job) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		job := obj.(*v1batch.Job)
@@ -335,3 +349,21 @@ func createJobListWatch(kubeClient clientset.Interface, ns string) cache.ListerW
 		},
 	}
 }
+
+// jobConditionTransitionTime returns the function returning the last
+// transition time of the job condition of the given type.
+func jobConditionTransitionTime(conditionType v1batch.JobConditionType) conditionTransitionTimeFunc {
+	return func(obj interface{}, _ *metric.Metric) metav1.Time {
+		j, ok := obj.(*v1batch.Job)
+		if !ok {
+			return metav1.Time{}
+		}
+
+		for _, c := range j.Status.Conditions {
+			if c.Type == conditionType {
+				return c.LastTransitionTime
+			}
+		}
+		return metav1.Time{}
+	}
+}
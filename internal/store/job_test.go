@@ -31,6 +31,7 @@ var (
 	Parallelism1             int32 = 1
 	Completions1             int32 = 1
 	ActiveDeadlineSeconds900 int64 = 900
+	BackoffLimit6            int32 = 6
 
 	RunningJob1StartTime, _    = time.Parse(time.RFC3339, "2017-05-26T12:00:07Z")
 	SuccessfulJob1StartTime, _ = time.Parse(time.RFC3339, "2017-05-26T12:00:07Z")
@@ -50,6 +51,10 @@ func TestJobStore(t *testing.T) {
 	const metadata = `
 		# HELP kube_job_created Unix creation timestamp
 		# TYPE kube_job_created gauge
+		# HELP kube_job_metadata_generation Sequence number representing a specific generation of the desired state.
+		# TYPE kube_job_metadata_generation gauge
+		# HELP kube_job_metadata_resource_version Resource version representing a specific version of the Job.
+		# TYPE kube_job_metadata_resource_version gauge
 		# HELP kube_job_owner Information about the Job's owner.
 		# TYPE kube_job_owner gauge
 		# HELP kube_job_complete The job has completed its execution.
@@ -62,6 +67,8 @@ func TestJobStore(t *testing.T) {
 		# TYPE kube_job_labels gauge
 		# HELP kube_job_spec_active_deadline_seconds The duration in seconds relative to the startTime that the job may be active before the system tries to terminate it.
 		# TYPE kube_job_spec_active_deadline_seconds gauge
+		# HELP kube_job_spec_backoff_limit The number of retries before marking the job as failed.
+		# TYPE kube_job_spec_backoff_limit gauge
 		# HELP kube_job_spec_completions The desired number of successfully finished pods the job should be run with.
 		# TYPE kube_job_spec_completions gauge
 		# HELP kube_job_spec_parallelism The maximum desired number of pods the job should run at any given time.
@@ -85,6 +92,7 @@ func TestJobStore(t *testing.T) {
 					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 					Namespace:         "ns1",
 					Generation:        1,
+					ResourceVersion:   "10596",
 					Labels: map[string]string{
 						"app": "example-running-1",
 					},
@@ -105,6 +113,7 @@ func TestJobStore(t *testing.T) {
 				},
 				Spec: v1batch.JobSpec{
 					ActiveDeadlineSeconds: &ActiveDeadlineSeconds900,
+					BackoffLimit:          &BackoffLimit6,
 					Parallelism:           &Parallelism1,
 					Completions:           &Completions1,
 				},
@@ -113,8 +122,11 @@ func TestJobStore(t *testing.T) {
 				kube_job_owner{job_name="RunningJob1",namespace="ns1",owner_is_controller="true",owner_kind="CronJob",owner_name="cronjob-name"} 1
 				kube_job_created{job_name="RunningJob1",namespace="ns1"} 1.5e+09
 				kube_job_info{job_name="RunningJob1",namespace="ns1"} 1
+				kube_job_metadata_generation{job_name="RunningJob1",namespace="ns1"} 1
+				kube_job_metadata_resource_version{job_name="RunningJob1",namespace="ns1"} 10596
 				kube_job_labels{job_name="RunningJob1",label_app="example-running-1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="RunningJob1",namespace="ns1"} 900
+				kube_job_spec_backoff_limit{job_name="RunningJob1",namespace="ns1"} 6
 				kube_job_spec_completions{job_name="RunningJob1",namespace="ns1"} 1
 				kube_job_spec_parallelism{job_name="RunningJob1",namespace="ns1"} 1
 				kube_job_status_active{job_name="RunningJob1",namespace="ns1"} 1
@@ -155,6 +167,7 @@ func TestJobStore(t *testing.T) {
 				kube_job_complete{condition="true",job_name="SuccessfulJob1",namespace="ns1"} 1
 				kube_job_complete{condition="unknown",job_name="SuccessfulJob1",namespace="ns1"} 0
 				kube_job_info{job_name="SuccessfulJob1",namespace="ns1"} 1
+				kube_job_metadata_generation{job_name="SuccessfulJob1",namespace="ns1"} 1
 				kube_job_labels{job_name="SuccessfulJob1",label_app="example-successful-1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="SuccessfulJob1",namespace="ns1"} 900
 				kube_job_spec_completions{job_name="SuccessfulJob1",namespace="ns1"} 1
@@ -183,7 +196,7 @@ func TestJobStore(t *testing.T) {
 					CompletionTime: &metav1.Time{Time: FailedJob1CompletionTime},
 					StartTime:      &metav1.Time{Time: FailedJob1StartTime},
 					Conditions: []v1batch.JobCondition{
-						{Type: v1batch.JobFailed, Status: v1.ConditionTrue},
+						{Type: v1batch.JobFailed, Status: v1.ConditionTrue, Reason: "BackoffLimitExceeded"},
 					},
 				},
 				Spec: v1batch.JobSpec{
@@ -194,10 +207,11 @@ func TestJobStore(t *testing.T) {
 			},
 			Want: metadata + `
 				kube_job_owner{job_name="FailedJob1",namespace="ns1",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
-				kube_job_failed{condition="false",job_name="FailedJob1",namespace="ns1"} 0
-				kube_job_failed{condition="true",job_name="FailedJob1",namespace="ns1"} 1
-				kube_job_failed{condition="unknown",job_name="FailedJob1",namespace="ns1"} 0
+				kube_job_failed{condition="false",reason="BackoffLimitExceeded",job_name="FailedJob1",namespace="ns1"} 0
+				kube_job_failed{condition="true",reason="BackoffLimitExceeded",job_name="FailedJob1",namespace="ns1"} 1
+				kube_job_failed{condition="unknown",reason="BackoffLimitExceeded",job_name="FailedJob1",namespace="ns1"} 0
 				kube_job_info{job_name="FailedJob1",namespace="ns1"} 1
+				kube_job_metadata_generation{job_name="FailedJob1",namespace="ns1"} 1
 				kube_job_labels{job_name="FailedJob1",label_app="example-failed-1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="FailedJob1",namespace="ns1"} 900
 				kube_job_spec_completions{job_name="FailedJob1",namespace="ns1"} 1
@@ -242,6 +256,7 @@ func TestJobStore(t *testing.T) {
 
 				kube_job_complete{condition="unknown",job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 0
 				kube_job_info{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
+				kube_job_metadata_generation{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 				kube_job_labels{job_name="SuccessfulJob2NoActiveDeadlineSeconds",label_app="example-successful-2",namespace="ns1"} 1
 				kube_job_spec_completions{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 				kube_job_spec_parallelism{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
@@ -73,9 +73,11 @@ var (
 			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1.Lease) *metric.Family {
 				ms := []*metric.Metric{}
 
+				// The renew time is exposed with sub-second precision,
+				// as leases are renewed every few seconds.
 				if !l.Spec.RenewTime.IsZero() {
 					ms = append(ms, &metric.Metric{
-						Value: float64(l.Spec.RenewTime.Unix()),
+						Value: float64(l.Spec.RenewTime.Unix()) + float64(l.Spec.RenewTime.Nanosecond())/1e9,
 					})
 				}
 				return &metric.Family{
@@ -83,9 +85,61 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_lease_spec_duration_seconds",
+			Type: metric.Gauge,
+			Help: "The duration in seconds the holder of the lease has to renew it before it expires.",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1.Lease) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if l.Spec.LeaseDurationSeconds != nil {
+					ms = append(ms, &metric.Metric{
+						Value: float64(*l.Spec.LeaseDurationSeconds),
+					})
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name: "kube_lease_created",
+			Type: metric.Gauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1.Lease) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(l.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_lease_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Lease.",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1.Lease) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(l.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// leaseAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Lease as Prometheus labels.
+func leaseAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_lease_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapLeaseFunc(func(l *coordinationv1.Lease) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(l.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapLeaseFunc(f func(*coordinationv1.Lease) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		lease := obj.(*coordinationv1.Lease)
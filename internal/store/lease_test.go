@@ -32,6 +32,8 @@ func TestLeaseStore(t *testing.T) {
         # TYPE kube_lease_owner gauge
         # HELP kube_lease_renew_time Kube lease renew time.
         # TYPE kube_lease_renew_time gauge
+        # HELP kube_lease_spec_duration_seconds The duration in seconds the holder of the lease has to renew it before it expires.
+        # TYPE kube_lease_spec_duration_seconds gauge
 	`
 
 	var (
@@ -60,6 +62,28 @@ func TestLeaseStore(t *testing.T) {
 				MetricNames: []string{
 					"kube_lease_owner",
 					"kube_lease_renew_time",
+					"kube_lease_spec_duration_seconds",
+				},
+			},
+			{
+				Obj: &coordinationv1.Lease{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "node1",
+					},
+					Spec: coordinationv1.LeaseSpec{
+						LeaseDurationSeconds: int32ptr(40),
+						RenewTime:            &metav1.MicroTime{Time: time.Unix(1500000000, 250000000)},
+					},
+				},
+				Want: metadata + `
+                    kube_lease_owner{lease="node1",owner_kind="<none>",owner_name="<none>"} 1
+                    kube_lease_renew_time{lease="node1"} 1.50000000025e+09
+                    kube_lease_spec_duration_seconds{lease="node1"} 40
+			`,
+				MetricNames: []string{
+					"kube_lease_owner",
+					"kube_lease_renew_time",
+					"kube_lease_spec_duration_seconds",
 				},
 			},
 		}
@@ -91,23 +91,49 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapLimitRangeFunc(func(r *v1.LimitRange) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
+				return &metric.Family{
+					Metrics: createdMetric(r.CreationTimestamp),
 				}
-
+			}),
+		},
+		{
+			Name: "kube_limitrange_owner",
+			Type: metric.Gauge,
+			Help: "Information about the LimitRange's owner.",
+			GenerateFunc: wrapLimitRangeFunc(func(r *v1.LimitRange) *metric.Family {
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: ownerMetrics(r.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_limitrange_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the LimitRange.",
+			GenerateFunc: wrapLimitRangeFunc(func(r *v1.LimitRange) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(r.ObjectMeta.ResourceVersion),
 				}
 			}),
 		},
 	}
 )
 
+// limitRangeAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a LimitRange as Prometheus labels.
+func limitRangeAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_limitrange_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapLimitRangeFunc(func(r *v1.LimitRange) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(r.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapLimitRangeFunc(f func(*v1.LimitRange) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		limitRange := obj.(*v1.LimitRange)
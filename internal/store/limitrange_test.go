@@ -35,8 +35,12 @@ func TestLimitRangeStore(t *testing.T) {
 	const metadata = `
 	# HELP kube_limitrange_created Unix creation timestamp
 	# TYPE kube_limitrange_created gauge
+	# HELP kube_limitrange_metadata_resource_version Resource version representing a specific version of the LimitRange.
+	# TYPE kube_limitrange_metadata_resource_version gauge
 	# HELP kube_limitrange Information about limit range.
 	# TYPE kube_limitrange gauge
+	# HELP kube_limitrange_owner Information about the LimitRange's owner.
+	# TYPE kube_limitrange_owner gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -76,9 +80,36 @@ func TestLimitRangeStore(t *testing.T) {
         kube_limitrange{constraint="max",limitrange="quotaTest",namespace="testNS",resource="memory",type="Pod"} 2.1e+09
         kube_limitrange{constraint="maxLimitRequestRatio",limitrange="quotaTest",namespace="testNS",resource="memory",type="Pod"} 2.1e+09
         kube_limitrange{constraint="min",limitrange="quotaTest",namespace="testNS",resource="memory",type="Pod"} 2.1e+09
+        kube_limitrange_owner{limitrange="quotaTest",namespace="testNS",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 
 		`,
 		},
+		{
+			Obj: &v1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "containerDefaults",
+					Namespace: "testNS",
+				},
+				Spec: v1.LimitRangeSpec{
+					Limits: []v1.LimitRangeItem{
+						{
+							Type: v1.LimitTypeContainer,
+							DefaultRequest: map[v1.ResourceName]resource.Quantity{
+								v1.ResourceCPU: resource.MustParse("100m"),
+							},
+							MaxLimitRequestRatio: map[v1.ResourceName]resource.Quantity{
+								v1.ResourceCPU: resource.MustParse("1500m"),
+							},
+						},
+					},
+				},
+			},
+			Want: metadata + `
+        kube_limitrange{constraint="defaultRequest",limitrange="containerDefaults",namespace="testNS",resource="cpu",type="Container"} 0.1
+        kube_limitrange{constraint="maxLimitRequestRatio",limitrange="containerDefaults",namespace="testNS",resource="cpu",type="Container"} 1.5
+        kube_limitrange_owner{limitrange="containerDefaults",namespace="testNS",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
+		`,
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(limitRangeMetricFamilies)
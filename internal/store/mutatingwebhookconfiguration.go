@@ -52,15 +52,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp.",
 			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(mwc *admissionregistration.MutatingWebhookConfiguration) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !mwc.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(mwc.CreationTimestamp.Unix()),
-					})
-				}
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(mwc.CreationTimestamp),
 				}
 			}),
 		},
@@ -88,6 +81,21 @@ func createMutatingWebhookConfigurationListWatch(kubeClient clientset.Interface,
 	}
 }
 
+// mutatingWebhookConfigurationAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a MutatingWebhookConfiguration as Prometheus labels.
+func mutatingWebhookConfigurationAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_mutatingwebhookconfiguration_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(mwc *admissionregistration.MutatingWebhookConfiguration) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(mwc.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapMutatingWebhookConfigurationFunc(f func(*admissionregistration.MutatingWebhookConfiguration) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		mutatingWebhookConfiguration := obj.(*admissionregistration.MutatingWebhookConfiguration)
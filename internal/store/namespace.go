@@ -38,11 +38,22 @@ var (
 			Name: "kube_namespace_created",
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
+			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(n.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_namespace_deleted",
+			Type: metric.Gauge,
+			Help: "Unix deletion timestamp",
 			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
 				ms := []*metric.Metric{}
-				if !n.CreationTimestamp.IsZero() {
+
+				if n.DeletionTimestamp != nil && !n.DeletionTimestamp.IsZero() {
 					ms = append(ms, &metric.Metric{
-						Value: float64(n.CreationTimestamp.Unix()),
+						Value: float64(n.DeletionTimestamp.Unix()),
 					})
 				}
 
@@ -105,8 +116,8 @@ var (
 					for j, m := range conditionMetrics {
 						metric := m
 
-						metric.LabelKeys = []string{"condition", "status"}
-						metric.LabelValues = append([]string{string(c.Type)}, metric.LabelValues...)
+						metric.LabelKeys = []string{"condition", "status", "reason"}
+						metric.LabelValues = append([]string{string(c.Type)}, append(metric.LabelValues, c.Reason)...)
 
 						ms[i*len(conditionStatuses)+j] = metric
 					}
@@ -117,9 +128,34 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_namespace_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Namespace.",
+			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(n.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// namespaceAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Namespace as Prometheus labels.
+func namespaceAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_namespace_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(n.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapNamespaceFunc(f func(*v1.Namespace) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		namespace := obj.(*v1.Namespace)
@@ -145,3 +181,20 @@ func createNamespaceListWatch(kubeClient clientset.Interface, ns string) cache.L
 		},
 	}
 }
+
+// namespaceConditionTransitionTime returns the last transition time of the
+// condition the given kube_namespace_status_condition series was generated for.
+func namespaceConditionTransitionTime(obj interface{}, m *metric.Metric) metav1.Time {
+	o, ok := obj.(*v1.Namespace)
+	if !ok {
+		return metav1.Time{}
+	}
+
+	condition := conditionLabelValue(m)
+	for _, c := range o.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.LastTransitionTime
+		}
+	}
+	return metav1.Time{}
+}
@@ -32,6 +32,10 @@ func TestNamespaceStore(t *testing.T) {
 	const metadata = `
 		# HELP kube_namespace_created Unix creation timestamp
 		# TYPE kube_namespace_created gauge
+		# HELP kube_namespace_deleted Unix deletion timestamp
+		# TYPE kube_namespace_deleted gauge
+		# HELP kube_namespace_metadata_resource_version Resource version representing a specific version of the Namespace.
+		# TYPE kube_namespace_metadata_resource_version gauge
 		# HELP kube_namespace_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_namespace_labels gauge
 		# HELP kube_namespace_status_phase kubernetes namespace status phase.
@@ -88,9 +92,10 @@ func TestNamespaceStore(t *testing.T) {
 				Status: v1.NamespaceStatus{
 					Phase: v1.NamespaceTerminating,
 					Conditions: []v1.NamespaceCondition{
-						{Type: v1.NamespaceDeletionDiscoveryFailure, Status: v1.ConditionTrue},
-						{Type: v1.NamespaceDeletionContentFailure, Status: v1.ConditionTrue},
-						{Type: v1.NamespaceDeletionGVParsingFailure, Status: v1.ConditionTrue},
+						{Type: v1.NamespaceDeletionDiscoveryFailure, Status: v1.ConditionTrue, Reason: "DiscoveryFailed"},
+						{Type: v1.NamespaceDeletionContentFailure, Status: v1.ConditionTrue, Reason: "ContentDeletionFailed"},
+						{Type: v1.NamespaceDeletionGVParsingFailure, Status: v1.ConditionTrue, Reason: "GroupVersionParsingFailed"},
+						{Type: v1.NamespaceFinalizersRemaining, Status: v1.ConditionTrue, Reason: "SomeFinalizersRemain"},
 					},
 				},
 			},
@@ -98,15 +103,18 @@ func TestNamespaceStore(t *testing.T) {
 				kube_namespace_labels{namespace="nsTerminateWithConditionTest"} 1
 				kube_namespace_status_phase{namespace="nsTerminateWithConditionTest",phase="Active"} 0
 				kube_namespace_status_phase{namespace="nsTerminateWithConditionTest",phase="Terminating"} 1
-				kube_namespace_status_condition{condition="NamespaceDeletionContentFailure",namespace="nsTerminateWithConditionTest",status="false"} 0
-				kube_namespace_status_condition{condition="NamespaceDeletionContentFailure",namespace="nsTerminateWithConditionTest",status="true"} 1
-				kube_namespace_status_condition{condition="NamespaceDeletionContentFailure",namespace="nsTerminateWithConditionTest",status="unknown"} 0
-				kube_namespace_status_condition{condition="NamespaceDeletionDiscoveryFailure",namespace="nsTerminateWithConditionTest",status="false"} 0
-				kube_namespace_status_condition{condition="NamespaceDeletionDiscoveryFailure",namespace="nsTerminateWithConditionTest",status="true"} 1
-				kube_namespace_status_condition{condition="NamespaceDeletionDiscoveryFailure",namespace="nsTerminateWithConditionTest",status="unknown"} 0
-				kube_namespace_status_condition{condition="NamespaceDeletionGroupVersionParsingFailure",namespace="nsTerminateWithConditionTest",status="false"} 0
-				kube_namespace_status_condition{condition="NamespaceDeletionGroupVersionParsingFailure",namespace="nsTerminateWithConditionTest",status="true"} 1
-				kube_namespace_status_condition{condition="NamespaceDeletionGroupVersionParsingFailure",namespace="nsTerminateWithConditionTest",status="unknown"} 0
+				kube_namespace_status_condition{condition="NamespaceDeletionContentFailure",namespace="nsTerminateWithConditionTest",reason="ContentDeletionFailed",status="false"} 0
+				kube_namespace_status_condition{condition="NamespaceDeletionContentFailure",namespace="nsTerminateWithConditionTest",reason="ContentDeletionFailed",status="true"} 1
+				kube_namespace_status_condition{condition="NamespaceDeletionContentFailure",namespace="nsTerminateWithConditionTest",reason="ContentDeletionFailed",status="unknown"} 0
+				kube_namespace_status_condition{condition="NamespaceDeletionDiscoveryFailure",namespace="nsTerminateWithConditionTest",reason="DiscoveryFailed",status="false"} 0
+				kube_namespace_status_condition{condition="NamespaceDeletionDiscoveryFailure",namespace="nsTerminateWithConditionTest",reason="DiscoveryFailed",status="true"} 1
+				kube_namespace_status_condition{condition="NamespaceDeletionDiscoveryFailure",namespace="nsTerminateWithConditionTest",reason="DiscoveryFailed",status="unknown"} 0
+				kube_namespace_status_condition{condition="NamespaceDeletionGroupVersionParsingFailure",namespace="nsTerminateWithConditionTest",reason="GroupVersionParsingFailed",status="false"} 0
+				kube_namespace_status_condition{condition="NamespaceDeletionGroupVersionParsingFailure",namespace="nsTerminateWithConditionTest",reason="GroupVersionParsingFailed",status="true"} 1
+				kube_namespace_status_condition{condition="NamespaceDeletionGroupVersionParsingFailure",namespace="nsTerminateWithConditionTest",reason="GroupVersionParsingFailed",status="unknown"} 0
+				kube_namespace_status_condition{condition="NamespaceFinalizersRemaining",namespace="nsTerminateWithConditionTest",reason="SomeFinalizersRemain",status="false"} 0
+				kube_namespace_status_condition{condition="NamespaceFinalizersRemaining",namespace="nsTerminateWithConditionTest",reason="SomeFinalizersRemain",status="true"} 1
+				kube_namespace_status_condition{condition="NamespaceFinalizersRemaining",namespace="nsTerminateWithConditionTest",reason="SomeFinalizersRemain",status="unknown"} 0
 `,
 		},
 		{
@@ -153,6 +161,23 @@ func TestNamespaceStore(t *testing.T) {
 				kube_namespace_labels{label_app="example2",label_l2="label2",namespace="ns2"} 1
 				kube_namespace_status_phase{namespace="ns2",phase="Active"} 1
 				kube_namespace_status_phase{namespace="ns2",phase="Terminating"} 0
+`,
+		},
+		{
+			Obj: &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "ns3",
+					DeletionTimestamp: &metav1.Time{Time: time.Unix(1800000000, 0)},
+				},
+				Status: v1.NamespaceStatus{
+					Phase: v1.NamespaceTerminating,
+				},
+			},
+			Want: metadata + `
+				kube_namespace_deleted{namespace="ns3"} 1.8e+09
+				kube_namespace_labels{namespace="ns3"} 1
+				kube_namespace_status_phase{namespace="ns3",phase="Active"} 0
+				kube_namespace_status_phase{namespace="ns3",phase="Terminating"} 1
 `,
 		},
 	}
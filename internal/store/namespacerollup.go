@@ -0,0 +1,328 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+const (
+	namespacePodsMetricName                       = "kube_namespace_pods"
+	namespaceContainersCrashLoopBackOffMetricName = "kube_namespace_containers_crashloopbackoff"
+	namespaceWorkloadsMetricName                  = "kube_namespace_workloads"
+)
+
+var (
+	rollupPodPhases = []v1.PodPhase{v1.PodPending, v1.PodRunning, v1.PodSucceeded, v1.PodFailed, v1.PodUnknown}
+	rollupWorkloads = []string{"DaemonSet", "Deployment", "StatefulSet"}
+
+	namespaceRollupMetricFamilies = []generator.FamilyGenerator{
+		{
+			Name:  namespacePodsMetricName,
+			Type:  metric.Gauge,
+			Help:  "The number of pods in a namespace by phase.",
+			OptIn: true,
+			GenerateFunc: wrapNamespaceRollupFunc(func(r *namespaceRollup) *metric.Family {
+				ms := make([]*metric.Metric, len(rollupPodPhases))
+				for i, phase := range rollupPodPhases {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"phase"},
+						LabelValues: []string{string(phase)},
+						Value:       float64(r.podPhases[phase]),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:  namespaceContainersCrashLoopBackOffMetricName,
+			Type:  metric.Gauge,
+			Help:  "The number of containers in a namespace waiting in CrashLoopBackOff.",
+			OptIn: true,
+			GenerateFunc: wrapNamespaceRollupFunc(func(r *namespaceRollup) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(r.crashLoopBackOffContainers),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:  namespaceWorkloadsMetricName,
+			Type:  metric.Gauge,
+			Help:  "The number of workloads in a namespace by kind.",
+			OptIn: true,
+			GenerateFunc: wrapNamespaceRollupFunc(func(r *namespaceRollup) *metric.Family {
+				ms := make([]*metric.Metric, len(rollupWorkloads))
+				for i, kind := range rollupWorkloads {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"kind"},
+						LabelValues: []string{kind},
+						Value:       float64(r.workloads[kind]),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapNamespaceRollupFunc(f func(*namespaceRollup) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		r := obj.(*namespaceRollup)
+
+		metricFamily := f(r)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descNamespaceLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{r.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+// namespaceRollup holds the number of pods by phase, of containers in
+// CrashLoopBackOff and of workloads by kind in a namespace.
+type namespaceRollup struct {
+	metav1.ObjectMeta
+	podPhases                  map[v1.PodPhase]int
+	crashLoopBackOffContainers int
+	workloads                  map[string]int
+	// objects is the number of objects accounted to the namespace.
+	objects int
+}
+
+// rollupObject is the contribution of a single pod or workload to the
+// rollup of its namespace.
+type rollupObject struct {
+	namespace                  string
+	kind                       string
+	phase                      v1.PodPhase
+	crashLoopBackOffContainers int
+}
+
+// namespaceRollupStore counts pods and workloads per namespace and hands the
+// rollups over to the given MetricsStore, so that small Prometheus servers
+// can alert on the state of namespaces without ingesting per pod series. Pods
+// and every kind of workload are fed in through the stores returned by
+// podStore and workloadStore, each registered with its own reflector.
+type namespaceRollupStore struct {
+	mutex      sync.Mutex
+	objects    map[types.UID]rollupObject
+	namespaces map[string]*namespaceRollup
+	store      *metricsstore.MetricsStore
+}
+
+func newNamespaceRollupStore(store *metricsstore.MetricsStore) *namespaceRollupStore {
+	return &namespaceRollupStore{
+		objects:    map[types.UID]rollupObject{},
+		namespaces: map[string]*namespaceRollup{},
+		store:      store,
+	}
+}
+
+// podStore returns the store to register with the pod reflector.
+func (s *namespaceRollupStore) podStore() *callbackStore {
+	return s.kindStore("Pod", func(obj interface{}) (types.UID, rollupObject, bool) {
+		p, ok := obj.(*v1.Pod)
+		if !ok {
+			return "", rollupObject{}, false
+		}
+
+		o := rollupObject{
+			namespace: p.Namespace,
+			kind:      "Pod",
+			phase:     p.Status.Phase,
+		}
+		for _, statuses := range [][]v1.ContainerStatus{p.Status.InitContainerStatuses, p.Status.ContainerStatuses} {
+			for _, cs := range statuses {
+				if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+					o.crashLoopBackOffContainers++
+				}
+			}
+		}
+		return p.UID, o, true
+	})
+}
+
+// workloadStore returns the store to register with the reflector of the
+// workloads of the given kind.
+func (s *namespaceRollupStore) workloadStore(kind string) *callbackStore {
+	return s.kindStore(kind, func(obj interface{}) (types.UID, rollupObject, bool) {
+		var meta metav1.Object
+		switch o := obj.(type) {
+		case *appsv1.DaemonSet:
+			meta = o
+		case *appsv1.Deployment:
+			meta = o
+		case *appsv1.StatefulSet:
+			meta = o
+		default:
+			return "", rollupObject{}, false
+		}
+		return meta.GetUID(), rollupObject{namespace: meta.GetNamespace(), kind: kind}, true
+	})
+}
+
+// kindStore returns a store accounting the objects of the given kind, which
+// are converted by the given function.
+func (s *namespaceRollupStore) kindStore(kind string, convert func(interface{}) (types.UID, rollupObject, bool)) *callbackStore {
+	return &callbackStore{
+		add: func(obj interface{}) error {
+			uid, o, ok := convert(obj)
+			if !ok {
+				return nil
+			}
+
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			touched := map[string]struct{}{o.namespace: {}}
+			if old, ok := s.remove(uid); ok {
+				touched[old.namespace] = struct{}{}
+			}
+			s.objects[uid] = o
+			s.account(o, 1)
+
+			return s.update(touched)
+		},
+		delete: func(obj interface{}) error {
+			uid, _, ok := convert(obj)
+			if !ok {
+				return nil
+			}
+
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			old, ok := s.remove(uid)
+			if !ok {
+				return nil
+			}
+
+			return s.update(map[string]struct{}{old.namespace: {}})
+		},
+		replace: func(list []interface{}) error {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			// Only forget the objects of this kind, as the objects of
+			// the other kinds are fed in by other reflectors.
+			touched := map[string]struct{}{}
+			for uid, o := range s.objects {
+				if o.kind == kind {
+					s.remove(uid)
+					touched[o.namespace] = struct{}{}
+				}
+			}
+			for _, obj := range list {
+				uid, o, ok := convert(obj)
+				if !ok {
+					continue
+				}
+				if old, ok := s.remove(uid); ok {
+					touched[old.namespace] = struct{}{}
+				}
+				s.objects[uid] = o
+				s.account(o, 1)
+				touched[o.namespace] = struct{}{}
+			}
+
+			return s.update(touched)
+		},
+	}
+}
+
+// remove forgets the object with the given UID and removes it from the rollup
+// of its namespace. It returns the forgotten object, if any.
+func (s *namespaceRollupStore) remove(uid types.UID) (rollupObject, bool) {
+	o, ok := s.objects[uid]
+	if !ok {
+		return rollupObject{}, false
+	}
+
+	delete(s.objects, uid)
+	s.account(o, -1)
+
+	return o, true
+}
+
+// account adds the given object to the rollup of its namespace, or removes it
+// if sign is negative.
+func (s *namespaceRollupStore) account(o rollupObject, sign int) {
+	r, ok := s.namespaces[o.namespace]
+	if !ok {
+		r = &namespaceRollup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: o.namespace,
+				UID:  types.UID(o.namespace),
+			},
+			podPhases: map[v1.PodPhase]int{},
+			workloads: map[string]int{},
+		}
+		s.namespaces[o.namespace] = r
+	}
+
+	r.objects += sign
+	if o.kind == "Pod" {
+		r.podPhases[o.phase] += sign
+		r.crashLoopBackOffContainers += sign * o.crashLoopBackOffContainers
+	} else {
+		r.workloads[o.kind] += sign
+	}
+}
+
+// update passes the rollups of the given namespaces on to the underlying
+// MetricsStore. Namespaces without any objects left are removed.
+func (s *namespaceRollupStore) update(namespaces map[string]struct{}) error {
+	for namespace := range namespaces {
+		r, ok := s.namespaces[namespace]
+		if !ok {
+			continue
+		}
+
+		if r.objects == 0 {
+			delete(s.namespaces, namespace)
+			if err := s.store.Delete(r); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.store.Add(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
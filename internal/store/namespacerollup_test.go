@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestNamespaceRollupStore(t *testing.T) {
+	const metadata = `
+		# HELP kube_namespace_pods The number of pods in a namespace by phase.
+		# TYPE kube_namespace_pods gauge
+		# HELP kube_namespace_containers_crashloopbackoff The number of containers in a namespace waiting in CrashLoopBackOff.
+		# TYPE kube_namespace_containers_crashloopbackoff gauge
+		# HELP kube_namespace_workloads The number of workloads in a namespace by kind.
+		# TYPE kube_namespace_workloads gauge
+	`
+
+	newPod := func(uid, namespace string, phase v1.PodPhase, waitingReasons ...string) *v1.Pod {
+		p := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uid,
+				Namespace: namespace,
+				UID:       types.UID("uid-" + uid),
+			},
+			Status: v1.PodStatus{
+				Phase: phase,
+			},
+		}
+		for _, reason := range waitingReasons {
+			p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, v1.ContainerStatus{
+				State: v1.ContainerState{
+					Waiting: &v1.ContainerStateWaiting{Reason: reason},
+				},
+			})
+		}
+		return p
+	}
+
+	pod1 := newPod("pod1", "ns1", v1.PodRunning, "CrashLoopBackOff", "ContainerCreating")
+	pod2 := newPod("pod2", "ns1", v1.PodPending)
+	pod3 := newPod("pod3", "ns2", v1.PodSucceeded)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment1",
+			Namespace: "ns1",
+			UID:       types.UID("uid-deployment1"),
+		},
+	}
+
+	ms := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(namespaceRollupMetricFamilies),
+		generator.ComposeMetricGenFuncs(namespaceRollupMetricFamilies),
+	)
+	s := newNamespaceRollupStore(ms)
+	pods := s.podStore()
+	deployments := s.workloadStore("Deployment")
+
+	write := func() string {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		return strings.TrimSpace(w.String())
+	}
+
+	if err := pods.Replace([]interface{}{pod1, pod2, pod3}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := deployments.Replace([]interface{}{deployment}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := metadata + `
+		kube_namespace_pods{namespace="ns1",phase="Pending"} 1
+		kube_namespace_pods{namespace="ns1",phase="Running"} 1
+		kube_namespace_pods{namespace="ns1",phase="Succeeded"} 0
+		kube_namespace_pods{namespace="ns1",phase="Failed"} 0
+		kube_namespace_pods{namespace="ns1",phase="Unknown"} 0
+		kube_namespace_containers_crashloopbackoff{namespace="ns1"} 1
+		kube_namespace_workloads{namespace="ns1",kind="DaemonSet"} 0
+		kube_namespace_workloads{namespace="ns1",kind="Deployment"} 1
+		kube_namespace_workloads{namespace="ns1",kind="StatefulSet"} 0
+		kube_namespace_pods{namespace="ns2",phase="Pending"} 0
+		kube_namespace_pods{namespace="ns2",phase="Running"} 0
+		kube_namespace_pods{namespace="ns2",phase="Succeeded"} 1
+		kube_namespace_pods{namespace="ns2",phase="Failed"} 0
+		kube_namespace_pods{namespace="ns2",phase="Unknown"} 0
+		kube_namespace_containers_crashloopbackoff{namespace="ns2"} 0
+		kube_namespace_workloads{namespace="ns2",kind="DaemonSet"} 0
+		kube_namespace_workloads{namespace="ns2",kind="Deployment"} 0
+		kube_namespace_workloads{namespace="ns2",kind="StatefulSet"} 0
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Updating a pod replaces its previous contribution, and namespaces
+	// without any objects left are removed.
+	pod1Recovered := newPod("pod1", "ns1", v1.PodRunning)
+	if err := pods.Update(pod1Recovered); err != nil {
+		t.Fatal(err)
+	}
+	if err := pods.Delete(pod3); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_namespace_pods{namespace="ns1",phase="Pending"} 1
+		kube_namespace_pods{namespace="ns1",phase="Running"} 1
+		kube_namespace_pods{namespace="ns1",phase="Succeeded"} 0
+		kube_namespace_pods{namespace="ns1",phase="Failed"} 0
+		kube_namespace_pods{namespace="ns1",phase="Unknown"} 0
+		kube_namespace_containers_crashloopbackoff{namespace="ns1"} 0
+		kube_namespace_workloads{namespace="ns1",kind="DaemonSet"} 0
+		kube_namespace_workloads{namespace="ns1",kind="Deployment"} 1
+		kube_namespace_workloads{namespace="ns1",kind="StatefulSet"} 0
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing the pods keeps the workloads, which are fed in by another
+	// reflector.
+	if err := pods.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_namespace_pods{namespace="ns1",phase="Pending"} 0
+		kube_namespace_pods{namespace="ns1",phase="Running"} 0
+		kube_namespace_pods{namespace="ns1",phase="Succeeded"} 0
+		kube_namespace_pods{namespace="ns1",phase="Failed"} 0
+		kube_namespace_pods{namespace="ns1",phase="Unknown"} 0
+		kube_namespace_containers_crashloopbackoff{namespace="ns1"} 0
+		kube_namespace_workloads{namespace="ns1",kind="DaemonSet"} 0
+		kube_namespace_workloads{namespace="ns1",kind="Deployment"} 1
+		kube_namespace_workloads{namespace="ns1",kind="StatefulSet"} 0
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+}
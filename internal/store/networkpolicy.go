@@ -38,13 +38,7 @@ var (
 			Help: "Unix creation timestamp of network policy",
 			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
 				return &metric.Family{
-					Metrics: []*metric.Metric{
-						{
-							LabelKeys:   []string{},
-							LabelValues: []string{},
-							Value:       float64(n.CreationTimestamp.Unix()),
-						},
-					},
+					Metrics: createdMetric(n.CreationTimestamp),
 				}
 			}),
 		},
@@ -97,9 +91,44 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_networkpolicy_owner",
+			Type: metric.Gauge,
+			Help: "Information about the NetworkPolicy's owner.",
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(n.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_networkpolicy_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the NetworkPolicy.",
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(n.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// networkpolicyAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a NetworkPolicy as Prometheus labels.
+func networkpolicyAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_networkpolicy_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(n.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapNetworkPolicyFunc(f func(*networkingv1.NetworkPolicy) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		networkPolicy := obj.(*networkingv1.NetworkPolicy)
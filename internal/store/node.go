@@ -18,14 +18,16 @@ package store
 
 import (
 	"strings"
+	"sync"
+	"time"
 
-	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/metric"
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -35,8 +37,13 @@ var (
 	descNodeLabelsName          = "kube_node_labels"
 	descNodeLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descNodeLabelsDefaultLabels = []string{"node"}
+)
 
-	nodeMetricFamilies = []generator.FamilyGenerator{
+// nodeMetricFamilies returns the metric families of nodes. The given tracker
+// remembers since when nodes have been unschedulable, and is owned by the
+// store the families are generated for.
+func nodeMetricFamilies(unschedulable *unschedulableTracker) []generator.FamilyGenerator {
+	return []generator.FamilyGenerator{
 		{
 			Name: "kube_node_info",
 			Type: metric.Gauge,
@@ -74,17 +81,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !n.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(n.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(n.CreationTimestamp),
 				}
 			}),
 		},
@@ -140,6 +138,24 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_node_spec_unschedulable_since",
+			Type: metric.Gauge,
+			Help: "Unix timestamp since when a node has been unschedulable.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if since, ok := unschedulable.since(n); ok {
+					ms = append(ms, &metric.Metric{
+						Value: float64(since.Unix()),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_node_spec_taint",
 			Type: metric.Gauge,
@@ -163,6 +179,26 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_node_status_addresses",
+			Type: metric.Gauge,
+			Help: "The addresses reachable on a cluster node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
+				ms := make([]*metric.Metric, len(n.Status.Addresses))
+
+				for i, address := range n.Status.Addresses {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"type", "address"},
+						LabelValues: []string{string(address.Type), address.Address},
+						Value:       1,
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		// This all-in-one metric family contains all conditions for extensibility.
 		// Third party plugin may report customized condition for cluster node
 		// (e.g. node-problem-detector), and Kubernetes may add new core
@@ -198,76 +234,8 @@ var (
 			Type: metric.Gauge,
 			Help: "The capacity for different resources of a node.",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
-				ms := []*metric.Metric{}
-
-				capacity := n.Status.Capacity
-				for resourceName, val := range capacity {
-					switch resourceName {
-					case v1.ResourceCPU:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitCore),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitByte),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourcePods:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitInteger),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitInteger),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-					}
-				}
-
-				for _, metric := range ms {
-					metric.LabelKeys = []string{"resource", "unit"}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: resourceListMetrics(n.Status.Capacity),
 				}
 			}),
 		},
@@ -276,82 +244,38 @@ var (
 			Type: metric.Gauge,
 			Help: "The allocatable for different resources of a node that are available for scheduling.",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
-				ms := []*metric.Metric{}
-
-				allocatable := n.Status.Allocatable
-
-				for resourceName, val := range allocatable {
-					switch resourceName {
-					case v1.ResourceCPU:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitCore),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitByte),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourcePods:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitInteger),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitInteger),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-					}
-				}
-
-				for _, m := range ms {
-					m.LabelKeys = []string{"resource", "unit"}
+				return &metric.Family{
+					Metrics: resourceListMetrics(n.Status.Allocatable),
 				}
-
+			}),
+		},
+		{
+			Name: "kube_node_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: resourceVersionMetric(n.ObjectMeta.ResourceVersion),
 				}
 			}),
 		},
 	}
-)
+}
+
+// nodeAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Node as Prometheus labels.
+func nodeAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_node_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(n.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
 
 func wrapNodeFunc(f func(*v1.Node) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
@@ -368,6 +292,70 @@ func wrapNodeFunc(f func(*v1.Node) *metric.Family) func(interface{}) *metric.Fam
 	}
 }
 
+// unschedulableTracker remembers when a node was first seen as unschedulable,
+// as the Node object does not record when spec.unschedulable was set.
+type unschedulableTracker struct {
+	mtx   sync.Mutex
+	first map[types.UID]time.Time
+	now   func() time.Time
+}
+
+func newUnschedulableTracker() *unschedulableTracker {
+	return &unschedulableTracker{
+		first: map[types.UID]time.Time{},
+		now:   time.Now,
+	}
+}
+
+// since returns the time since when the given node has been unschedulable.
+// The TimeAdded of the node.kubernetes.io/unschedulable taint is preferred if
+// present, falling back to the time the node was first observed as
+// unschedulable by kube-state-metrics.
+func (t *unschedulableTracker) since(n *v1.Node) (time.Time, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !n.Spec.Unschedulable {
+		delete(t.first, n.UID)
+		return time.Time{}, false
+	}
+
+	for _, taint := range n.Spec.Taints {
+		if taint.Key == v1.TaintNodeUnschedulable && taint.TimeAdded != nil && !taint.TimeAdded.IsZero() {
+			t.first[n.UID] = taint.TimeAdded.Time
+			return taint.TimeAdded.Time, true
+		}
+	}
+
+	first, ok := t.first[n.UID]
+	if !ok {
+		first = t.now()
+		t.first[n.UID] = first
+	}
+
+	return first, true
+}
+
+// forget implements the objectTracker interface.
+func (t *unschedulableTracker) forget(uid types.UID) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	delete(t.first, uid)
+}
+
+// retain implements the objectTracker interface.
+func (t *unschedulableTracker) retain(uids map[types.UID]struct{}) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for uid := range t.first {
+		if _, ok := uids[uid]; !ok {
+			delete(t.first, uid)
+		}
+	}
+}
+
 func createNodeListWatch(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
 	return &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -378,3 +366,20 @@ func createNodeListWatch(kubeClient clientset.Interface, ns string) cache.Lister
 		},
 	}
 }
+
+// nodeConditionTransitionTime returns the last transition time of the condition
+// the given kube_node_status_condition series was generated for.
+func nodeConditionTransitionTime(obj interface{}, m *metric.Metric) metav1.Time {
+	o, ok := obj.(*v1.Node)
+	if !ok {
+		return metav1.Time{}
+	}
+
+	condition := conditionLabelValue(m)
+	for _, c := range o.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.LastTransitionTime
+		}
+	}
+	return metav1.Time{}
+}
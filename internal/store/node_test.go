@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 )
 
 func TestNodeStore(t *testing.T) {
@@ -53,9 +54,11 @@ func TestNodeStore(t *testing.T) {
 				# HELP kube_node_info Information about a cluster node.
 				# HELP kube_node_labels Kubernetes labels converted to Prometheus labels.
 				# HELP kube_node_spec_unschedulable Whether a node can schedule new pods.
+				# HELP kube_node_spec_unschedulable_since Unix timestamp since when a node has been unschedulable.
 				# TYPE kube_node_info gauge
 				# TYPE kube_node_labels gauge
 				# TYPE kube_node_spec_unschedulable gauge
+				# TYPE kube_node_spec_unschedulable_since gauge
 				kube_node_info{container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os_image="osimage",pod_cidr="172.24.10.0/24",provider_id="provider://i-uniqueid"} 1
 				kube_node_labels{node="127.0.0.1"} 1
 				kube_node_spec_unschedulable{node="127.0.0.1"} 0
@@ -90,6 +93,9 @@ func TestNodeStore(t *testing.T) {
 					Unschedulable: true,
 					ProviderID:    "provider://i-randomidentifier",
 					PodCIDR:       "172.24.10.0/24",
+					Taints: []v1.Taint{
+						{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule, TimeAdded: &metav1.Time{Time: time.Unix(1500000000, 0)}},
+					},
 				},
 				Status: v1.NodeStatus{
 					NodeInfo: v1.NodeSystemInfo{
@@ -106,6 +112,8 @@ func TestNodeStore(t *testing.T) {
 						v1.ResourceStorage:                resource.MustParse("3G"),
 						v1.ResourceEphemeralStorage:       resource.MustParse("4G"),
 						v1.ResourceName("nvidia.com/gpu"): resource.MustParse("4"),
+						v1.ResourceName("hugepages-2Mi"):  resource.MustParse("512Mi"),
+						v1.ResourceName("example-device"): resource.MustParse("2"),
 					},
 					Allocatable: v1.ResourceList{
 						v1.ResourceCPU:                    resource.MustParse("3"),
@@ -114,6 +122,7 @@ func TestNodeStore(t *testing.T) {
 						v1.ResourceStorage:                resource.MustParse("2G"),
 						v1.ResourceEphemeralStorage:       resource.MustParse("3G"),
 						v1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+						v1.ResourceName("hugepages-2Mi"):  resource.MustParse("256Mi"),
 					},
 				},
 			},
@@ -123,6 +132,7 @@ func TestNodeStore(t *testing.T) {
 		# HELP kube_node_labels Kubernetes labels converted to Prometheus labels.
 		# HELP kube_node_role The role of a cluster node.
 		# HELP kube_node_spec_unschedulable Whether a node can schedule new pods.
+		# HELP kube_node_spec_unschedulable_since Unix timestamp since when a node has been unschedulable.
 		# HELP kube_node_status_allocatable The allocatable for different resources of a node that are available for scheduling.
 		# HELP kube_node_status_capacity The capacity for different resources of a node.
 		# TYPE kube_node_created gauge
@@ -130,6 +140,7 @@ func TestNodeStore(t *testing.T) {
 		# TYPE kube_node_labels gauge
 		# TYPE kube_node_role gauge
 		# TYPE kube_node_spec_unschedulable gauge
+		# TYPE kube_node_spec_unschedulable_since gauge
 		# TYPE kube_node_status_allocatable gauge
 		# TYPE kube_node_status_capacity gauge
 		kube_node_created{node="127.0.0.1"} 1.5e+09
@@ -137,14 +148,18 @@ func TestNodeStore(t *testing.T) {
 		kube_node_labels{label_node_role_kubernetes_io_master="",node="127.0.0.1"} 1
 		kube_node_role{node="127.0.0.1",role="master"} 1
         kube_node_spec_unschedulable{node="127.0.0.1"} 1
+        kube_node_spec_unschedulable_since{node="127.0.0.1"} 1.5e+09
         kube_node_status_allocatable{node="127.0.0.1",resource="cpu",unit="core"} 3
         kube_node_status_allocatable{node="127.0.0.1",resource="ephemeral_storage",unit="byte"} 3e+09
+        kube_node_status_allocatable{node="127.0.0.1",resource="hugepages_2Mi",unit="byte"} 2.68435456e+08
         kube_node_status_allocatable{node="127.0.0.1",resource="memory",unit="byte"} 1e+09
         kube_node_status_allocatable{node="127.0.0.1",resource="nvidia_com_gpu",unit="integer"} 1
         kube_node_status_allocatable{node="127.0.0.1",resource="pods",unit="integer"} 555
         kube_node_status_allocatable{node="127.0.0.1",resource="storage",unit="byte"} 2e+09
         kube_node_status_capacity{node="127.0.0.1",resource="cpu",unit="core"} 4.3
         kube_node_status_capacity{node="127.0.0.1",resource="ephemeral_storage",unit="byte"} 4e+09
+        kube_node_status_capacity{node="127.0.0.1",resource="example_device",unit="integer"} 2
+        kube_node_status_capacity{node="127.0.0.1",resource="hugepages_2Mi",unit="byte"} 5.36870912e+08
         kube_node_status_capacity{node="127.0.0.1",resource="memory",unit="byte"} 2e+09
         kube_node_status_capacity{node="127.0.0.1",resource="nvidia_com_gpu",unit="integer"} 4
         kube_node_status_capacity{node="127.0.0.1",resource="pods",unit="integer"} 1000
@@ -268,12 +283,132 @@ func TestNodeStore(t *testing.T) {
 			`,
 			MetricNames: []string{"kube_node_spec_taint"},
 		},
+		// Verify SpecUnschedulableSince
+		{
+			Obj: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "127.0.0.1",
+					UID:  "uid-1",
+				},
+				Spec: v1.NodeSpec{
+					Unschedulable: true,
+					Taints: []v1.Taint{
+						{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule, TimeAdded: &metav1.Time{Time: time.Unix(1500000000, 0)}},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_node_spec_unschedulable_since Unix timestamp since when a node has been unschedulable.
+				# TYPE kube_node_spec_unschedulable_since gauge
+				kube_node_spec_unschedulable_since{node="127.0.0.1"} 1.5e+09
+			`,
+			MetricNames: []string{"kube_node_spec_unschedulable_since"},
+		},
+		{
+			Obj: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "127.0.0.1",
+					UID:  "uid-1",
+				},
+			},
+			Want: `
+				# HELP kube_node_spec_unschedulable_since Unix timestamp since when a node has been unschedulable.
+				# TYPE kube_node_spec_unschedulable_since gauge
+			`,
+			MetricNames: []string{"kube_node_spec_unschedulable_since"},
+		},
+		// Verify StatusAddresses
+		{
+			Obj: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "127.0.0.1",
+				},
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{
+						{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+						{Type: v1.NodeExternalIP, Address: "203.0.113.1"},
+						{Type: v1.NodeHostName, Address: "node-1"},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_node_status_addresses The addresses reachable on a cluster node.
+				# TYPE kube_node_status_addresses gauge
+				kube_node_status_addresses{address="10.0.0.1",node="127.0.0.1",type="InternalIP"} 1
+				kube_node_status_addresses{address="203.0.113.1",node="127.0.0.1",type="ExternalIP"} 1
+				kube_node_status_addresses{address="node-1",node="127.0.0.1",type="Hostname"} 1
+			`,
+			MetricNames: []string{"kube_node_status_addresses"},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generator.ComposeMetricGenFuncs(nodeMetricFamilies)
-		c.Headers = generator.ExtractMetricFamilyHeaders(nodeMetricFamilies)
+		families := nodeMetricFamilies(newUnschedulableTracker())
+		c.Func = generator.ComposeMetricGenFuncs(families)
+		c.Headers = generator.ExtractMetricFamilyHeaders(families)
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+func TestUnschedulableTracker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tracker := newUnschedulableTracker()
+	tracker.now = func() time.Time { return now }
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{UID: "uid-1"},
+		Spec:       v1.NodeSpec{Unschedulable: true},
+	}
+
+	since, ok := tracker.since(node)
+	if !ok || !since.Equal(now) {
+		t.Fatalf("expected node to be unschedulable since %v, got %v (%v)", now, since, ok)
+	}
+
+	// Subsequent observations must keep the time the node was first seen.
+	tracker.now = func() time.Time { return now.Add(time.Hour) }
+	since, ok = tracker.since(node)
+	if !ok || !since.Equal(now) {
+		t.Fatalf("expected node to be unschedulable since %v, got %v (%v)", now, since, ok)
+	}
+
+	node.Spec.Unschedulable = false
+	if _, ok := tracker.since(node); ok {
+		t.Fatal("expected schedulable node not to report an unschedulable time")
+	}
+	if len(tracker.first) != 0 {
+		t.Fatalf("expected tracker to forget schedulable node, got %v", tracker.first)
+	}
+
+	// Nodes deleted or no longer listed while unschedulable are forgotten
+	// by the store owning the tracker.
+	families := nodeMetricFamilies(tracker)
+	ms := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(families),
+		generator.ComposeMetricGenFuncs(families),
+	)
+	store := &trackingStore{Store: ms, tracker: tracker}
+
+	node.Spec.Unschedulable = true
+	other := node.DeepCopy()
+	other.UID = "uid-2"
+	if err := store.Replace([]interface{}{node, other}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracker.first) != 2 {
+		t.Fatalf("expected tracker to remember both unschedulable nodes, got %v", tracker.first)
+	}
+	if err := store.Delete(node); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tracker.first[node.UID]; ok || len(tracker.first) != 1 {
+		t.Fatalf("expected tracker to forget deleted node, got %v", tracker.first)
+	}
+	if err := store.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracker.first) != 0 {
+		t.Fatalf("expected tracker to forget nodes which are no longer listed, got %v", tracker.first)
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+var (
+	nodeResourcesMetricFamilies = []generator.FamilyGenerator{
+		{
+			Name:  "kube_node_resource_requests",
+			Type:  metric.Gauge,
+			Help:  "The total amount of resources requested by the non-terminated pods scheduled to a node.",
+			OptIn: true,
+			GenerateFunc: wrapNodeResourcesFunc(func(n *nodeResources) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceListMetrics(n.requests),
+				}
+			}),
+		},
+		{
+			Name:  "kube_node_resource_limits",
+			Type:  metric.Gauge,
+			Help:  "The total amount of resource limits of the non-terminated pods scheduled to a node.",
+			OptIn: true,
+			GenerateFunc: wrapNodeResourcesFunc(func(n *nodeResources) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceListMetrics(n.limits),
+				}
+			}),
+		},
+	}
+)
+
+func wrapNodeResourcesFunc(f func(*nodeResources) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		n := obj.(*nodeResources)
+
+		metricFamily := f(n)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append([]string{"node"}, m.LabelKeys...)
+			m.LabelValues = append([]string{n.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+// nodeResources holds the resources requested by and the resource limits of
+// all non-terminated pods scheduled to a node.
+type nodeResources struct {
+	metav1.ObjectMeta
+	requests v1.ResourceList
+	limits   v1.ResourceList
+}
+
+// podResources holds the effective resource requests and limits of a pod
+// together with the node it is scheduled to.
+type podResources struct {
+	node     string
+	requests v1.ResourceList
+	limits   v1.ResourceList
+}
+
+// nodeResourcesStore implements the k8s.io/client-go/tools/cache.Store
+// interface for pods. It sums up the resource requests and limits of the pods
+// per node and hands the aggregates over to the given MetricsStore, so that
+// the metrics are generated once per node instead of once per pod.
+type nodeResourcesStore struct {
+	mutex    sync.Mutex
+	pods     map[types.UID]podResources
+	nodePods map[string]map[types.UID]struct{}
+	store    *metricsstore.MetricsStore
+}
+
+func newNodeResourcesStore(store *metricsstore.MetricsStore) *nodeResourcesStore {
+	return &nodeResourcesStore{
+		pods:     map[types.UID]podResources{},
+		nodePods: map[string]map[types.UID]struct{}{},
+		store:    store,
+	}
+}
+
+// effectivePodResources returns the resources a pod reserves on its node, in
+// the same way the scheduler accounts for them: the sum of all containers, or
+// the largest init container if that is larger, plus the pod overhead.
+func effectivePodResources(p *v1.Pod) podResources {
+	pr := podResources{
+		node:     p.Spec.NodeName,
+		requests: v1.ResourceList{},
+		limits:   v1.ResourceList{},
+	}
+
+	for _, c := range p.Spec.Containers {
+		addResourceList(pr.requests, c.Resources.Requests)
+		addResourceList(pr.limits, c.Resources.Limits)
+	}
+
+	for _, c := range p.Spec.InitContainers {
+		maxResourceList(pr.requests, c.Resources.Requests)
+		maxResourceList(pr.limits, c.Resources.Limits)
+	}
+
+	addResourceList(pr.requests, p.Spec.Overhead)
+	if len(pr.limits) > 0 {
+		addResourceList(pr.limits, p.Spec.Overhead)
+	}
+
+	return pr
+}
+
+func addResourceList(list, add v1.ResourceList) {
+	for name, quantity := range add {
+		if value, ok := list[name]; ok {
+			value.Add(quantity)
+			list[name] = value
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func maxResourceList(list, other v1.ResourceList) {
+	for name, quantity := range other {
+		if value, ok := list[name]; !ok || quantity.Cmp(value) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// Add adds the resources of the given pod to the aggregates of its node.
+func (s *nodeResourcesStore) Add(obj interface{}) error {
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	oldNode := s.removePod(p.UID)
+
+	if p.Spec.NodeName != "" && p.Status.Phase != v1.PodSucceeded && p.Status.Phase != v1.PodFailed {
+		pr := effectivePodResources(p)
+		s.pods[p.UID] = pr
+		if _, ok := s.nodePods[pr.node]; !ok {
+			s.nodePods[pr.node] = map[types.UID]struct{}{}
+		}
+		s.nodePods[pr.node][p.UID] = struct{}{}
+
+		if err := s.updateNode(pr.node); err != nil {
+			return err
+		}
+	}
+
+	if oldNode != "" && oldNode != p.Spec.NodeName {
+		return s.updateNode(oldNode)
+	}
+
+	return nil
+}
+
+// Update updates the aggregates of the node the given pod is scheduled to.
+func (s *nodeResourcesStore) Update(obj interface{}) error {
+	return s.Add(obj)
+}
+
+// Delete removes the resources of the given pod from the aggregates of its
+// node.
+func (s *nodeResourcesStore) Delete(obj interface{}) error {
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if node := s.removePod(p.UID); node != "" {
+		return s.updateNode(node)
+	}
+
+	return nil
+}
+
+// removePod forgets the given pod and returns the node it was accounted to.
+func (s *nodeResourcesStore) removePod(uid types.UID) string {
+	pr, ok := s.pods[uid]
+	if !ok {
+		return ""
+	}
+
+	delete(s.pods, uid)
+	delete(s.nodePods[pr.node], uid)
+
+	return pr.node
+}
+
+// updateNode recomputes the aggregates of the given node and passes them on
+// to the underlying MetricsStore.
+func (s *nodeResourcesStore) updateNode(node string) error {
+	n := s.nodeResources(node)
+	if len(s.nodePods[node]) == 0 {
+		delete(s.nodePods, node)
+		return s.store.Delete(n)
+	}
+
+	return s.store.Add(n)
+}
+
+func (s *nodeResourcesStore) nodeResources(node string) *nodeResources {
+	n := &nodeResources{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node,
+			UID:  types.UID(node),
+		},
+		requests: v1.ResourceList{},
+		limits:   v1.ResourceList{},
+	}
+
+	for uid := range s.nodePods[node] {
+		addResourceList(n.requests, s.pods[uid].requests)
+		addResourceList(n.limits, s.pods[uid].limits)
+	}
+
+	return n
+}
+
+// List implements the List method of the store interface.
+func (s *nodeResourcesStore) List() []interface{} {
+	return nil
+}
+
+// ListKeys implements the ListKeys method of the store interface.
+func (s *nodeResourcesStore) ListKeys() []string {
+	return nil
+}
+
+// Get implements the Get method of the store interface.
+func (s *nodeResourcesStore) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// GetByKey implements the GetByKey method of the store interface.
+func (s *nodeResourcesStore) GetByKey(key string) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Replace will delete the contents of the store, using instead the
+// given list of pods.
+func (s *nodeResourcesStore) Replace(list []interface{}, _ string) error {
+	s.mutex.Lock()
+	s.pods = map[types.UID]podResources{}
+	s.nodePods = map[string]map[types.UID]struct{}{}
+	s.mutex.Unlock()
+
+	if err := s.store.Replace(nil, ""); err != nil {
+		return err
+	}
+
+	for _, o := range list {
+		if err := s.Add(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resync implements the Resync method of the store interface.
+func (s *nodeResourcesStore) Resync() error {
+	return nil
+}
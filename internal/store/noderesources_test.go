@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestNodeResourcesStore(t *testing.T) {
+	const metadata = `
+		# HELP kube_node_resource_requests The total amount of resources requested by the non-terminated pods scheduled to a node.
+		# TYPE kube_node_resource_requests gauge
+		# HELP kube_node_resource_limits The total amount of resource limits of the non-terminated pods scheduled to a node.
+		# TYPE kube_node_resource_limits gauge
+	`
+
+	newPod := func(uid, node string, phase v1.PodPhase, containers, initContainers []v1.Container) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uid,
+				Namespace: "ns1",
+				UID:       types.UID("uid-" + uid),
+			},
+			Spec: v1.PodSpec{
+				NodeName:       node,
+				Containers:     containers,
+				InitContainers: initContainers,
+			},
+			Status: v1.PodStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	container := func(cpuRequest, memoryRequest, cpuLimit string) v1.Container {
+		c := v1.Container{
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse(cpuRequest),
+					v1.ResourceMemory: resource.MustParse(memoryRequest),
+				},
+			},
+		}
+		if cpuLimit != "" {
+			c.Resources.Limits = v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse(cpuLimit),
+			}
+		}
+		return c
+	}
+
+	pod1 := newPod("pod1", "node1", v1.PodRunning, []v1.Container{
+		container("100m", "100M", "500m"),
+		container("200m", "200M", ""),
+	}, nil)
+	pod2 := newPod("pod2", "node1", v1.PodPending, []v1.Container{
+		container("100m", "100M", "200m"),
+	}, []v1.Container{
+		container("1", "50M", "1"),
+	})
+	pod3 := newPod("pod3", "node2", v1.PodSucceeded, []v1.Container{
+		container("4", "4G", ""),
+	}, nil)
+	pod4 := newPod("pod4", "", v1.PodPending, []v1.Container{
+		container("4", "4G", ""),
+	}, nil)
+
+	ms := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(nodeResourcesMetricFamilies),
+		generator.ComposeMetricGenFuncs(nodeResourcesMetricFamilies),
+	)
+	s := newNodeResourcesStore(ms)
+
+	write := func() string {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		return strings.TrimSpace(w.String())
+	}
+
+	if err := s.Replace([]interface{}{pod1, pod2, pod3, pod4}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := metadata + `
+		kube_node_resource_requests{node="node1",resource="cpu",unit="core"} 1.3
+		kube_node_resource_requests{node="node1",resource="memory",unit="byte"} 4e+08
+		kube_node_resource_limits{node="node1",resource="cpu",unit="core"} 1.5
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving a pod to another node updates the aggregates of both nodes.
+	pod1Moved := pod1.DeepCopy()
+	pod1Moved.Spec.NodeName = "node2"
+	if err := s.Update(pod1Moved); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_node_resource_requests{node="node1",resource="cpu",unit="core"} 1
+		kube_node_resource_requests{node="node1",resource="memory",unit="byte"} 1e+08
+		kube_node_resource_limits{node="node1",resource="cpu",unit="core"} 1
+		kube_node_resource_requests{node="node2",resource="cpu",unit="core"} 0.3
+		kube_node_resource_requests{node="node2",resource="memory",unit="byte"} 3e+08
+		kube_node_resource_limits{node="node2",resource="cpu",unit="core"} 0.5
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nodes without any pods left are removed.
+	if err := s.Delete(pod2); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_node_resource_requests{node="node2",resource="cpu",unit="core"} 0.3
+		kube_node_resource_requests{node="node2",resource="memory",unit="byte"} 3e+08
+		kube_node_resource_limits{node="node2",resource="cpu",unit="core"} 0.5
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+}
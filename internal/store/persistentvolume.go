@@ -102,17 +102,84 @@ var (
 			Type: metric.Gauge,
 			Help: "Information about persistentvolume.",
 			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				var csiDriver, csiVolumeHandle string
+				if p.Spec.CSI != nil {
+					csiDriver = p.Spec.CSI.Driver
+					csiVolumeHandle = p.Spec.CSI.VolumeHandle
+				}
 				return &metric.Family{
 					Metrics: []*metric.Metric{
 						{
-							LabelKeys:   []string{"storageclass"},
-							LabelValues: []string{p.Spec.StorageClassName},
+							LabelKeys:   []string{"storageclass", "csi_driver", "csi_volume_handle"},
+							LabelValues: []string{p.Spec.StorageClassName, csiDriver, csiVolumeHandle},
 							Value:       1,
 						},
 					},
 				}
 			}),
 		},
+		{
+			Name: "kube_persistentvolume_claim_ref",
+			Type: metric.Gauge,
+			Help: "Information about the Persistent Volume Claim Reference.",
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				if p.Spec.ClaimRef == nil {
+					return &metric.Family{
+						Metrics: []*metric.Metric{},
+					}
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"name", "claim_namespace"},
+							LabelValues: []string{p.Spec.ClaimRef.Name, p.Spec.ClaimRef.Namespace},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name: "kube_persistentvolume_reclaim_policy",
+			Type: metric.Gauge,
+			Help: "Describes the reclaim policy in use by this persistentvolume.",
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				if p.Spec.PersistentVolumeReclaimPolicy == "" {
+					return &metric.Family{
+						Metrics: []*metric.Metric{},
+					}
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"reclaim_policy"},
+							LabelValues: []string{string(p.Spec.PersistentVolumeReclaimPolicy)},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name: "kube_persistentvolume_deleted",
+			Type: metric.Gauge,
+			Help: "Unix deletion timestamp",
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if p.DeletionTimestamp != nil && !p.DeletionTimestamp.IsZero() {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{},
+						LabelValues: []string{},
+						Value:       float64(p.DeletionTimestamp.Unix()),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_persistentvolume_capacity_bytes",
 			Type: metric.Gauge,
@@ -128,9 +195,44 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_persistentvolume_created",
+			Type: metric.Gauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(p.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_persistentvolume_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the PersistentVolume.",
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(p.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// persistentVolumeAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a PersistentVolume as Prometheus labels.
+func persistentVolumeAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_persistentvolume_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(p.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapPersistentVolumeFunc(f func(*v1.PersistentVolume) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		persistentVolume := obj.(*v1.PersistentVolume)
@@ -18,6 +18,7 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -169,7 +170,7 @@ func TestPersistentVolumeStore(t *testing.T) {
 			Want: `
 					# HELP kube_persistentvolume_info Information about persistentvolume.
 					# TYPE kube_persistentvolume_info gauge
-					kube_persistentvolume_info{persistentvolume="test-pv-available",storageclass=""} 1
+					kube_persistentvolume_info{csi_driver="",csi_volume_handle="",persistentvolume="test-pv-available",storageclass=""} 1
 				`,
 			MetricNames: []string{"kube_persistentvolume_info"},
 		},
@@ -229,6 +230,61 @@ func TestPersistentVolumeStore(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_persistentvolume_capacity_bytes"},
 		},
+		{
+			Obj: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pv-csi",
+				},
+				Spec: v1.PersistentVolumeSpec{
+					StorageClassName: "csi-standard",
+					PersistentVolumeSource: v1.PersistentVolumeSource{
+						CSI: &v1.CSIPersistentVolumeSource{
+							Driver:       "pd.csi.storage.gke.io",
+							VolumeHandle: "projects/p/zones/z/disks/d",
+						},
+					},
+					ClaimRef: &v1.ObjectReference{
+						Name:      "pvc-test",
+						Namespace: "default",
+					},
+				},
+				Status: v1.PersistentVolumeStatus{
+					Phase: v1.VolumeBound,
+				},
+			},
+			Want: `
+					# HELP kube_persistentvolume_claim_ref Information about the Persistent Volume Claim Reference.
+					# HELP kube_persistentvolume_info Information about persistentvolume.
+					# TYPE kube_persistentvolume_claim_ref gauge
+					# TYPE kube_persistentvolume_info gauge
+					kube_persistentvolume_claim_ref{claim_namespace="default",name="pvc-test",persistentvolume="test-pv-csi"} 1
+					kube_persistentvolume_info{csi_driver="pd.csi.storage.gke.io",csi_volume_handle="projects/p/zones/z/disks/d",persistentvolume="test-pv-csi",storageclass="csi-standard"} 1
+				`,
+			MetricNames: []string{"kube_persistentvolume_info", "kube_persistentvolume_claim_ref"},
+		},
+		{
+			Obj: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pv-released",
+					DeletionTimestamp: &metav1.Time{Time: time.Unix(1800000000, 0)},
+				},
+				Spec: v1.PersistentVolumeSpec{
+					PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+				},
+				Status: v1.PersistentVolumeStatus{
+					Phase: v1.VolumeReleased,
+				},
+			},
+			Want: `
+					# HELP kube_persistentvolume_deleted Unix deletion timestamp
+					# HELP kube_persistentvolume_reclaim_policy Describes the reclaim policy in use by this persistentvolume.
+					# TYPE kube_persistentvolume_deleted gauge
+					# TYPE kube_persistentvolume_reclaim_policy gauge
+					kube_persistentvolume_deleted{persistentvolume="test-pv-released"} 1.8e+09
+					kube_persistentvolume_reclaim_policy{persistentvolume="test-pv-released",reclaim_policy="Retain"} 1
+				`,
+			MetricNames: []string{"kube_persistentvolume_reclaim_policy", "kube_persistentvolume_deleted"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(persistentVolumeMetricFamilies)
@@ -58,11 +58,15 @@ var (
 			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
 				storageClassName := getPersistentVolumeClaimClass(p)
 				volumeName := p.Spec.VolumeName
+				volumeMode := ""
+				if p.Spec.VolumeMode != nil {
+					volumeMode = string(*p.Spec.VolumeMode)
+				}
 				return &metric.Family{
 					Metrics: []*metric.Metric{
 						{
-							LabelKeys:   []string{"storageclass", "volumename"},
-							LabelValues: []string{storageClassName, volumeName},
+							LabelKeys:   []string{"storageclass", "volumename", "volume_mode"},
+							LabelValues: []string{storageClassName, volumeName, volumeMode},
 							Value:       1,
 						},
 					},
@@ -170,9 +174,54 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_persistentvolumeclaim_owner",
+			Type: metric.Gauge,
+			Help: "Information about the PersistentVolumeClaim's owner.",
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(p.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_persistentvolumeclaim_created",
+			Type: metric.Gauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(p.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_persistentvolumeclaim_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the PersistentVolumeClaim.",
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(p.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// persistentVolumeClaimAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a PersistentVolumeClaim as Prometheus labels.
+func persistentVolumeClaimAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_persistentvolumeclaim_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(p.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapPersistentVolumeClaimFunc(f func(*v1.PersistentVolumeClaim) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		persistentVolumeClaim := obj.(*v1.PersistentVolumeClaim)
@@ -214,3 +263,21 @@ func getPersistentVolumeClaimClass(claim *v1.PersistentVolumeClaim) string {
 	// Special non-empty string to indicate absence of storage class.
 	return "<none>"
 }
+
+// persistentVolumeClaimConditionTransitionTime returns the last transition time
+// of the condition the given kube_persistentvolumeclaim_status_condition series
+// was generated for.
+func persistentVolumeClaimConditionTransitionTime(obj interface{}, m *metric.Metric) metav1.Time {
+	o, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return metav1.Time{}
+	}
+
+	condition := conditionLabelValue(m)
+	for _, c := range o.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.LastTransitionTime
+		}
+	}
+	return metav1.Time{}
+}
@@ -17,14 +17,16 @@ limitations under the License.
 package store
 
 import (
+	"regexp"
 	"strconv"
+	"strings"
 
-	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/metric"
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
@@ -36,6 +38,9 @@ var (
 	containerWaitingReasons    = []string{"ContainerCreating", "CrashLoopBackOff", "CreateContainerConfigError", "ErrImagePull", "ImagePullBackOff", "CreateContainerError", "InvalidImageName"}
 	containerTerminatedReasons = []string{"OOMKilled", "Completed", "Error", "ContainerCannotRun", "DeadlineExceeded", "Evicted"}
 	podStatusReasons           = []string{"NodeLost", "Evicted"}
+	// imageDigestPattern matches digests as specified by the OCI image
+	// spec, e.g. sha256:abc.
+	imageDigestPattern = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
 
 	podMetricFamilies = []generator.FamilyGenerator{
 		{
@@ -94,6 +99,12 @@ var (
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
+				if p.Status.Phase != v1.PodSucceeded && p.Status.Phase != v1.PodFailed {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
 				var lastFinishTime float64
 				for _, cs := range p.Status.ContainerStatuses {
 					if cs.State.Terminated != nil {
@@ -122,41 +133,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Information about the Pod's owner.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				labelKeys := []string{"owner_kind", "owner_name", "owner_is_controller"}
-
-				owners := p.GetOwnerReferences()
-				if len(owners) == 0 {
-					return &metric.Family{
-						Metrics: []*metric.Metric{
-							{
-								LabelKeys:   labelKeys,
-								LabelValues: []string{"<none>", "<none>", "<none>"},
-								Value:       1,
-							},
-						},
-					}
-				}
-
-				ms := make([]*metric.Metric, len(owners))
-
-				for i, owner := range owners {
-					if owner.Controller != nil {
-						ms[i] = &metric.Metric{
-							LabelKeys:   labelKeys,
-							LabelValues: []string{owner.Kind, owner.Name, strconv.FormatBool(*owner.Controller)},
-							Value:       1,
-						}
-					} else {
-						ms[i] = &metric.Metric{
-							LabelKeys:   labelKeys,
-							LabelValues: []string{owner.Kind, owner.Name, "false"},
-							Value:       1,
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: ownerMetrics(p.GetOwnerReferences()),
 				}
 			}),
 		},
@@ -180,14 +158,24 @@ var (
 			Name: "kube_pod_created",
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(p.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_pod_deleted",
+			Type: metric.Gauge,
+			Help: "Unix deletion timestamp",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
-				if !p.CreationTimestamp.IsZero() {
+				if p.DeletionTimestamp != nil && !p.DeletionTimestamp.IsZero() {
 					ms = append(ms, &metric.Metric{
 						LabelKeys:   []string{},
 						LabelValues: []string{},
-						Value:       float64(p.CreationTimestamp.Unix()),
+						Value:       float64(p.DeletionTimestamp.Unix()),
 					})
 				}
 
@@ -197,17 +185,15 @@ var (
 			}),
 		},
 		{
-			Name: "kube_pod_deleted",
+			Name: "kube_pod_deletion_grace_period_seconds",
 			Type: metric.Gauge,
-			Help: "Unix deletion timestamp",
+			Help: "The grace period in seconds a deleted pod is given to terminate, which is included in its deletion timestamp.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
-				if p.DeletionTimestamp != nil && !p.DeletionTimestamp.IsZero() {
+				if p.DeletionTimestamp != nil && p.DeletionGracePeriodSeconds != nil {
 					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(p.DeletionTimestamp.Unix()),
+						Value: float64(*p.DeletionGracePeriodSeconds),
 					})
 				}
 
@@ -232,6 +218,40 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_pod_spec_scheduler",
+			Type: metric.Gauge,
+			Help: "The scheduler responsible for scheduling the pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"scheduler"},
+							LabelValues: []string{p.Spec.SchedulerName},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name: "kube_pod_spec_termination_grace_period_seconds",
+			Type: metric.Gauge,
+			Help: "The duration in seconds the pod is given to terminate gracefully.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if p.Spec.TerminationGracePeriodSeconds != nil {
+					ms = append(ms, &metric.Metric{
+						Value: float64(*p.Spec.TerminationGracePeriodSeconds),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_pod_status_scheduled_time",
 			Type: metric.Gauge,
@@ -282,6 +302,28 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_pod_status_unschedulable_since",
+			Type: metric.Gauge,
+			Help: "Unix timestamp since when the pod has been unschedulable.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+				ms := []*metric.Metric{}
+
+				for _, c := range p.Status.Conditions {
+					if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse && !c.LastTransitionTime.IsZero() {
+						ms = append(ms, &metric.Metric{
+							LabelKeys:   []string{},
+							LabelValues: []string{},
+							Value:       float64(c.LastTransitionTime.Unix()),
+						})
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_pod_status_phase",
 			Type: metric.Gauge,
@@ -346,6 +388,26 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_pod_status_ready_time",
+			Type: metric.Gauge,
+			Help: "Unix timestamp when pod moved into ready status.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+				ms := []*metric.Metric{}
+
+				for _, c := range p.Status.Conditions {
+					if c.Type == v1.PodReady && c.Status == v1.ConditionTrue && !c.LastTransitionTime.IsZero() {
+						ms = append(ms, &metric.Metric{
+							Value: float64(c.LastTransitionTime.Unix()),
+						})
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_pod_status_scheduled",
 			Type: metric.Gauge,
@@ -400,19 +462,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Information about a container in a pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.ContainerStatuses))
-				labelKeys := []string{"container", "image", "image_id", "container_id"}
-
-				for i, cs := range p.Status.ContainerStatuses {
-					ms[i] = &metric.Metric{
-						LabelKeys:   labelKeys,
-						LabelValues: []string{cs.Name, cs.Image, cs.ImageID, cs.ContainerID},
-						Value:       1,
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerInfoMetrics(p.Status.ContainerStatuses, p.Spec.Containers),
 				}
 			}),
 		},
@@ -421,19 +472,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Information about an init container in a pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses))
-				labelKeys := []string{"container", "image", "image_id", "container_id"}
-
-				for i, cs := range p.Status.InitContainerStatuses {
-					ms[i] = &metric.Metric{
-						LabelKeys:   labelKeys,
-						LabelValues: []string{cs.Name, cs.Image, cs.ImageID, cs.ContainerID},
-						Value:       1,
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerInfoMetrics(p.Status.InitContainerStatuses, p.Spec.InitContainers),
 				}
 			}),
 		},
@@ -482,20 +522,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the reason the container is currently in waiting state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.ContainerStatuses)*len(containerWaitingReasons))
-
-				for i, cs := range p.Status.ContainerStatuses {
-					for j, reason := range containerWaitingReasons {
-						ms[i*len(containerWaitingReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(waitingReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: waitingReasonMetrics(p.Status.ContainerStatuses),
 				}
 			}),
 		},
@@ -504,20 +532,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the reason the init container is currently in waiting state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses)*len(containerWaitingReasons))
-
-				for i, cs := range p.Status.InitContainerStatuses {
-					for j, reason := range containerWaitingReasons {
-						ms[i*len(containerWaitingReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(waitingReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: waitingReasonMetrics(p.Status.InitContainerStatuses),
 				}
 			}),
 		},
@@ -777,49 +793,7 @@ var (
 				ms := []*metric.Metric{}
 
 				for _, c := range p.Spec.Containers {
-					req := c.Resources.Requests
-
-					for resourceName, val := range req {
-						switch resourceName {
-						case v1.ResourceCPU:
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-								Value:       float64(val.MilliValue()) / 1000,
-							})
-						case v1.ResourceStorage:
-							fallthrough
-						case v1.ResourceEphemeralStorage:
-							fallthrough
-						case v1.ResourceMemory:
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						default:
-							if isHugePageResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isAttachableVolumeResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isExtendedResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-									Value:       float64(val.Value()),
-								})
-							}
-						}
-					}
-				}
-
-				for _, metric := range ms {
-					metric.LabelKeys = []string{"container", "resource", "unit"}
+					ms = append(ms, containerResourceMetrics(c.Name, c.Resources.Requests)...)
 				}
 
 				return &metric.Family{
@@ -835,49 +809,23 @@ var (
 				ms := []*metric.Metric{}
 
 				for _, c := range p.Spec.Containers {
-					lim := c.Resources.Limits
+					ms = append(ms, containerResourceMetrics(c.Name, c.Resources.Limits)...)
+				}
 
-					for resourceName, val := range lim {
-						switch resourceName {
-						case v1.ResourceCPU:
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.MilliValue()) / 1000,
-								LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							})
-						case v1.ResourceStorage:
-							fallthrough
-						case v1.ResourceEphemeralStorage:
-							fallthrough
-						case v1.ResourceMemory:
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						default:
-							if isHugePageResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isAttachableVolumeResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								})
-							}
-							if isExtendedResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-								})
-							}
-						}
-					}
+				return &metric.Family{
+					Metrics: ms,
 				}
+			}),
+		},
+		{
+			Name: "kube_pod_init_container_resource_requests",
+			Type: metric.Gauge,
+			Help: "The number of requested request resource by the init container.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+				ms := []*metric.Metric{}
 
-				for _, metric := range ms {
-					metric.LabelKeys = []string{"container", "resource", "unit"}
+				for _, c := range p.Spec.InitContainers {
+					ms = append(ms, containerResourceMetrics(c.Name, c.Resources.Requests)...)
 				}
 
 				return &metric.Family{
@@ -893,49 +841,41 @@ var (
 				ms := []*metric.Metric{}
 
 				for _, c := range p.Spec.InitContainers {
-					lim := c.Resources.Limits
+					ms = append(ms, containerResourceMetrics(c.Name, c.Resources.Limits)...)
+				}
 
-					for resourceName, val := range lim {
-						switch resourceName {
-						case v1.ResourceCPU:
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.MilliValue()) / 1000,
-								LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							})
-						case v1.ResourceStorage:
-							fallthrough
-						case v1.ResourceEphemeralStorage:
-							fallthrough
-						case v1.ResourceMemory:
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						default:
-							if isHugePageResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isAttachableVolumeResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								})
-							}
-							if isExtendedResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-								})
-							}
-						}
-					}
+				return &metric.Family{
+					Metrics: ms,
 				}
+			}),
+		},
+		{
+			Name:  "kube_pod_spec_volumes",
+			Type:  metric.Gauge,
+			Help:  "Information about the volumes of a pod.",
+			OptIn: true,
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+				ms := make([]*metric.Metric, len(p.Spec.Volumes))
+
+				for i, v := range p.Spec.Volumes {
+					volumeType, readOnly := volumeSourceInfo(v.VolumeSource)
 
-				for _, metric := range ms {
-					metric.LabelKeys = []string{"container", "resource", "unit"}
+					var claimName, configMapName, secretName string
+					if v.PersistentVolumeClaim != nil {
+						claimName = v.PersistentVolumeClaim.ClaimName
+					}
+					if v.ConfigMap != nil {
+						configMapName = v.ConfigMap.Name
+					}
+					if v.Secret != nil {
+						secretName = v.Secret.SecretName
+					}
+
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"volume", "type", "persistentvolumeclaim", "configmap", "secret", "read_only"},
+						LabelValues: []string{v.Name, volumeType, claimName, configMapName, secretName, strconv.FormatBool(readOnly)},
+						Value:       1,
+					}
 				}
 
 				return &metric.Family{
@@ -992,39 +932,39 @@ var (
 			Type: metric.Gauge,
 			Help: "The pod overhead associated with running a pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if p.Spec.Overhead != nil {
-					for resourceName, val := range p.Spec.Overhead {
-						switch resourceName {
-						case v1.ResourceCPU:
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-								Value:       float64(val.MilliValue()) / 1000,
-							})
-
-						case v1.ResourceMemory:
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						}
-					}
-
-				}
-
-				for _, metric := range ms {
-					metric.LabelKeys = []string{"resource", "unit"}
+				return &metric.Family{
+					Metrics: resourceListMetrics(p.Spec.Overhead),
 				}
-
+			}),
+		},
+		{
+			Name: "kube_pod_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: resourceVersionMetric(p.ObjectMeta.ResourceVersion),
 				}
 			}),
 		},
 	}
 )
 
+// podAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Pod as Prometheus labels.
+func podAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_pod_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(p.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapPodFunc(f func(*v1.Pod) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		pod := obj.(*v1.Pod)
@@ -1040,6 +980,22 @@ func wrapPodFunc(f func(*v1.Pod) *metric.Family) func(interface{}) *metric.Famil
 	}
 }
 
+// createScheduledPodListWatch lists and watches only pods that have been
+// scheduled to a node.
+func createScheduledPodListWatch(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	fieldSelector := fields.OneTermNotEqualSelector("spec.nodeName", "").String()
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = fieldSelector
+			return kubeClient.CoreV1().Pods(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = fieldSelector
+			return kubeClient.CoreV1().Pods(ns).Watch(opts)
+		},
+	}
+}
+
 func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
 	return &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -1051,6 +1007,157 @@ func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListerW
 	}
 }
 
+// volumeSourceInfo returns the type of the given volume source, named after
+// its field in the pod spec, and whether the volume is read only. Secret,
+// configMap, downwardAPI and projected volumes are always mounted read only.
+func volumeSourceInfo(vs v1.VolumeSource) (string, bool) {
+	switch {
+	case vs.HostPath != nil:
+		return "hostPath", false
+	case vs.EmptyDir != nil:
+		return "emptyDir", false
+	case vs.GCEPersistentDisk != nil:
+		return "gcePersistentDisk", vs.GCEPersistentDisk.ReadOnly
+	case vs.AWSElasticBlockStore != nil:
+		return "awsElasticBlockStore", vs.AWSElasticBlockStore.ReadOnly
+	case vs.GitRepo != nil:
+		return "gitRepo", false
+	case vs.Secret != nil:
+		return "secret", true
+	case vs.NFS != nil:
+		return "nfs", vs.NFS.ReadOnly
+	case vs.ISCSI != nil:
+		return "iscsi", vs.ISCSI.ReadOnly
+	case vs.Glusterfs != nil:
+		return "glusterfs", vs.Glusterfs.ReadOnly
+	case vs.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim", vs.PersistentVolumeClaim.ReadOnly
+	case vs.RBD != nil:
+		return "rbd", vs.RBD.ReadOnly
+	case vs.FlexVolume != nil:
+		return "flexVolume", vs.FlexVolume.ReadOnly
+	case vs.Cinder != nil:
+		return "cinder", vs.Cinder.ReadOnly
+	case vs.CephFS != nil:
+		return "cephfs", vs.CephFS.ReadOnly
+	case vs.Flocker != nil:
+		return "flocker", false
+	case vs.DownwardAPI != nil:
+		return "downwardAPI", true
+	case vs.FC != nil:
+		return "fc", vs.FC.ReadOnly
+	case vs.AzureFile != nil:
+		return "azureFile", vs.AzureFile.ReadOnly
+	case vs.ConfigMap != nil:
+		return "configMap", true
+	case vs.VsphereVolume != nil:
+		return "vsphereVolume", false
+	case vs.Quobyte != nil:
+		return "quobyte", vs.Quobyte.ReadOnly
+	case vs.AzureDisk != nil:
+		return "azureDisk", vs.AzureDisk.ReadOnly != nil && *vs.AzureDisk.ReadOnly
+	case vs.PhotonPersistentDisk != nil:
+		return "photonPersistentDisk", false
+	case vs.Projected != nil:
+		return "projected", true
+	case vs.PortworxVolume != nil:
+		return "portworxVolume", vs.PortworxVolume.ReadOnly
+	case vs.ScaleIO != nil:
+		return "scaleIO", vs.ScaleIO.ReadOnly
+	case vs.StorageOS != nil:
+		return "storageos", vs.StorageOS.ReadOnly
+	case vs.CSI != nil:
+		return "csi", vs.CSI.ReadOnly != nil && *vs.CSI.ReadOnly
+	}
+
+	return "unknown", false
+}
+
+// containerInfoMetrics returns the info metrics of the given container
+// statuses, along with the digest of the running image and the image pull
+// policy of the matching container of the given spec.
+func containerInfoMetrics(statuses []v1.ContainerStatus, containers []v1.Container) []*metric.Metric {
+	pullPolicies := make(map[string]v1.PullPolicy, len(containers))
+	for _, c := range containers {
+		pullPolicies[c.Name] = c.ImagePullPolicy
+	}
+
+	ms := make([]*metric.Metric, len(statuses))
+	labelKeys := []string{"container", "image", "image_id", "image_digest", "image_pull_policy", "container_id"}
+
+	for i, cs := range statuses {
+		ms[i] = &metric.Metric{
+			LabelKeys:   labelKeys,
+			LabelValues: []string{cs.Name, cs.Image, cs.ImageID, imageDigest(cs.ImageID), string(pullPolicies[cs.Name]), cs.ContainerID},
+			Value:       1,
+		}
+	}
+
+	return ms
+}
+
+// imageDigest returns the digest of the given image ID as reported by the
+// container runtime, e.g. sha256:abc for
+// docker-pullable://nginx@sha256:abc or docker://sha256:abc. It returns an
+// empty string if the image ID holds no digest.
+func imageDigest(imageID string) string {
+	digest := imageID
+	if i := strings.LastIndex(digest, "@"); i >= 0 {
+		digest = digest[i+1:]
+	} else if i := strings.Index(digest, "://"); i >= 0 {
+		digest = digest[i+3:]
+	}
+
+	if !imageDigestPattern.MatchString(digest) {
+		return ""
+	}
+	return digest
+}
+
+// containerResourceMetrics generates the resource metrics of a container,
+// labelled with the name of the container.
+func containerResourceMetrics(container string, rl v1.ResourceList) []*metric.Metric {
+	ms := resourceListMetrics(rl)
+
+	for _, m := range ms {
+		m.LabelKeys = append([]string{"container"}, m.LabelKeys...)
+		m.LabelValues = append([]string{container}, m.LabelValues...)
+	}
+
+	return ms
+}
+
+// waitingReasonMetrics generates one metric per container and well-known
+// waiting reason. Reasons outside of the well-known ones, e.g. reported by
+// custom runtimes or newer kubelets, are exposed as an additional metric for
+// the containers currently waiting for such a reason.
+func waitingReasonMetrics(statuses []v1.ContainerStatus) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(statuses)*len(containerWaitingReasons))
+
+	for _, cs := range statuses {
+		knownReason := false
+		for _, reason := range containerWaitingReasons {
+			isWaiting := waitingReason(cs, reason)
+			knownReason = knownReason || isWaiting
+			ms = append(ms, &metric.Metric{
+				LabelKeys:   []string{"container", "reason"},
+				LabelValues: []string{cs.Name, reason},
+				Value:       boolFloat64(isWaiting),
+			})
+		}
+
+		if !knownReason && cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			ms = append(ms, &metric.Metric{
+				LabelKeys:   []string{"container", "reason"},
+				LabelValues: []string{cs.Name, cs.State.Waiting.Reason},
+				Value:       1,
+			})
+		}
+	}
+
+	return ms
+}
+
 func waitingReason(cs v1.ContainerStatus, reason string) bool {
 	if cs.State.Waiting == nil {
 		return false
@@ -1071,3 +1178,21 @@ func lastTerminationReason(cs v1.ContainerStatus, reason string) bool {
 	}
 	return cs.LastTerminationState.Terminated.Reason == reason
 }
+
+// podConditionTransitionTime returns the function returning the last
+// transition time of the pod condition of the given type.
+func podConditionTransitionTime(conditionType v1.PodConditionType) conditionTransitionTimeFunc {
+	return func(obj interface{}, _ *metric.Metric) metav1.Time {
+		p, ok := obj.(*v1.Pod)
+		if !ok {
+			return metav1.Time{}
+		}
+
+		for _, c := range p.Status.Conditions {
+			if c.Type == conditionType {
+				return c.LastTransitionTime
+			}
+		}
+		return metav1.Time{}
+	}
+}
@@ -29,6 +29,7 @@ import (
 
 func TestPodStore(t *testing.T) {
 	var test = true
+	var podTerminationGracePeriodSeconds int64 = 30
 	startTime := 1501569018
 	metav1StartTime := metav1.Unix(int64(startTime), 0)
 
@@ -53,9 +54,57 @@ func TestPodStore(t *testing.T) {
 			Want: `
 			# HELP kube_pod_container_info Information about a container in a pod.
 			# TYPE kube_pod_container_info gauge
-			kube_pod_container_info{container="container1",container_id="docker://ab123",image="k8s.gcr.io/hyperkube1",image_id="docker://sha256:aaa",namespace="ns1",pod="pod1"} 1`,
+			kube_pod_container_info{container="container1",container_id="docker://ab123",image="k8s.gcr.io/hyperkube1",image_id="docker://sha256:aaa",image_digest="",image_pull_policy="",namespace="ns1",pod="pod1"} 1`,
 			MetricNames: []string{"kube_pod_container_info"},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{
+							Name:            "init1",
+							ImagePullPolicy: v1.PullIfNotPresent,
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:            "container1",
+							ImagePullPolicy: v1.PullAlways,
+						},
+					},
+				},
+				Status: v1.PodStatus{
+					InitContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:        "init1",
+							Image:       "busybox:1.32",
+							ImageID:     "docker://sha256:b97242f89c8a29d13aea12843a08441a4bbfc33528f55b60366c1d8f6923d0d4",
+							ContainerID: "docker://ef789",
+						},
+					},
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:        "container1",
+							Image:       "nginx:1.19",
+							ImageID:     "docker-pullable://nginx@sha256:c3a1592d2b6d275bef4087573355827b200b00ffc2d9849890a4f3aa2128c4ae",
+							ContainerID: "docker://ab123",
+						},
+					},
+				},
+			},
+			Want: `
+			# HELP kube_pod_container_info Information about a container in a pod.
+			# HELP kube_pod_init_container_info Information about an init container in a pod.
+			# TYPE kube_pod_container_info gauge
+			# TYPE kube_pod_init_container_info gauge
+			kube_pod_container_info{container="container1",container_id="docker://ab123",image="nginx:1.19",image_id="docker-pullable://nginx@sha256:c3a1592d2b6d275bef4087573355827b200b00ffc2d9849890a4f3aa2128c4ae",image_digest="sha256:c3a1592d2b6d275bef4087573355827b200b00ffc2d9849890a4f3aa2128c4ae",image_pull_policy="Always",namespace="ns1",pod="pod1"} 1
+			kube_pod_init_container_info{container="init1",container_id="docker://ef789",image="busybox:1.32",image_id="docker://sha256:b97242f89c8a29d13aea12843a08441a4bbfc33528f55b60366c1d8f6923d0d4",image_digest="sha256:b97242f89c8a29d13aea12843a08441a4bbfc33528f55b60366c1d8f6923d0d4",image_pull_policy="IfNotPresent",namespace="ns1",pod="pod1"} 1`,
+			MetricNames: []string{"kube_pod_container_info", "kube_pod_init_container_info"},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -92,9 +141,9 @@ func TestPodStore(t *testing.T) {
 				# HELP kube_pod_init_container_info Information about an init container in a pod.
 				# TYPE kube_pod_container_info gauge
 				# TYPE kube_pod_init_container_info gauge
-				kube_pod_container_info{container="container2",container_id="docker://cd456",image="k8s.gcr.io/hyperkube2",image_id="docker://sha256:bbb",namespace="ns2",pod="pod2"} 1
-				kube_pod_container_info{container="container3",container_id="docker://ef789",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",namespace="ns2",pod="pod2"} 1
-				kube_pod_init_container_info{container="initContainer",container_id="docker://ef123",image="k8s.gcr.io/initfoo",image_id="docker://sha256:wxyz",namespace="ns2",pod="pod2"} 1`,
+				kube_pod_container_info{container="container2",container_id="docker://cd456",image="k8s.gcr.io/hyperkube2",image_id="docker://sha256:bbb",image_digest="",image_pull_policy="",namespace="ns2",pod="pod2"} 1
+				kube_pod_container_info{container="container3",container_id="docker://ef789",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",image_digest="",image_pull_policy="",namespace="ns2",pod="pod2"} 1
+				kube_pod_init_container_info{container="initContainer",container_id="docker://ef123",image="k8s.gcr.io/initfoo",image_id="docker://sha256:wxyz",image_digest="",image_pull_policy="",namespace="ns2",pod="pod2"} 1`,
 			MetricNames: []string{"kube_pod_container_info", "kube_pod_init_container_info"},
 		},
 		{
@@ -866,11 +915,12 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:              "pod1",
-					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
-					Namespace:         "ns1",
-					UID:               "abc-123-xxx",
-					DeletionTimestamp: &metav1.Time{Time: time.Unix(1800000000, 0)},
+					Name:                       "pod1",
+					CreationTimestamp:          metav1.Time{Time: time.Unix(1500000000, 0)},
+					Namespace:                  "ns1",
+					UID:                        "abc-123-xxx",
+					DeletionTimestamp:          &metav1.Time{Time: time.Unix(1800000000, 0)},
+					DeletionGracePeriodSeconds: &podTerminationGracePeriodSeconds,
 				},
 				Spec: v1.PodSpec{
 					NodeName:          "node1",
@@ -884,10 +934,13 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 			},
 			Want: `
 				# HELP kube_pod_deleted Unix deletion timestamp
+				# HELP kube_pod_deletion_grace_period_seconds The grace period in seconds a deleted pod is given to terminate, which is included in its deletion timestamp.
 				# TYPE kube_pod_deleted gauge
+				# TYPE kube_pod_deletion_grace_period_seconds gauge
 				kube_pod_deleted{namespace="ns1",pod="pod1"} 1.8e+09
+				kube_pod_deletion_grace_period_seconds{namespace="ns1",pod="pod1"} 30
 `,
-			MetricNames: []string{"kube_pod_deleted"},
+			MetricNames: []string{"kube_pod_deleted", "kube_pod_deletion_grace_period_seconds"},
 		},
 		{
 			Obj: &v1.Pod{
@@ -923,6 +976,23 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				`,
 			MetricNames: []string{"kube_pod_restart_policy"},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod2",
+					Namespace: "ns2",
+				},
+				Spec: v1.PodSpec{
+					SchedulerName: "volcano",
+				},
+			},
+			Want: `
+				# HELP kube_pod_spec_scheduler The scheduler responsible for scheduling the pod.
+				# TYPE kube_pod_spec_scheduler gauge
+				kube_pod_spec_scheduler{namespace="ns2",pod="pod2",scheduler="volcano"} 1
+				`,
+			MetricNames: []string{"kube_pod_spec_scheduler"},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -943,6 +1013,7 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				Status: v1.PodStatus{
 					HostIP: "1.1.1.1",
 					PodIP:  "2.3.4.5",
+					Phase:  v1.PodSucceeded,
 					ContainerStatuses: []v1.ContainerStatus{
 						{
 							Name:        "container2_1",
@@ -1145,6 +1216,9 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 						{
 							Type:   v1.PodReady,
 							Status: v1.ConditionTrue,
+							LastTransitionTime: metav1.Time{
+								Time: time.Unix(1501666018, 0),
+							},
 						},
 					},
 				},
@@ -1152,9 +1226,12 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 			Want: `
 				# HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
 				# TYPE kube_pod_status_ready gauge
+				# HELP kube_pod_status_ready_time Unix timestamp when pod moved into ready status.
+				# TYPE kube_pod_status_ready_time gauge
 				kube_pod_status_ready{condition="false",namespace="ns1",pod="pod1"} 0
 				kube_pod_status_ready{condition="true",namespace="ns1",pod="pod1"} 1
 				kube_pod_status_ready{condition="unknown",namespace="ns1",pod="pod1"} 0
+				kube_pod_status_ready_time{namespace="ns1",pod="pod1"} 1.501666018e+09
 			`,
 			MetricNames: []string{"kube_pod_status_ready"},
 		},
@@ -1176,6 +1253,8 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 			Want: `
 				# HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
 				# TYPE kube_pod_status_ready gauge
+				# HELP kube_pod_status_ready_time Unix timestamp when pod moved into ready status.
+				# TYPE kube_pod_status_ready_time gauge
 				kube_pod_status_ready{condition="false",namespace="ns2",pod="pod2"} 1
 				kube_pod_status_ready{condition="true",namespace="ns2",pod="pod2"} 0
 				kube_pod_status_ready{condition="unknown",namespace="ns2",pod="pod2"} 0
@@ -1247,10 +1326,11 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				Status: v1.PodStatus{
 					Conditions: []v1.PodCondition{
 						{
-							Type:    v1.PodScheduled,
-							Status:  v1.ConditionFalse,
-							Reason:  "Unschedulable",
-							Message: "0/3 nodes are available: 3 Insufficient cpu.",
+							Type:               v1.PodScheduled,
+							Status:             v1.ConditionFalse,
+							Reason:             "Unschedulable",
+							Message:            "0/3 nodes are available: 3 Insufficient cpu.",
+							LastTransitionTime: metav1.Time{Time: time.Unix(1501666018, 0)},
 						},
 					},
 				},
@@ -1258,7 +1338,10 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 			Want: `
 				# HELP kube_pod_status_unschedulable Describes the unschedulable status for the pod.
 				# TYPE kube_pod_status_unschedulable gauge
+				# HELP kube_pod_status_unschedulable_since Unix timestamp since when the pod has been unschedulable.
+				# TYPE kube_pod_status_unschedulable_since gauge
 				kube_pod_status_unschedulable{namespace="ns2",pod="pod2"} 1
+				kube_pod_status_unschedulable_since{namespace="ns2",pod="pod2"} 1.501666018e+09
 			`,
 			MetricNames: []string{"kube_pod_status_unschedulable"},
 		},
@@ -1330,10 +1413,12 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				# HELP kube_pod_container_resource_limits The number of requested limit resource by a container.
 				# HELP kube_pod_container_resource_requests The number of requested request resource by a container.
 				# HELP kube_pod_init_container_resource_limits The number of requested limit resource by the init container.
+				# HELP kube_pod_init_container_resource_requests The number of requested request resource by the init container.
 				# HELP kube_pod_init_container_status_last_terminated_reason Describes the last reason the init container was in terminated state.
 				# TYPE kube_pod_container_resource_limits gauge
 				# TYPE kube_pod_container_resource_requests gauge
 				# TYPE kube_pod_init_container_resource_limits gauge
+				# TYPE kube_pod_init_container_resource_requests gauge
 				# TYPE kube_pod_init_container_status_last_terminated_reason gauge
 				kube_pod_container_resource_requests{container="pod1_con1",namespace="ns1",pod="pod1",resource="cpu",unit="core"} 0.2
 				kube_pod_container_resource_requests{container="pod1_con2",namespace="ns1",pod="pod1",resource="cpu",unit="core"} 0.3
@@ -1354,11 +1439,17 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
                 kube_pod_init_container_resource_limits{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="memory",unit="byte"} 1e+08
                 kube_pod_init_container_resource_limits{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="nvidia_com_gpu",unit="integer"} 1
                 kube_pod_init_container_resource_limits{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="storage",unit="byte"} 4e+08
+				kube_pod_init_container_resource_requests{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="cpu",unit="core"} 0.2
+				kube_pod_init_container_resource_requests{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="ephemeral_storage",unit="byte"} 3e+08
+				kube_pod_init_container_resource_requests{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="memory",unit="byte"} 1e+08
+				kube_pod_init_container_resource_requests{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="nvidia_com_gpu",unit="integer"} 1
+				kube_pod_init_container_resource_requests{container="pod1_initcon1",namespace="ns1",pod="pod1",resource="storage",unit="byte"} 4e+08
 		`,
 			MetricNames: []string{
 				"kube_pod_container_resource_requests",
 				"kube_pod_container_resource_limits",
 				"kube_pod_init_container_resource_limits",
+				"kube_pod_init_container_resource_requests",
 				"kube_pod_init_container_status_last_terminated_reason",
 			},
 		},
@@ -1423,9 +1514,11 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				# HELP kube_pod_container_resource_limits The number of requested limit resource by a container.
 				# HELP kube_pod_container_resource_requests The number of requested request resource by a container.
 				# HELP kube_pod_init_container_resource_limits The number of requested limit resource by the init container.
+				# HELP kube_pod_init_container_resource_requests The number of requested request resource by the init container.
 				# TYPE kube_pod_container_resource_limits gauge
 				# TYPE kube_pod_container_resource_requests gauge
 				# TYPE kube_pod_init_container_resource_limits gauge
+				# TYPE kube_pod_init_container_resource_requests gauge
 				kube_pod_container_resource_requests{container="pod2_con1",namespace="ns2",pod="pod2",resource="cpu",unit="core"} 0.4
 				kube_pod_container_resource_requests{container="pod2_con2",namespace="ns2",pod="pod2",resource="cpu",unit="core"} 0.5
 				kube_pod_container_resource_requests{container="pod2_con1",namespace="ns2",pod="pod2",resource="memory",unit="byte"} 3e+08
@@ -1436,6 +1529,8 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				kube_pod_container_resource_limits{container="pod2_con2",namespace="ns2",pod="pod2",resource="memory",unit="byte"} 4e+08
                 kube_pod_init_container_resource_limits{container="pod2_initcon1",namespace="ns2",pod="pod2",resource="cpu",unit="core"} 0.4
                 kube_pod_init_container_resource_limits{container="pod2_initcon1",namespace="ns2",pod="pod2",resource="memory",unit="byte"} 3e+08
+				kube_pod_init_container_resource_requests{container="pod2_initcon1",namespace="ns2",pod="pod2",resource="cpu",unit="core"} 0.4
+				kube_pod_init_container_resource_requests{container="pod2_initcon1",namespace="ns2",pod="pod2",resource="memory",unit="byte"} 3e+08
 		`,
 			MetricNames: []string{
 				"kube_pod_container_resource_requests",
@@ -1450,6 +1545,7 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				"kube_pod_init_container_resource_limits",
 				"kube_pod_init_container_resource_limits",
 				"kube_pod_init_container_resource_limits",
+				"kube_pod_init_container_resource_requests",
 			},
 		},
 		{
@@ -1528,6 +1624,165 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				"kube_pod_spec_volumes_persistentvolumeclaims_readonly",
 			},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod-custom-reason",
+					Namespace: "ns1",
+				},
+				Status: v1.PodStatus{
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name: "container1",
+							State: v1.ContainerState{
+								Waiting: &v1.ContainerStateWaiting{
+									Reason: "RuntimeSandboxNotReady",
+								},
+							},
+						},
+					},
+					InitContainerStatuses: []v1.ContainerStatus{
+						{
+							Name: "initcontainer1",
+							State: v1.ContainerState{
+								Waiting: &v1.ContainerStateWaiting{
+									Reason: "PodInitializing",
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_container_status_waiting_reason Describes the reason the container is currently in waiting state.
+				# HELP kube_pod_init_container_status_waiting_reason Describes the reason the init container is currently in waiting state.
+				# TYPE kube_pod_container_status_waiting_reason gauge
+				# TYPE kube_pod_init_container_status_waiting_reason gauge
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="ContainerCreating"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="ImagePullBackOff"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="CrashLoopBackOff"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="ErrImagePull"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="CreateContainerConfigError"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="CreateContainerError"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="InvalidImageName"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod-custom-reason",reason="RuntimeSandboxNotReady"} 1
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="ContainerCreating"} 0
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="ImagePullBackOff"} 0
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="CrashLoopBackOff"} 0
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="ErrImagePull"} 0
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="CreateContainerConfigError"} 0
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="CreateContainerError"} 0
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="InvalidImageName"} 0
+				kube_pod_init_container_status_waiting_reason{container="initcontainer1",namespace="ns1",pod="pod-custom-reason",reason="PodInitializing"} 1
+			`,
+			MetricNames: []string{
+				"kube_pod_container_status_waiting_reason",
+				"kube_pod_init_container_status_waiting_reason",
+			},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod-grace-period",
+					Namespace: "ns1",
+				},
+				Spec: v1.PodSpec{
+					TerminationGracePeriodSeconds: &podTerminationGracePeriodSeconds,
+				},
+			},
+			Want: `
+				# HELP kube_pod_spec_termination_grace_period_seconds The duration in seconds the pod is given to terminate gracefully.
+				# TYPE kube_pod_spec_termination_grace_period_seconds gauge
+				kube_pod_spec_termination_grace_period_seconds{namespace="ns1",pod="pod-grace-period"} 30
+			`,
+			MetricNames: []string{"kube_pod_spec_termination_grace_period_seconds"},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod3",
+					Namespace: "ns3",
+				},
+				Status: v1.PodStatus{
+					Phase: v1.PodRunning,
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name: "container3_1",
+							State: v1.ContainerState{
+								Terminated: &v1.ContainerStateTerminated{
+									FinishedAt: metav1.Time{
+										Time: time.Unix(1501777018, 0),
+									},
+								},
+							},
+						},
+						{
+							Name: "container3_2",
+							State: v1.ContainerState{
+								Running: &v1.ContainerStateRunning{},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_completion_time Completion time in unix timestamp for a pod.
+				# TYPE kube_pod_completion_time gauge
+				`,
+			MetricNames: []string{"kube_pod_completion_time"},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+				},
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						{
+							Name: "data",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+									ClaimName: "claim1",
+									ReadOnly:  true,
+								},
+							},
+						},
+						{
+							Name: "config",
+							VolumeSource: v1.VolumeSource{
+								ConfigMap: &v1.ConfigMapVolumeSource{
+									LocalObjectReference: v1.LocalObjectReference{Name: "configmap1"},
+								},
+							},
+						},
+						{
+							Name: "credentials",
+							VolumeSource: v1.VolumeSource{
+								Secret: &v1.SecretVolumeSource{
+									SecretName: "secret1",
+								},
+							},
+						},
+						{
+							Name: "scratch",
+							VolumeSource: v1.VolumeSource{
+								EmptyDir: &v1.EmptyDirVolumeSource{},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_spec_volumes Information about the volumes of a pod.
+				# TYPE kube_pod_spec_volumes gauge
+				kube_pod_spec_volumes{configmap="",namespace="ns1",persistentvolumeclaim="claim1",pod="pod1",read_only="true",secret="",type="persistentVolumeClaim",volume="data"} 1
+				kube_pod_spec_volumes{configmap="configmap1",namespace="ns1",persistentvolumeclaim="",pod="pod1",read_only="true",secret="",type="configMap",volume="config"} 1
+				kube_pod_spec_volumes{configmap="",namespace="ns1",persistentvolumeclaim="",pod="pod1",read_only="true",secret="secret1",type="secret",volume="credentials"} 1
+				kube_pod_spec_volumes{configmap="",namespace="ns1",persistentvolumeclaim="",pod="pod1",read_only="false",secret="",type="emptyDir",volume="scratch"} 1
+			`,
+			MetricNames: []string{"kube_pod_spec_volumes[ {]"},
+		},
 	}
 
 	for i, c := range cases {
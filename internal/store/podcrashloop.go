@@ -0,0 +1,239 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// crashLoopBackOffResetPeriod is the period a container has to run for the
+// kubelet to reset its restart back-off, i.e. twice the maximum back-off of
+// five minutes.
+const crashLoopBackOffResetPeriod = 10 * time.Minute
+
+var (
+	podCrashLoopMetricFamilies = []generator.FamilyGenerator{
+		{
+			Name:  "kube_pod_container_status_crashloopbackoff_streak",
+			Type:  metric.Gauge,
+			Help:  "The number of consecutive restarts of a container without running long enough for its restart back-off to be reset, or 0 while it is running or ready without new restarts.",
+			OptIn: true,
+			GenerateFunc: wrapPodCrashLoopFunc(func(p *podCrashLoop) *metric.Family {
+				ms := make([]*metric.Metric, len(p.containers))
+				for i, c := range p.containers {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"container"},
+						LabelValues: []string{c.name},
+						Value:       float64(c.streak),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapPodCrashLoopFunc(f func(*podCrashLoop) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		p := obj.(*podCrashLoop)
+
+		metricFamily := f(p)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descPodLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{p.Namespace, p.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+// podCrashLoop holds the restart streaks of the containers of a pod.
+type podCrashLoop struct {
+	metav1.ObjectMeta
+	containers []containerStreak
+}
+
+// containerStreak is the restart streak of a container together with the
+// restart count it was last updated with. restarts holds the consecutive
+// restarts even while streak is reset to 0, so that they are picked up again
+// if the container restarts before running long enough.
+type containerStreak struct {
+	name         string
+	restartCount int32
+	restarts     int32
+	streak       int32
+}
+
+// podCrashLoopStore tracks the restart streaks of the containers of the pods
+// fed in through the store returned by podStore, and hands them over to the
+// given MetricsStore. As the streaks are derived from the changes of the
+// restart counts between updates, they are tracked from the first time a pod
+// is seen.
+type podCrashLoopStore struct {
+	mutex sync.Mutex
+	pods  map[types.UID]*podCrashLoop
+	store *metricsstore.MetricsStore
+}
+
+func newPodCrashLoopStore(store *metricsstore.MetricsStore) *podCrashLoopStore {
+	return &podCrashLoopStore{
+		pods:  map[types.UID]*podCrashLoop{},
+		store: store,
+	}
+}
+
+// podStore returns the store to register with the pod reflector.
+func (s *podCrashLoopStore) podStore() *callbackStore {
+	return &callbackStore{
+		add: func(obj interface{}) error {
+			p, ok := obj.(*v1.Pod)
+			if !ok {
+				return nil
+			}
+
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			return s.update(p)
+		},
+		delete: func(obj interface{}) error {
+			p, ok := obj.(*v1.Pod)
+			if !ok {
+				return nil
+			}
+
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			delete(s.pods, p.UID)
+
+			return s.store.Delete(p)
+		},
+		replace: func(list []interface{}) error {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			// Keep the streaks of the pods which are still present, so
+			// that relisting does not reset them.
+			old := s.pods
+			s.pods = make(map[types.UID]*podCrashLoop, len(list))
+			if err := s.store.Replace(nil, ""); err != nil {
+				return err
+			}
+
+			for _, obj := range list {
+				p, ok := obj.(*v1.Pod)
+				if !ok {
+					continue
+				}
+				if c, ok := old[p.UID]; ok {
+					s.pods[p.UID] = c
+				}
+				if err := s.update(p); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// update recomputes the restart streaks of the containers of the given pod
+// and passes them on to the underlying MetricsStore.
+func (s *podCrashLoopStore) update(p *v1.Pod) error {
+	previous := map[string]containerStreak{}
+	if old, ok := s.pods[p.UID]; ok {
+		for _, c := range old.containers {
+			previous[c.name] = c
+		}
+	}
+
+	c := &podCrashLoop{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: p.Namespace,
+			Name:      p.Name,
+			UID:       p.UID,
+		},
+		containers: make([]containerStreak, len(p.Status.ContainerStatuses)),
+	}
+	for i, cs := range p.Status.ContainerStatuses {
+		prev, ok := previous[cs.Name]
+		c.containers[i] = nextContainerStreak(cs, prev, ok)
+	}
+	s.pods[p.UID] = c
+
+	return s.store.Add(c)
+}
+
+// nextContainerStreak returns the restart streak of the container with the
+// given status, based on its previous streak if any. Restarts extend the
+// streak unless the container ran for at least crashLoopBackOffResetPeriod
+// before it terminated, in which case they start a new one. The streak is 0
+// while the container is running or ready without having restarted since the
+// previous update. Containers seen for the first time start with a streak of
+// one if they are currently in CrashLoopBackOff.
+func nextContainerStreak(cs v1.ContainerStatus, prev containerStreak, seen bool) containerStreak {
+	next := containerStreak{
+		name:         cs.Name,
+		restartCount: cs.RestartCount,
+	}
+
+	if !seen || cs.RestartCount < prev.restartCount {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			next.restarts = 1
+			next.streak = 1
+		}
+		return next
+	}
+
+	delta := cs.RestartCount - prev.restartCount
+	switch {
+	case delta == 0:
+		next.restarts = prev.restarts
+		if cs.State.Running == nil && !cs.Ready {
+			next.streak = next.restarts
+		}
+	case lastRunResetBackOff(cs):
+		next.restarts = 1
+		next.streak = 1
+	default:
+		next.restarts = prev.restarts + delta
+		next.streak = next.restarts
+	}
+	return next
+}
+
+// lastRunResetBackOff returns whether the last terminated run of the
+// container with the given status lasted long enough for the kubelet to reset
+// its restart back-off.
+func lastRunResetBackOff(cs v1.ContainerStatus) bool {
+	t := cs.LastTerminationState.Terminated
+	return t != nil && !t.StartedAt.IsZero() && t.FinishedAt.Sub(t.StartedAt.Time) >= crashLoopBackOffResetPeriod
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestPodCrashLoopStore(t *testing.T) {
+	const metadata = `
+		# HELP kube_pod_container_status_crashloopbackoff_streak The number of consecutive restarts of a container without running long enough for its restart back-off to be reset, or 0 while it is running or ready without new restarts.
+		# TYPE kube_pod_container_status_crashloopbackoff_streak gauge
+	`
+
+	started := time.Unix(1500000000, 0)
+	newPod := func(restartCount int32, waitingReason string, lastRun time.Duration) *v1.Pod {
+		cs := v1.ContainerStatus{
+			Name:         "container1",
+			RestartCount: restartCount,
+		}
+		if waitingReason != "" {
+			cs.State.Waiting = &v1.ContainerStateWaiting{Reason: waitingReason}
+		} else {
+			cs.State.Running = &v1.ContainerStateRunning{}
+		}
+		if lastRun > 0 {
+			cs.LastTerminationState.Terminated = &v1.ContainerStateTerminated{
+				ExitCode:   1,
+				StartedAt:  metav1.NewTime(started),
+				FinishedAt: metav1.NewTime(started.Add(lastRun)),
+			}
+		}
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "ns1",
+				UID:       types.UID("uid1"),
+			},
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{cs},
+			},
+		}
+	}
+
+	ms := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(podCrashLoopMetricFamilies),
+		generator.ComposeMetricGenFuncs(podCrashLoopMetricFamilies),
+	)
+	pods := newPodCrashLoopStore(ms).podStore()
+
+	write := func() string {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		return strings.TrimSpace(w.String())
+	}
+
+	for _, step := range []struct {
+		name    string
+		update  func() error
+		wantVal string
+	}{
+		{
+			name:    "containers in CrashLoopBackOff when first seen start a streak",
+			update:  func() error { return pods.Replace([]interface{}{newPod(7, "CrashLoopBackOff", time.Second)}, "") },
+			wantVal: "1",
+		},
+		{
+			name:    "restarts after short runs extend the streak",
+			update:  func() error { return pods.Update(newPod(9, "", time.Second)) },
+			wantVal: "3",
+		},
+		{
+			name:    "updates without restarts keep the streak",
+			update:  func() error { return pods.Update(newPod(9, "CrashLoopBackOff", time.Second)) },
+			wantVal: "3",
+		},
+		{
+			name:    "running without restarts resets the streak",
+			update:  func() error { return pods.Update(newPod(9, "", time.Second)) },
+			wantVal: "0",
+		},
+		{
+			name:    "waiting again without a long run resumes the streak",
+			update:  func() error { return pods.Update(newPod(9, "CrashLoopBackOff", time.Second)) },
+			wantVal: "3",
+		},
+		{
+			name:    "relisting keeps the streak",
+			update:  func() error { return pods.Replace([]interface{}{newPod(10, "CrashLoopBackOff", time.Second)}, "") },
+			wantVal: "4",
+		},
+		{
+			name:    "restarts after long runs start a new streak",
+			update:  func() error { return pods.Update(newPod(11, "CrashLoopBackOff", time.Hour)) },
+			wantVal: "1",
+		},
+	} {
+		if err := step.update(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		want := metadata + `
+			kube_pod_container_status_crashloopbackoff_streak{container="container1",namespace="ns1",pod="pod1"} ` + step.wantVal
+		if err := compareOutput(want, write()); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+	}
+
+	if err := pods.Delete(newPod(11, "", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := compareOutput(metadata, write()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -37,16 +37,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !p.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(p.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(p.CreationTimestamp),
 				}
 			}),
 		},
@@ -78,6 +70,20 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_poddisruptionbudget_violated",
+			Type: metric.Gauge,
+			Help: "Whether the current number of healthy pods is below the desired number of healthy pods.",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: boolFloat64(p.Status.CurrentHealthy < p.Status.DesiredHealthy),
+						},
+					},
+				}
+			}),
+		},
 		{
 			Name: "kube_poddisruptionbudget_status_pod_disruptions_allowed",
 			Type: metric.Gauge,
@@ -120,9 +126,58 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_poddisruptionbudget_owner",
+			Type: metric.Gauge,
+			Help: "Information about the PodDisruptionBudget's owner.",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(p.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_poddisruptionbudget_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the PodDisruptionBudget.",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(p.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
+		{
+			Name: "kube_poddisruptionbudget_metadata_generation",
+			Type: metric.Gauge,
+			Help: "Sequence number representing a specific generation of the desired state.",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(p.ObjectMeta.Generation),
+						},
+					},
+				}
+			}),
+		},
 	}
 )
 
+// podDisruptionBudgetAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a PodDisruptionBudget as Prometheus labels.
+func podDisruptionBudgetAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_poddisruptionbudget_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(p.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapPodDisruptionBudgetFunc(f func(*v1beta1.PodDisruptionBudget) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		podDisruptionBudget := obj.(*v1beta1.PodDisruptionBudget)
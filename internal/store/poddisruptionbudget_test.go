@@ -32,16 +32,24 @@ func TestPodDisruptionBudgetStore(t *testing.T) {
 	const metadata = `
 	# HELP kube_poddisruptionbudget_created Unix creation timestamp
 	# TYPE kube_poddisruptionbudget_created gauge
+	# HELP kube_poddisruptionbudget_metadata_generation Sequence number representing a specific generation of the desired state.
+	# TYPE kube_poddisruptionbudget_metadata_generation gauge
+	# HELP kube_poddisruptionbudget_metadata_resource_version Resource version representing a specific version of the PodDisruptionBudget.
+	# TYPE kube_poddisruptionbudget_metadata_resource_version gauge
 	# HELP kube_poddisruptionbudget_status_current_healthy Current number of healthy pods
 	# TYPE kube_poddisruptionbudget_status_current_healthy gauge
 	# HELP kube_poddisruptionbudget_status_desired_healthy Minimum desired number of healthy pods
 	# TYPE kube_poddisruptionbudget_status_desired_healthy gauge
+	# HELP kube_poddisruptionbudget_violated Whether the current number of healthy pods is below the desired number of healthy pods.
+	# TYPE kube_poddisruptionbudget_violated gauge
 	# HELP kube_poddisruptionbudget_status_pod_disruptions_allowed Number of pod disruptions that are currently allowed
 	# TYPE kube_poddisruptionbudget_status_pod_disruptions_allowed gauge
 	# HELP kube_poddisruptionbudget_status_expected_pods Total number of pods counted by this disruption budget
 	# TYPE kube_poddisruptionbudget_status_expected_pods gauge
 	# HELP kube_poddisruptionbudget_status_observed_generation Most recent generation observed when updating this PDB status
 	# TYPE kube_poddisruptionbudget_status_observed_generation gauge
+	# HELP kube_poddisruptionbudget_owner Information about the PodDisruptionBudget's owner.
+	# TYPE kube_poddisruptionbudget_owner gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -62,11 +70,14 @@ func TestPodDisruptionBudgetStore(t *testing.T) {
 			},
 			Want: metadata + `
 			kube_poddisruptionbudget_created{namespace="ns1",poddisruptionbudget="pdb1"} 1.5e+09
+			kube_poddisruptionbudget_metadata_generation{namespace="ns1",poddisruptionbudget="pdb1"} 21
 			kube_poddisruptionbudget_status_current_healthy{namespace="ns1",poddisruptionbudget="pdb1"} 12
 			kube_poddisruptionbudget_status_desired_healthy{namespace="ns1",poddisruptionbudget="pdb1"} 10
+			kube_poddisruptionbudget_violated{namespace="ns1",poddisruptionbudget="pdb1"} 0
 			kube_poddisruptionbudget_status_pod_disruptions_allowed{namespace="ns1",poddisruptionbudget="pdb1"} 2
 			kube_poddisruptionbudget_status_expected_pods{namespace="ns1",poddisruptionbudget="pdb1"} 15
 			kube_poddisruptionbudget_status_observed_generation{namespace="ns1",poddisruptionbudget="pdb1"} 111
+			kube_poddisruptionbudget_owner{poddisruptionbudget="pdb1",namespace="ns1",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
 		{
@@ -85,11 +96,14 @@ func TestPodDisruptionBudgetStore(t *testing.T) {
 				},
 			},
 			Want: metadata + `
+				kube_poddisruptionbudget_metadata_generation{namespace="ns2",poddisruptionbudget="pdb2"} 14
 				kube_poddisruptionbudget_status_current_healthy{namespace="ns2",poddisruptionbudget="pdb2"} 8
 				kube_poddisruptionbudget_status_desired_healthy{namespace="ns2",poddisruptionbudget="pdb2"} 9
+				kube_poddisruptionbudget_violated{namespace="ns2",poddisruptionbudget="pdb2"} 1
 				kube_poddisruptionbudget_status_pod_disruptions_allowed{namespace="ns2",poddisruptionbudget="pdb2"} 0
 				kube_poddisruptionbudget_status_expected_pods{namespace="ns2",poddisruptionbudget="pdb2"} 10
 				kube_poddisruptionbudget_status_observed_generation{namespace="ns2",poddisruptionbudget="pdb2"} 1111
+				kube_poddisruptionbudget_owner{poddisruptionbudget="pdb2",namespace="ns2",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
 	}
@@ -0,0 +1,324 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sort"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+var (
+	podDisruptionBudgetPodMetricFamilies = []generator.FamilyGenerator{
+		{
+			Name:  "kube_poddisruptionbudget_pod",
+			Type:  metric.Gauge,
+			Help:  "Information about the pod disruption budgets selecting a pod.",
+			OptIn: true,
+			GenerateFunc: wrapPodDisruptionBudgetPodFunc(func(p *podDisruptionBudgets) *metric.Family {
+				ms := make([]*metric.Metric, len(p.budgets))
+
+				for i, name := range p.budgets {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"poddisruptionbudget"},
+						LabelValues: []string{name},
+						Value:       1,
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapPodDisruptionBudgetPodFunc(f func(*podDisruptionBudgets) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		p := obj.(*podDisruptionBudgets)
+
+		metricFamily := f(p)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descPodLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{p.Namespace, p.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+// podDisruptionBudgets holds the names of the pod disruption budgets
+// selecting a pod.
+type podDisruptionBudgets struct {
+	metav1.ObjectMeta
+	budgets []string
+}
+
+// budgetSelector holds the parsed selector of a pod disruption budget.
+type budgetSelector struct {
+	namespace string
+	name      string
+	selector  labels.Selector
+}
+
+// podDisruptionBudgetPodStore joins pods with the pod disruption budgets
+// selecting them and hands the result over to the given MetricsStore. Pods
+// and pod disruption budgets are fed in through the stores returned by
+// podStore and budgetStore, each registered with its own reflector.
+type podDisruptionBudgetPodStore struct {
+	mutex   sync.Mutex
+	pods    map[types.UID]metav1.ObjectMeta
+	budgets map[types.UID]budgetSelector
+	store   *metricsstore.MetricsStore
+}
+
+func newPodDisruptionBudgetPodStore(store *metricsstore.MetricsStore) *podDisruptionBudgetPodStore {
+	return &podDisruptionBudgetPodStore{
+		pods:    map[types.UID]metav1.ObjectMeta{},
+		budgets: map[types.UID]budgetSelector{},
+		store:   store,
+	}
+}
+
+// podStore returns the store to register with the pod reflector.
+func (s *podDisruptionBudgetPodStore) podStore() *callbackStore {
+	return &callbackStore{
+		add:     s.addPod,
+		delete:  s.deletePod,
+		replace: s.replacePods,
+	}
+}
+
+// budgetStore returns the store to register with the pod disruption budget
+// reflector.
+func (s *podDisruptionBudgetPodStore) budgetStore() *callbackStore {
+	return &callbackStore{
+		add:     s.addBudget,
+		delete:  s.deleteBudget,
+		replace: s.replaceBudgets,
+	}
+}
+
+func (s *podDisruptionBudgetPodStore) addPod(obj interface{}) error {
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Only keep the fields needed to evaluate the selectors.
+	pod := metav1.ObjectMeta{
+		Namespace: p.Namespace,
+		Name:      p.Name,
+		UID:       p.UID,
+		Labels:    p.Labels,
+	}
+	s.pods[p.UID] = pod
+
+	return s.updatePod(pod)
+}
+
+func (s *podDisruptionBudgetPodStore) deletePod(obj interface{}) error {
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.pods, p.UID)
+
+	return s.store.Delete(p)
+}
+
+func (s *podDisruptionBudgetPodStore) replacePods(list []interface{}) error {
+	s.mutex.Lock()
+	s.pods = map[types.UID]metav1.ObjectMeta{}
+	s.mutex.Unlock()
+
+	if err := s.store.Replace(nil, ""); err != nil {
+		return err
+	}
+
+	for _, o := range list {
+		if err := s.addPod(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *podDisruptionBudgetPodStore) addBudget(obj interface{}) error {
+	pdb, ok := obj.(*v1beta1.PodDisruptionBudget)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// An empty or invalid selector matches no pods, the same way the
+	// disruption controller treats it.
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil || selector.Empty() {
+		selector = labels.Nothing()
+	}
+
+	s.budgets[pdb.UID] = budgetSelector{
+		namespace: pdb.Namespace,
+		name:      pdb.Name,
+		selector:  selector,
+	}
+
+	return s.updateNamespace(pdb.Namespace)
+}
+
+func (s *podDisruptionBudgetPodStore) deleteBudget(obj interface{}) error {
+	pdb, ok := obj.(*v1beta1.PodDisruptionBudget)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.budgets, pdb.UID)
+
+	return s.updateNamespace(pdb.Namespace)
+}
+
+func (s *podDisruptionBudgetPodStore) replaceBudgets(list []interface{}) error {
+	s.mutex.Lock()
+	s.budgets = map[types.UID]budgetSelector{}
+	for _, pod := range s.pods {
+		if err := s.updatePod(pod); err != nil {
+			s.mutex.Unlock()
+			return err
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, o := range list {
+		if err := s.addBudget(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateNamespace recomputes the pod disruption budgets of all pods in the
+// given namespace.
+func (s *podDisruptionBudgetPodStore) updateNamespace(namespace string) error {
+	for _, pod := range s.pods {
+		if pod.Namespace != namespace {
+			continue
+		}
+		if err := s.updatePod(pod); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updatePod recomputes the pod disruption budgets selecting the given pod and
+// passes them on to the underlying MetricsStore.
+func (s *podDisruptionBudgetPodStore) updatePod(pod metav1.ObjectMeta) error {
+	p := &podDisruptionBudgets{
+		ObjectMeta: pod,
+	}
+
+	podLabels := labels.Set(pod.Labels)
+	for _, b := range s.budgets {
+		if b.namespace == pod.Namespace && b.selector.Matches(podLabels) {
+			p.budgets = append(p.budgets, b.name)
+		}
+	}
+	sort.Strings(p.budgets)
+
+	return s.store.Add(p)
+}
+
+// callbackStore implements the k8s.io/client-go/tools/cache.Store interface
+// by calling the given functions, so that a single type can consume the
+// objects of several reflectors.
+type callbackStore struct {
+	add     func(interface{}) error
+	delete  func(interface{}) error
+	replace func([]interface{}) error
+}
+
+// Add implements the Add method of the store interface.
+func (s *callbackStore) Add(obj interface{}) error {
+	return s.add(obj)
+}
+
+// Update implements the Update method of the store interface.
+func (s *callbackStore) Update(obj interface{}) error {
+	return s.add(obj)
+}
+
+// Delete implements the Delete method of the store interface.
+func (s *callbackStore) Delete(obj interface{}) error {
+	return s.delete(obj)
+}
+
+// List implements the List method of the store interface.
+func (s *callbackStore) List() []interface{} {
+	return nil
+}
+
+// ListKeys implements the ListKeys method of the store interface.
+func (s *callbackStore) ListKeys() []string {
+	return nil
+}
+
+// Get implements the Get method of the store interface.
+func (s *callbackStore) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// GetByKey implements the GetByKey method of the store interface.
+func (s *callbackStore) GetByKey(key string) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Replace implements the Replace method of the store interface.
+func (s *callbackStore) Replace(list []interface{}, _ string) error {
+	return s.replace(list)
+}
+
+// Resync implements the Resync method of the store interface.
+func (s *callbackStore) Resync() error {
+	return nil
+}
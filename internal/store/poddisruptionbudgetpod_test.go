@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestPodDisruptionBudgetPodStore(t *testing.T) {
+	const metadata = `
+		# HELP kube_poddisruptionbudget_pod Information about the pod disruption budgets selecting a pod.
+		# TYPE kube_poddisruptionbudget_pod gauge
+	`
+
+	newPod := func(name, namespace string, labels map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID("uid-" + namespace + "-" + name),
+				Labels:    labels,
+			},
+		}
+	}
+
+	newBudget := func(name, namespace string, selector *metav1.LabelSelector) *v1beta1.PodDisruptionBudget {
+		return &v1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID("uid-" + namespace + "-" + name),
+			},
+			Spec: v1beta1.PodDisruptionBudgetSpec{
+				Selector: selector,
+			},
+		}
+	}
+
+	pod1 := newPod("pod1", "ns1", map[string]string{"app": "web", "tier": "frontend"})
+	pod2 := newPod("pod2", "ns1", map[string]string{"app": "db"})
+	pod3 := newPod("pod3", "ns2", map[string]string{"app": "web"})
+
+	pdb1 := newBudget("web", "ns1", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}})
+	pdb2 := newBudget("frontend", "ns1", &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"frontend"}},
+		},
+	})
+	// An empty selector selects no pods.
+	pdb3 := newBudget("empty", "ns1", &metav1.LabelSelector{})
+
+	ms := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(podDisruptionBudgetPodMetricFamilies),
+		generator.ComposeMetricGenFuncs(podDisruptionBudgetPodMetricFamilies),
+	)
+	s := newPodDisruptionBudgetPodStore(ms)
+	pods, budgets := s.podStore(), s.budgetStore()
+
+	write := func() string {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		return strings.TrimSpace(w.String())
+	}
+
+	if err := pods.Replace([]interface{}{pod1, pod2, pod3}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgets.Replace([]interface{}{pdb1, pdb2, pdb3}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := metadata + `
+		kube_poddisruptionbudget_pod{namespace="ns1",pod="pod1",poddisruptionbudget="frontend"} 1
+		kube_poddisruptionbudget_pod{namespace="ns1",pod="pod1",poddisruptionbudget="web"} 1
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing a selector updates the pods of its namespace.
+	pdb1Updated := pdb1.DeepCopy()
+	pdb1Updated.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+	if err := budgets.Update(pdb1Updated); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_poddisruptionbudget_pod{namespace="ns1",pod="pod1",poddisruptionbudget="frontend"} 1
+		kube_poddisruptionbudget_pod{namespace="ns1",pod="pod2",poddisruptionbudget="web"} 1
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting budgets and pods removes their series.
+	if err := budgets.Delete(pdb2); err != nil {
+		t.Fatal(err)
+	}
+	if err := pods.Delete(pod2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compareOutput(metadata, write()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podFanOut is the store of the pod reflector shared by the opt-in metric
+// families derived from pods, which passes every change on to the stores
+// registered with it. It keeps the current pods, so that stores registered
+// after the reflector has listed the pods, e.g. the ones of resources started
+// on first request, are handed the pods seen so far. To keep the memory of
+// the pods small, they are stripped down to the fields the registered stores
+// use, see stripPod, before being kept and passed on.
+type podFanOut struct {
+	mutex  sync.Mutex
+	pods   cache.Store
+	stores []cache.Store
+}
+
+func newPodFanOut() *podFanOut {
+	return &podFanOut{
+		pods: cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+}
+
+// register passes all future changes of the pods on to the given store, after
+// handing it the current pods.
+func (f *podFanOut) register(store cache.Store) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.stores = append(f.stores, store)
+	return store.Replace(f.pods.List(), "")
+}
+
+// each calls the given function with the store of the current pods and the
+// registered stores, and returns the first error.
+func (f *podFanOut) each(fn func(cache.Store) error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	err := fn(f.pods)
+	for _, s := range f.stores {
+		if sErr := fn(s); err == nil {
+			err = sErr
+		}
+	}
+	return err
+}
+
+// Add implements the Add method of the store interface.
+func (f *podFanOut) Add(obj interface{}) error {
+	obj = stripPod(obj)
+	return f.each(func(s cache.Store) error { return s.Add(obj) })
+}
+
+// Update implements the Update method of the store interface.
+func (f *podFanOut) Update(obj interface{}) error {
+	obj = stripPod(obj)
+	return f.each(func(s cache.Store) error { return s.Update(obj) })
+}
+
+// Delete implements the Delete method of the store interface.
+func (f *podFanOut) Delete(obj interface{}) error {
+	obj = stripPod(obj)
+	return f.each(func(s cache.Store) error { return s.Delete(obj) })
+}
+
+// Replace implements the Replace method of the store interface.
+func (f *podFanOut) Replace(list []interface{}, resourceVersion string) error {
+	stripped := make([]interface{}, len(list))
+	for i, obj := range list {
+		stripped[i] = stripPod(obj)
+	}
+	return f.each(func(s cache.Store) error { return s.Replace(stripped, resourceVersion) })
+}
+
+// List implements the List method of the store interface.
+func (f *podFanOut) List() []interface{} {
+	return f.pods.List()
+}
+
+// ListKeys implements the ListKeys method of the store interface.
+func (f *podFanOut) ListKeys() []string {
+	return f.pods.ListKeys()
+}
+
+// Get implements the Get method of the store interface.
+func (f *podFanOut) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	return f.pods.Get(obj)
+}
+
+// GetByKey implements the GetByKey method of the store interface.
+func (f *podFanOut) GetByKey(key string) (item interface{}, exists bool, err error) {
+	return f.pods.GetByKey(key)
+}
+
+// Resync implements the Resync method of the store interface.
+func (f *podFanOut) Resync() error {
+	return nil
+}
+
+// stripPod returns a copy of the given pod with only the fields used by the
+// stores fed by podFanOut: the metadata identifying the pod, its labels and
+// owner references, its node, the resources of its containers and its phase
+// and container statuses. Any further field a store fed by podFanOut uses has
+// to be kept here as well. Objects which are no pods are returned as they are.
+func stripPod(obj interface{}) interface{} {
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		return obj
+	}
+
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       p.Namespace,
+			Name:            p.Name,
+			UID:             p.UID,
+			ResourceVersion: p.ResourceVersion,
+			Labels:          p.Labels,
+			OwnerReferences: p.OwnerReferences,
+		},
+		Spec: v1.PodSpec{
+			NodeName:       p.Spec.NodeName,
+			Containers:     stripContainers(p.Spec.Containers),
+			InitContainers: stripContainers(p.Spec.InitContainers),
+			Overhead:       p.Spec.Overhead,
+		},
+		Status: v1.PodStatus{
+			Phase:                 p.Status.Phase,
+			InitContainerStatuses: p.Status.InitContainerStatuses,
+			ContainerStatuses:     p.Status.ContainerStatuses,
+		},
+	}
+}
+
+// stripContainers returns copies of the given containers with only their
+// names and resources.
+func stripContainers(containers []v1.Container) []v1.Container {
+	if containers == nil {
+		return nil
+	}
+
+	stripped := make([]v1.Container, len(containers))
+	for i, c := range containers {
+		stripped[i] = v1.Container{Name: c.Name, Resources: c.Resources}
+	}
+	return stripped
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPodFanOut(t *testing.T) {
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name}}
+	}
+
+	fanOut := newPodFanOut()
+	first := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := fanOut.register(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := fanOut.Replace([]interface{}{newPod("pod1"), newPod("pod2")}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fanOut.Delete(newPod("pod1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stores registered later are handed the current pods.
+	second := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := fanOut.register(second); err != nil {
+		t.Fatal(err)
+	}
+	if err := fanOut.Add(newPod("pod3")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"ns1/pod2", "ns1/pod3"}
+	for name, s := range map[string]cache.Store{"fan-out": fanOut, "first": first, "second": second} {
+		got := s.ListKeys()
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected pods %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestPodFanOutStripsPods(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns1",
+			Name:        "pod1",
+			UID:         "uid1",
+			Labels:      map[string]string{"app": "example"},
+			Annotations: map[string]string{"large": "annotation"},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node1",
+			Containers: []v1.Container{{
+				Name:  "container1",
+				Image: "k8s.gcr.io/hyperkube1",
+				Env:   []v1.EnvVar{{Name: "KEY", Value: "value"}},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+				},
+			}},
+			Volumes: []v1.Volume{{Name: "volume1"}},
+		},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "container1", RestartCount: 1}},
+			Conditions:        []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+		},
+	}
+
+	fanOut := newPodFanOut()
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := fanOut.register(store); err != nil {
+		t.Fatal(err)
+	}
+	if err := fanOut.Add(pod); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "pod1",
+			UID:       "uid1",
+			Labels:    map[string]string{"app": "example"},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node1",
+			Containers: []v1.Container{{
+				Name:      "container1",
+				Resources: pod.Spec.Containers[0].Resources,
+			}},
+		},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			ContainerStatuses: pod.Status.ContainerStatuses,
+		},
+	}
+	for name, s := range map[string]cache.Store{"fan-out": fanOut, "registered": store} {
+		got, ok, err := s.GetByKey("ns1/pod1")
+		if err != nil || !ok {
+			t.Fatalf("%s: expected pod, got %v, %v", name, ok, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected pod %+v, got %+v", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+var (
+	podWorkloadMetricFamilies = []generator.FamilyGenerator{
+		{
+			Name:  "kube_pod_workload",
+			Type:  metric.Gauge,
+			Help:  "The top-level workload controlling a pod, e.g. the Deployment of the ReplicaSet of the pod.",
+			OptIn: true,
+			GenerateFunc: wrapPodWorkloadFunc(func(p *podWorkload) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"workload_kind", "workload_name"},
+							LabelValues: []string{p.kind, p.name},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+	}
+)
+
+func wrapPodWorkloadFunc(f func(*podWorkload) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		p := obj.(*podWorkload)
+
+		metricFamily := f(p)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descPodLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{p.Namespace, p.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+// podWorkload holds the top-level workload controlling a pod.
+type podWorkload struct {
+	metav1.ObjectMeta
+	kind string
+	name string
+}
+
+// controllerRef is the controller of an object. The zero value stands for an
+// object without controller.
+type controllerRef struct {
+	uid  types.UID
+	kind string
+	name string
+}
+
+func controllerOf(o metav1.Object) controllerRef {
+	ref := metav1.GetControllerOf(o)
+	if ref == nil {
+		return controllerRef{}
+	}
+	return controllerRef{uid: ref.UID, kind: ref.Kind, name: ref.Name}
+}
+
+// podWorkloadStore resolves the controller of pods to the top-level workload
+// controlling them, by following the controllers of the ReplicaSets and Jobs
+// controlling pods, e.g. to their Deployment or CronJob, and hands the result
+// over to the given MetricsStore. Pods, ReplicaSets and Jobs are fed in
+// through the stores returned by podStore and ownerStore, each registered
+// with its own reflector.
+type podWorkloadStore struct {
+	mutex sync.Mutex
+	pods  map[types.UID]podController
+	// owners holds the controllers of the ReplicaSets and Jobs by their
+	// kind and UID.
+	owners map[string]map[types.UID]controllerRef
+	// ownerPods holds the pods controlled by every controller by its UID.
+	ownerPods map[types.UID]map[types.UID]struct{}
+	store     *metricsstore.MetricsStore
+}
+
+// podController holds a pod together with its controller.
+type podController struct {
+	meta       metav1.ObjectMeta
+	controller controllerRef
+}
+
+func newPodWorkloadStore(store *metricsstore.MetricsStore) *podWorkloadStore {
+	return &podWorkloadStore{
+		pods:      map[types.UID]podController{},
+		owners:    map[string]map[types.UID]controllerRef{},
+		ownerPods: map[types.UID]map[types.UID]struct{}{},
+		store:     store,
+	}
+}
+
+// podStore returns the store to register with the pod reflector.
+func (s *podWorkloadStore) podStore() *callbackStore {
+	return &callbackStore{
+		add:     s.addPod,
+		delete:  s.deletePod,
+		replace: s.replacePods,
+	}
+}
+
+// ownerStore returns the store to register with the reflector of the
+// ReplicaSets or Jobs of the given kind.
+func (s *podWorkloadStore) ownerStore(kind string) *callbackStore {
+	return &callbackStore{
+		add: func(obj interface{}) error {
+			return s.addOwner(kind, obj)
+		},
+		delete: func(obj interface{}) error {
+			return s.deleteOwner(kind, obj)
+		},
+		replace: func(list []interface{}) error {
+			return s.replaceOwners(kind, list)
+		},
+	}
+}
+
+func (s *podWorkloadStore) addPod(obj interface{}) error {
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removePod(p.UID)
+
+	pod := podController{
+		meta: metav1.ObjectMeta{
+			Namespace: p.Namespace,
+			Name:      p.Name,
+			UID:       p.UID,
+		},
+		controller: controllerOf(p),
+	}
+	s.pods[p.UID] = pod
+	if pod.controller.uid != "" {
+		if _, ok := s.ownerPods[pod.controller.uid]; !ok {
+			s.ownerPods[pod.controller.uid] = map[types.UID]struct{}{}
+		}
+		s.ownerPods[pod.controller.uid][p.UID] = struct{}{}
+	}
+
+	return s.updatePod(pod)
+}
+
+func (s *podWorkloadStore) deletePod(obj interface{}) error {
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removePod(p.UID)
+
+	return s.store.Delete(p)
+}
+
+// removePod forgets the given pod.
+func (s *podWorkloadStore) removePod(uid types.UID) {
+	pod, ok := s.pods[uid]
+	if !ok {
+		return
+	}
+
+	delete(s.pods, uid)
+	if pods, ok := s.ownerPods[pod.controller.uid]; ok {
+		delete(pods, uid)
+		if len(pods) == 0 {
+			delete(s.ownerPods, pod.controller.uid)
+		}
+	}
+}
+
+func (s *podWorkloadStore) replacePods(list []interface{}) error {
+	s.mutex.Lock()
+	s.pods = map[types.UID]podController{}
+	s.ownerPods = map[types.UID]map[types.UID]struct{}{}
+	s.mutex.Unlock()
+
+	if err := s.store.Replace(nil, ""); err != nil {
+		return err
+	}
+
+	for _, o := range list {
+		if err := s.addPod(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ownerMeta returns the metadata of the given ReplicaSet or Job.
+func ownerMeta(obj interface{}) (metav1.Object, bool) {
+	switch o := obj.(type) {
+	case *appsv1.ReplicaSet:
+		return o, true
+	case *batchv1.Job:
+		return o, true
+	}
+	return nil, false
+}
+
+func (s *podWorkloadStore) addOwner(kind string, obj interface{}) error {
+	o, ok := ownerMeta(obj)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.owners[kind]; !ok {
+		s.owners[kind] = map[types.UID]controllerRef{}
+	}
+	controller := controllerOf(o)
+	if old, ok := s.owners[kind][o.GetUID()]; ok && old == controller {
+		return nil
+	}
+	s.owners[kind][o.GetUID()] = controller
+
+	return s.updateOwnerPods(o.GetUID())
+}
+
+func (s *podWorkloadStore) deleteOwner(kind string, obj interface{}) error {
+	o, ok := ownerMeta(obj)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.owners[kind][o.GetUID()]; !ok {
+		return nil
+	}
+	delete(s.owners[kind], o.GetUID())
+
+	return s.updateOwnerPods(o.GetUID())
+}
+
+func (s *podWorkloadStore) replaceOwners(kind string, list []interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Only replace the owners of the given kind, as the owners of the
+	// other kind are fed in by another reflector.
+	owners := make(map[types.UID]controllerRef, len(list))
+	for _, obj := range list {
+		if o, ok := ownerMeta(obj); ok {
+			owners[o.GetUID()] = controllerOf(o)
+		}
+	}
+	s.owners[kind] = owners
+
+	for _, pod := range s.pods {
+		if pod.controller.kind != kind {
+			continue
+		}
+		if err := s.updatePod(pod); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateOwnerPods recomputes the workloads of the pods controlled by the
+// owner with the given UID.
+func (s *podWorkloadStore) updateOwnerPods(uid types.UID) error {
+	for podUID := range s.ownerPods[uid] {
+		if err := s.updatePod(s.pods[podUID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updatePod resolves the top-level workload of the given pod and passes it on
+// to the underlying MetricsStore. Pods controlled by a ReplicaSet or Job which
+// has not been seen yet, or which has no controller itself, are reported with
+// the ReplicaSet or Job as their workload.
+func (s *podWorkloadStore) updatePod(pod podController) error {
+	w := &podWorkload{
+		ObjectMeta: pod.meta,
+		kind:       "<none>",
+		name:       "<none>",
+	}
+
+	if c := pod.controller; c.uid != "" {
+		w.kind, w.name = c.kind, c.name
+		if owner, ok := s.owners[c.kind][c.uid]; ok && owner.uid != "" {
+			w.kind, w.name = owner.kind, owner.name
+		}
+	}
+
+	return s.store.Add(w)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestPodWorkloadStore(t *testing.T) {
+	const metadata = `
+		# HELP kube_pod_workload The top-level workload controlling a pod, e.g. the Deployment of the ReplicaSet of the pod.
+		# TYPE kube_pod_workload gauge
+	`
+
+	controlledBy := func(kind, name string) []metav1.OwnerReference {
+		controller := true
+		return []metav1.OwnerReference{
+			{Kind: kind, Name: name, UID: types.UID("uid-" + name), Controller: &controller},
+		}
+	}
+	objectMeta := func(name string, owners []metav1.OwnerReference) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "ns1",
+			UID:             types.UID("uid-" + name),
+			OwnerReferences: owners,
+		}
+	}
+
+	pod1 := &v1.Pod{ObjectMeta: objectMeta("pod1", controlledBy("ReplicaSet", "rs1"))}
+	pod2 := &v1.Pod{ObjectMeta: objectMeta("pod2", controlledBy("Job", "job1"))}
+	pod3 := &v1.Pod{ObjectMeta: objectMeta("pod3", controlledBy("StatefulSet", "sts1"))}
+	pod4 := &v1.Pod{ObjectMeta: objectMeta("pod4", nil)}
+	rs1 := &appsv1.ReplicaSet{ObjectMeta: objectMeta("rs1", controlledBy("Deployment", "deployment1"))}
+	job1 := &batchv1.Job{ObjectMeta: objectMeta("job1", controlledBy("CronJob", "cronjob1"))}
+
+	ms := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(podWorkloadMetricFamilies),
+		generator.ComposeMetricGenFuncs(podWorkloadMetricFamilies),
+	)
+	s := newPodWorkloadStore(ms)
+	pods := s.podStore()
+	replicaSets := s.ownerStore("ReplicaSet")
+	jobs := s.ownerStore("Job")
+
+	write := func() string {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		return strings.TrimSpace(w.String())
+	}
+
+	// Pods whose ReplicaSet or Job has not been seen yet are reported with
+	// their direct controller.
+	if err := pods.Replace([]interface{}{pod1, pod2, pod3, pod4}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := metadata + `
+		kube_pod_workload{namespace="ns1",pod="pod1",workload_kind="ReplicaSet",workload_name="rs1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod2",workload_kind="Job",workload_name="job1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod3",workload_kind="StatefulSet",workload_name="sts1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod4",workload_kind="<none>",workload_name="<none>"} 1
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replicaSets.Replace([]interface{}{rs1}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Add(job1); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_pod_workload{namespace="ns1",pod="pod1",workload_kind="Deployment",workload_name="deployment1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod2",workload_kind="CronJob",workload_name="cronjob1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod3",workload_kind="StatefulSet",workload_name="sts1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod4",workload_kind="<none>",workload_name="<none>"} 1
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Orphaned ReplicaSets are the top-level workload of their pods.
+	rs1Orphaned := rs1.DeepCopy()
+	rs1Orphaned.OwnerReferences = nil
+	if err := replicaSets.Update(rs1Orphaned); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Delete(job1); err != nil {
+		t.Fatal(err)
+	}
+	if err := pods.Delete(pod4); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_pod_workload{namespace="ns1",pod="pod1",workload_kind="ReplicaSet",workload_name="rs1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod2",workload_kind="Job",workload_name="job1"} 1
+		kube_pod_workload{namespace="ns1",pod="pod3",workload_kind="StatefulSet",workload_name="sts1"} 1
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+}
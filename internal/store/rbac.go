@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
+)
+
+// apiResource is a Kubernetes API resource in an API group and version.
+type apiResource struct {
+	group    string
+	version  string
+	resource string
+}
+
+// PolicyRules returns the RBAC policy rules needed to list and watch the
+// objects of the given resources with the given opt-in metric families
+// enabled, one rule per API group.
+func PolicyRules(resources []string, optIn ksmtypes.OptInLister) ([]rbacv1.PolicyRule, error) {
+	groups := map[string]map[string]struct{}{}
+	for _, r := range resources {
+		definition, ok := resourceDefinitions[r]
+		if !ok {
+			return nil, errors.Errorf("resource %s does not exist. Available resources: %s", r, strings.Join(availableResources(), ","))
+		}
+		for _, a := range definition.apiResources {
+			if !a.listed(optIn) {
+				continue
+			}
+			if groups[a.group] == nil {
+				groups[a.group] = map[string]struct{}{}
+			}
+			groups[a.group][a.resource] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	rules := make([]rbacv1.PolicyRule, 0, len(names))
+	for _, group := range names {
+		resources := make([]string, 0, len(groups[group]))
+		for resource := range groups[group] {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: resources,
+			Verbs:     []string{"list", "watch"},
+		})
+	}
+
+	return rules, nil
+}
+
+// ListedAPIResources returns the API resources listed and watched by the
+// stores of the given resource with the given opt-in metric families enabled.
+// The first one is the API resource the metrics of the resource are generated
+// from, e.g. deployments for deployments.
+func ListedAPIResources(resource string, optIn ksmtypes.OptInLister) ([]CollectorAPIResource, bool) {
+	definition, ok := resourceDefinitions[resource]
+	if !ok {
+		return nil, false
+	}
+
+	apiResources := []CollectorAPIResource{}
+	for _, a := range definition.apiResources {
+		if !a.listed(optIn) {
+			continue
+		}
+		apiResources = append(apiResources, CollectorAPIResource{
+			Group:    a.group,
+			Version:  a.version,
+			Resource: a.resource,
+			OptIn:    len(a.optInMetricFamilies) > 0,
+		})
+	}
+	return apiResources, true
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/kube-state-metrics/pkg/optin"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestPolicyRules(t *testing.T) {
+	resources := []string{"pods", "deployments", "poddisruptionbudgets", "statefulsets"}
+
+	tests := []struct {
+		optIn    options.MetricSet
+		expected []rbacv1.PolicyRule
+	}{
+		{
+			optIn: options.MetricSet{},
+			expected: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "watch"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets"}, Verbs: []string{"list", "watch"}},
+				{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: []string{"list", "watch"}},
+			},
+		},
+		{
+			optIn: options.MetricSet{"kube_workload_.*": {}, "kube_pod_workload": {}},
+			expected: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "watch"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"daemonsets", "deployments", "replicasets", "statefulsets"}, Verbs: []string{"list", "watch"}},
+				{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"list", "watch"}},
+				{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: []string{"list", "watch"}},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		optIn, err := optin.NewMetricFamilyFilter(test.optIn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules, err := PolicyRules(resources, optIn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rules, test.expected) {
+			t.Errorf("%d: expected rules:\n%v\ngot:\n%v", i, test.expected, rules)
+		}
+	}
+
+	if _, err := PolicyRules([]string{"pods", "unknown"}, nil); err == nil {
+		t.Fatal("expected error for unknown resource")
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+)
+
+// resourceDefinition describes a resource kube-state-metrics can collect.
+type resourceDefinition struct {
+	// kind is the kind of the objects of the resource.
+	kind string
+	// clusterScoped is set for resources whose objects do not belong to the
+	// namespaces kube-state-metrics is configured with. Leases are the node
+	// leases in the kube-node-lease namespace, which belong to nodes.
+	clusterScoped bool
+	// buildStores builds the stores of the resource.
+	buildStores func(b *Builder) []cache.Store
+	// metricFamilies holds the metric families exposed by the stores,
+	// including the annotations metric family, which is only exposed for
+	// allowed annotations.
+	metricFamilies []generator.FamilyGenerator
+	// apiResources holds the API resources listed and watched by the stores.
+	// The first one is the API resource the metrics are generated from.
+	apiResources []listedAPIResource
+}
+
+// listedAPIResource is an API resource listed and watched by the stores of a
+// resource. If optInMetricFamilies is set, it is only listed and watched if
+// at least one of these opt-in metric families is enabled.
+type listedAPIResource struct {
+	apiResource
+	optInMetricFamilies []string
+}
+
+// listed returns whether the API resource is listed and watched with the
+// given opt-in metric families enabled.
+func (a listedAPIResource) listed(optIn ksmtypes.OptInLister) bool {
+	if len(a.optInMetricFamilies) == 0 {
+		return true
+	}
+	if optIn == nil {
+		return false
+	}
+	for _, name := range a.optInMetricFamilies {
+		if optIn.IsOptedIn(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceDefinitions holds the definitions of all resources, by name. It is
+// the single source of the stores, kinds, metric families and API resources
+// of the resources. It is assigned in init as the stores refer to it when
+// they are built.
+var resourceDefinitions map[string]resourceDefinition
+
+func init() {
+	resourceDefinitions = map[string]resourceDefinition{
+		"certificatesigningrequests": {
+			kind:           "CertificateSigningRequest",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildCsrStore()} },
+			metricFamilies: collectorMetricFamilies(csrAnnotationsMetricFamily(nil), csrMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"certificates.k8s.io", "v1beta1", "certificatesigningrequests"}, nil},
+			},
+		},
+		"configmaps": {
+			kind:           "ConfigMap",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildConfigMapStore()} },
+			metricFamilies: collectorMetricFamilies(configMapAnnotationsMetricFamily(nil), configMapMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "configmaps"}, nil},
+			},
+		},
+		"cronjobs": {
+			kind:           "CronJob",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildCronJobStore()} },
+			metricFamilies: collectorMetricFamilies(cronJobAnnotationsMetricFamily(nil), cronJobMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"batch", "v1beta1", "cronjobs"}, nil},
+			},
+		},
+		"daemonsets": {
+			kind:           "DaemonSet",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildDaemonSetStore()} },
+			metricFamilies: collectorMetricFamilies(daemonSetAnnotationsMetricFamily(nil), daemonSetMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"apps", "v1", "daemonsets"}, nil},
+			},
+		},
+		"deployments": {
+			kind:           "Deployment",
+			buildStores:    func(b *Builder) []cache.Store { return b.buildDeploymentStores() },
+			metricFamilies: collectorMetricFamilies(deploymentAnnotationsMetricFamily(nil), deploymentMetricFamilies(nil), workloadMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"apps", "v1", "deployments"}, nil},
+				{apiResource{"apps", "v1", "statefulsets"}, metricFamilyNames(workloadMetricFamilies)},
+				{apiResource{"apps", "v1", "daemonsets"}, metricFamilyNames(workloadMetricFamilies)},
+				{apiResource{"apps", "v1", "replicasets"}, metricFamilyNames(workloadMetricFamilies)},
+			},
+		},
+		"endpoints": {
+			kind:           "Endpoints",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildEndpointsStore()} },
+			metricFamilies: collectorMetricFamilies(endpointAnnotationsMetricFamily(nil), endpointMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "endpoints"}, nil},
+			},
+		},
+		"horizontalpodautoscalers": {
+			kind:           "HorizontalPodAutoscaler",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildHPAStore()} },
+			metricFamilies: collectorMetricFamilies(hpaAnnotationsMetricFamily(nil), hpaMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"autoscaling", "v2beta1", "horizontalpodautoscalers"}, nil},
+			},
+		},
+		"ingresses": {
+			kind:           "Ingress",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildIngressStore()} },
+			metricFamilies: collectorMetricFamilies(ingressAnnotationsMetricFamily(nil), ingressMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"extensions", "v1beta1", "ingresses"}, nil},
+			},
+		},
+		"jobs": {
+			kind:           "Job",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildJobStore()} },
+			metricFamilies: collectorMetricFamilies(jobAnnotationsMetricFamily(nil), jobMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"batch", "v1", "jobs"}, nil},
+			},
+		},
+		"leases": {
+			kind:           "Lease",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildLeases()} },
+			metricFamilies: collectorMetricFamilies(leaseAnnotationsMetricFamily(nil), leaseMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"coordination.k8s.io", "v1", "leases"}, nil},
+			},
+		},
+		"limitranges": {
+			kind:           "LimitRange",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildLimitRangeStore()} },
+			metricFamilies: collectorMetricFamilies(limitRangeAnnotationsMetricFamily(nil), limitRangeMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "limitranges"}, nil},
+			},
+		},
+		"mutatingwebhookconfigurations": {
+			kind:           "MutatingWebhookConfiguration",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildMutatingWebhookConfigurationStore()} },
+			metricFamilies: collectorMetricFamilies(mutatingWebhookConfigurationAnnotationsMetricFamily(nil), mutatingWebhookConfigurationMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"admissionregistration.k8s.io", "v1beta1", "mutatingwebhookconfigurations"}, nil},
+			},
+		},
+		"namespaces": {
+			kind:           "Namespace",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return b.buildNamespaceStores() },
+			metricFamilies: collectorMetricFamilies(namespaceAnnotationsMetricFamily(nil), namespaceMetricFamilies, namespaceRollupMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "namespaces"}, nil},
+				{apiResource{"", "v1", "pods"}, []string{namespacePodsMetricName, namespaceContainersCrashLoopBackOffMetricName}},
+				{apiResource{"apps", "v1", "daemonsets"}, []string{namespaceWorkloadsMetricName}},
+				{apiResource{"apps", "v1", "deployments"}, []string{namespaceWorkloadsMetricName}},
+				{apiResource{"apps", "v1", "statefulsets"}, []string{namespaceWorkloadsMetricName}},
+			},
+		},
+		"networkpolicies": {
+			kind:           "NetworkPolicy",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildNetworkPolicyStore()} },
+			metricFamilies: collectorMetricFamilies(networkpolicyAnnotationsMetricFamily(nil), networkpolicyMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"networking.k8s.io", "v1", "networkpolicies"}, nil},
+			},
+		},
+		"nodes": {
+			kind:           "Node",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return b.buildNodeStores() },
+			metricFamilies: collectorMetricFamilies(nodeAnnotationsMetricFamily(nil), nodeMetricFamilies(nil), nodeResourcesMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "nodes"}, nil},
+				{apiResource{"", "v1", "pods"}, metricFamilyNames(nodeResourcesMetricFamilies)},
+			},
+		},
+		"persistentvolumeclaims": {
+			kind:           "PersistentVolumeClaim",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildPersistentVolumeClaimStore()} },
+			metricFamilies: collectorMetricFamilies(persistentVolumeClaimAnnotationsMetricFamily(nil), persistentVolumeClaimMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "persistentvolumeclaims"}, nil},
+			},
+		},
+		"persistentvolumes": {
+			kind:           "PersistentVolume",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildPersistentVolumeStore()} },
+			metricFamilies: collectorMetricFamilies(persistentVolumeAnnotationsMetricFamily(nil), persistentVolumeMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "persistentvolumes"}, nil},
+			},
+		},
+		"poddisruptionbudgets": {
+			kind:           "PodDisruptionBudget",
+			buildStores:    func(b *Builder) []cache.Store { return b.buildPodDisruptionBudgetStores() },
+			metricFamilies: collectorMetricFamilies(podDisruptionBudgetAnnotationsMetricFamily(nil), podDisruptionBudgetMetricFamilies, podDisruptionBudgetPodMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"policy", "v1beta1", "poddisruptionbudgets"}, nil},
+				{apiResource{"", "v1", "pods"}, metricFamilyNames(podDisruptionBudgetPodMetricFamilies)},
+			},
+		},
+		"pods": {
+			kind:           "Pod",
+			buildStores:    func(b *Builder) []cache.Store { return b.buildPodStores() },
+			metricFamilies: collectorMetricFamilies(podAnnotationsMetricFamily(nil), podMetricFamilies, podWorkloadMetricFamilies, podCrashLoopMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "pods"}, nil},
+				{apiResource{"apps", "v1", "replicasets"}, metricFamilyNames(podWorkloadMetricFamilies)},
+				{apiResource{"batch", "v1", "jobs"}, metricFamilyNames(podWorkloadMetricFamilies)},
+			},
+		},
+		"replicasets": {
+			kind:           "ReplicaSet",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildReplicaSetStore()} },
+			metricFamilies: collectorMetricFamilies(replicaSetAnnotationsMetricFamily(nil), replicaSetMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"apps", "v1", "replicasets"}, nil},
+			},
+		},
+		"replicationcontrollers": {
+			kind:           "ReplicationController",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildReplicationControllerStore()} },
+			metricFamilies: collectorMetricFamilies(replicationControllerAnnotationsMetricFamily(nil), replicationControllerMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "replicationcontrollers"}, nil},
+			},
+		},
+		"resourcequotas": {
+			kind:           "ResourceQuota",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildResourceQuotaStore()} },
+			metricFamilies: collectorMetricFamilies(resourceQuotaAnnotationsMetricFamily(nil), resourceQuotaMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "resourcequotas"}, nil},
+			},
+		},
+		"secrets": {
+			kind:           "Secret",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildSecretStore()} },
+			metricFamilies: collectorMetricFamilies(secretAnnotationsMetricFamily(nil), secretMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "secrets"}, nil},
+			},
+		},
+		"services": {
+			kind:           "Service",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildServiceStore()} },
+			metricFamilies: collectorMetricFamilies(serviceAnnotationsMetricFamily(nil), serviceMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"", "v1", "services"}, nil},
+			},
+		},
+		"statefulsets": {
+			kind:           "StatefulSet",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildStatefulSetStore()} },
+			metricFamilies: collectorMetricFamilies(statefulSetAnnotationsMetricFamily(nil), statefulSetMetricFamilies(nil)),
+			apiResources: []listedAPIResource{
+				{apiResource{"apps", "v1", "statefulsets"}, nil},
+			},
+		},
+		"storageclasses": {
+			kind:           "StorageClass",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildStorageClassStore()} },
+			metricFamilies: collectorMetricFamilies(storageClassAnnotationsMetricFamily(nil), storageClassMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"storage.k8s.io", "v1", "storageclasses"}, nil},
+			},
+		},
+		"validatingwebhookconfigurations": {
+			kind:           "ValidatingWebhookConfiguration",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildValidatingWebhookConfigurationStore()} },
+			metricFamilies: collectorMetricFamilies(validatingWebhookConfigurationAnnotationsMetricFamily(nil), validatingWebhookConfigurationMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"admissionregistration.k8s.io", "v1", "validatingwebhookconfigurations"}, nil},
+			},
+		},
+		"volumeattachments": {
+			kind:           "VolumeAttachment",
+			clusterScoped:  true,
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildVolumeAttachmentStore()} },
+			metricFamilies: collectorMetricFamilies(volumeAttachmentAnnotationsMetricFamily(nil), volumeAttachmentMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"storage.k8s.io", "v1beta1", "volumeattachments"}, nil},
+			},
+		},
+		"verticalpodautoscalers": {
+			kind:           "VerticalPodAutoscaler",
+			buildStores:    func(b *Builder) []cache.Store { return []cache.Store{b.buildVPAStore()} },
+			metricFamilies: collectorMetricFamilies(vpaAnnotationsMetricFamily(nil), vpaMetricFamilies),
+			apiResources: []listedAPIResource{
+				{apiResource{"autoscaling.k8s.io", "v1beta2", "verticalpodautoscalers"}, nil},
+			},
+		},
+	}
+}
+
+// metricFamilyNames returns the names of the given metric families.
+func metricFamilyNames(families []generator.FamilyGenerator) []string {
+	names := make([]string, 0, len(families))
+	for _, f := range families {
+		names = append(names, f.Name)
+	}
+	return names
+}
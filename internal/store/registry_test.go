@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+)
+
+func TestResourceDefinitions(t *testing.T) {
+	for r, d := range resourceDefinitions {
+		if d.kind == "" {
+			t.Errorf("missing kind of resource %s", r)
+		}
+		if d.buildStores == nil {
+			t.Errorf("missing stores of resource %s", r)
+		}
+		if len(d.metricFamilies) == 0 {
+			t.Errorf("missing metric families of resource %s", r)
+		}
+		if len(d.apiResources) == 0 {
+			t.Errorf("missing API resources of resource %s", r)
+			continue
+		}
+		if len(d.apiResources[0].optInMetricFamilies) != 0 {
+			t.Errorf("expected the first API resource of resource %s not to be opt-in", r)
+		}
+
+		optIn := map[string]bool{}
+		for _, f := range d.metricFamilies {
+			optIn[f.Name] = f.OptIn
+		}
+		for _, a := range d.apiResources {
+			for _, name := range a.optInMetricFamilies {
+				if !optIn[name] {
+					t.Errorf("API resource %s of resource %s depends on %s, which is not an opt-in metric family of the resource", a.resource, r, name)
+				}
+			}
+		}
+	}
+}
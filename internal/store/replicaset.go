@@ -17,8 +17,6 @@ limitations under the License.
 package store
 
 import (
-	"strconv"
-
 	"k8s.io/kube-state-metrics/pkg/metric"
 	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 
@@ -41,17 +39,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapReplicaSetFunc(func(r *v1.ReplicaSet) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(r.CreationTimestamp),
 				}
 			}),
 		},
@@ -148,41 +137,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Information about the ReplicaSet's owner.",
 			GenerateFunc: wrapReplicaSetFunc(func(r *v1.ReplicaSet) *metric.Family {
-				owners := r.GetOwnerReferences()
-
-				if len(owners) == 0 {
-					return &metric.Family{
-						Metrics: []*metric.Metric{
-							{
-								LabelKeys:   []string{"owner_kind", "owner_name", "owner_is_controller"},
-								LabelValues: []string{"<none>", "<none>", "<none>"},
-								Value:       1,
-							},
-						},
-					}
-				}
-
-				ms := make([]*metric.Metric, len(owners))
-
-				for i, owner := range owners {
-					if owner.Controller != nil {
-						ms[i] = &metric.Metric{
-							LabelValues: []string{owner.Kind, owner.Name, strconv.FormatBool(*owner.Controller)},
-						}
-					} else {
-						ms[i] = &metric.Metric{
-							LabelValues: []string{owner.Kind, owner.Name, "false"},
-						}
-					}
-				}
-
-				for _, m := range ms {
-					m.LabelKeys = []string{"owner_kind", "owner_name", "owner_is_controller"}
-					m.Value = 1
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: ownerMetrics(r.GetOwnerReferences()),
 				}
 			}),
 		},
@@ -203,9 +159,34 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_replicaset_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the ReplicaSet.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1.ReplicaSet) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(r.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// replicaSetAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a ReplicaSet as Prometheus labels.
+func replicaSetAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_replicaset_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapReplicaSetFunc(func(r *v1.ReplicaSet) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(r.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapReplicaSetFunc(f func(*v1.ReplicaSet) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		replicaSet := obj.(*v1.ReplicaSet)
@@ -38,6 +38,8 @@ func TestReplicaSetStore(t *testing.T) {
 	const metadata = `
 		# HELP kube_replicaset_created Unix creation timestamp
 		# TYPE kube_replicaset_created gauge
+		# HELP kube_replicaset_metadata_resource_version Resource version representing a specific version of the ReplicaSet.
+		# TYPE kube_replicaset_metadata_resource_version gauge
 	  # HELP kube_replicaset_metadata_generation Sequence number representing a specific generation of the desired state.
 		# TYPE kube_replicaset_metadata_generation gauge
 		# HELP kube_replicaset_status_replicas The number of replicas per ReplicaSet.
@@ -17,8 +17,6 @@ limitations under the License.
 package store
 
 import (
-	"strconv"
-
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,16 +37,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(r.CreationTimestamp),
 				}
 			}),
 		},
@@ -159,39 +149,39 @@ var (
 			Type: metric.Gauge,
 			Help: "Information about the ReplicationController's owner.",
 			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) *metric.Family {
-				labelKeys := []string{"owner_kind", "owner_name", "owner_is_controller"}
-				ms := []*metric.Metric{}
-
-				owners := r.GetOwnerReferences()
-				if len(owners) == 0 {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   labelKeys,
-						LabelValues: []string{"<none>", "<none>", "<none>"},
-						Value:       1,
-					})
-				} else {
-					for _, owner := range owners {
-						ownerIsController := "false"
-						if owner.Controller != nil {
-							ownerIsController = strconv.FormatBool(*owner.Controller)
-						}
-
-						ms = append(ms, &metric.Metric{
-							LabelKeys:   labelKeys,
-							LabelValues: []string{owner.Kind, owner.Name, ownerIsController},
-							Value:       1,
-						})
-					}
+				return &metric.Family{
+					Metrics: ownerMetrics(r.GetOwnerReferences()),
 				}
-
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the ReplicationController.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) *metric.Family {
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: resourceVersionMetric(r.ObjectMeta.ResourceVersion),
 				}
 			}),
 		},
 	}
 )
 
+// replicationControllerAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a ReplicationController as Prometheus labels.
+func replicationControllerAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_replicationcontroller_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(r.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapReplicationControllerFunc(f func(*v1.ReplicationController) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		replicationController := obj.(*v1.ReplicationController)
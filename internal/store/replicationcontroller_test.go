@@ -40,6 +40,8 @@ func TestReplicationControllerStore(t *testing.T) {
 	const metadata = `
 		# HELP kube_replicationcontroller_created Unix creation timestamp
 		# TYPE kube_replicationcontroller_created gauge
+		# HELP kube_replicationcontroller_metadata_resource_version Resource version representing a specific version of the ReplicationController.
+		# TYPE kube_replicationcontroller_metadata_resource_version gauge
 		# HELP kube_replicationcontroller_metadata_generation Sequence number representing a specific generation of the desired state.
 		# TYPE kube_replicationcontroller_metadata_generation gauge
 		# HELP kube_replicationcontroller_owner Information about the ReplicationController's owner.
@@ -17,6 +17,8 @@ limitations under the License.
 package store
 
 import (
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,17 +39,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(r.CreationTimestamp),
 				}
 			}),
 		},
@@ -80,9 +73,90 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_resourcequota_scope",
+			Type: metric.Gauge,
+			Help: "Scopes the resource quota is restricted to. One series for each scope.",
+			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
+				ms := make([]*metric.Metric, len(r.Spec.Scopes))
+
+				for i, scope := range r.Spec.Scopes {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"scope"},
+						LabelValues: []string{string(scope)},
+						Value:       1,
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name: "kube_resourcequota_scope_selector",
+			Type: metric.Gauge,
+			Help: "Scope selector requirements the resource quota is restricted to. One series for each requirement.",
+			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
+				ms := []*metric.Metric{}
+
+				if r.Spec.ScopeSelector == nil {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for _, req := range r.Spec.ScopeSelector.MatchExpressions {
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"scope", "operator", "values"},
+						LabelValues: []string{string(req.ScopeName), string(req.Operator), strings.Join(req.Values, ",")},
+						Value:       1,
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name: "kube_resourcequota_owner",
+			Type: metric.Gauge,
+			Help: "Information about the ResourceQuota's owner.",
+			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(r.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_resourcequota_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the ResourceQuota.",
+			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(r.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// resourceQuotaAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a ResourceQuota as Prometheus labels.
+func resourceQuotaAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_resourcequota_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(r.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapResourceQuotaFunc(f func(*v1.ResourceQuota) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		resourceQuota := obj.(*v1.ResourceQuota)
@@ -35,6 +35,14 @@ func TestResourceQuotaStore(t *testing.T) {
 	# TYPE kube_resourcequota gauge
 	# HELP kube_resourcequota_created Unix creation timestamp
 	# TYPE kube_resourcequota_created gauge
+	# HELP kube_resourcequota_metadata_resource_version Resource version representing a specific version of the ResourceQuota.
+	# TYPE kube_resourcequota_metadata_resource_version gauge
+	# HELP kube_resourcequota_scope Scopes the resource quota is restricted to. One series for each scope.
+	# TYPE kube_resourcequota_scope gauge
+	# HELP kube_resourcequota_scope_selector Scope selector requirements the resource quota is restricted to. One series for each requirement.
+	# TYPE kube_resourcequota_scope_selector gauge
+	# HELP kube_resourcequota_owner Information about the ResourceQuota's owner.
+	# TYPE kube_resourcequota_owner gauge
 	`
 	cases := []generateMetricsTestCase{
 		// Verify populating base metric and that metric for unset fields are skipped.
@@ -49,6 +57,7 @@ func TestResourceQuotaStore(t *testing.T) {
 			},
 			Want: metadata + `
 			kube_resourcequota_created{namespace="testNS",resourcequota="quotaTest"} 1.5e+09
+			kube_resourcequota_owner{resourcequota="quotaTest",namespace="testNS",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
 		// Verify resource metric.
@@ -130,6 +139,37 @@ func TestResourceQuotaStore(t *testing.T) {
 			kube_resourcequota{namespace="testNS",resource="services.nodeports",resourcequota="quotaTest",type="used"} 1
 			kube_resourcequota{namespace="testNS",resource="storage",resourcequota="quotaTest",type="hard"} 1e+10
 			kube_resourcequota{namespace="testNS",resource="storage",resourcequota="quotaTest",type="used"} 9e+09
+			kube_resourcequota_owner{resourcequota="quotaTest",namespace="testNS",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
+			`,
+		},
+		// Verify scope and scope selector metrics.
+		{
+			Obj: &v1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "quotaScopeTest",
+					Namespace: "testNS",
+				},
+				Spec: v1.ResourceQuotaSpec{
+					Scopes: []v1.ResourceQuotaScope{
+						v1.ResourceQuotaScopeBestEffort,
+						v1.ResourceQuotaScopeNotTerminating,
+					},
+					ScopeSelector: &v1.ScopeSelector{
+						MatchExpressions: []v1.ScopedResourceSelectorRequirement{
+							{
+								ScopeName: v1.ResourceQuotaScopePriorityClass,
+								Operator:  v1.ScopeSelectorOpIn,
+								Values:    []string{"high", "medium"},
+							},
+						},
+					},
+				},
+			},
+			Want: metadata + `
+			kube_resourcequota_scope{namespace="testNS",resourcequota="quotaScopeTest",scope="BestEffort"} 1
+			kube_resourcequota_scope{namespace="testNS",resourcequota="quotaScopeTest",scope="NotTerminating"} 1
+			kube_resourcequota_scope_selector{namespace="testNS",operator="In",resourcequota="quotaScopeTest",scope="PriorityClass",values="high,medium"} 1
+			kube_resourcequota_owner{resourcequota="quotaScopeTest",namespace="testNS",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
 	}
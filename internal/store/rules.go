@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/kube-state-metrics/pkg/rules"
+)
+
+// metricFamilyPattern matches the names of the metric families of
+// kube-state-metrics in rule expressions.
+var metricFamilyPattern = regexp.MustCompile(`\bkube_[a-z0-9_]+\b`)
+
+// RecommendedRules returns the recommended rules based on the metrics of the
+// given resources, in a group per resource. It fails if a rule uses a metric
+// family its resource does not expose by default, so that the rules always
+// match the exposed metric families.
+func RecommendedRules(resources []string) ([]rules.RuleGroup, error) {
+	groups := []rules.RuleGroup{}
+	for _, r := range resources {
+		if !resourceExists(r) {
+			return nil, errors.Errorf("resource %s does not exist", r)
+		}
+
+		recommended, ok := rules.Recommended[r]
+		if !ok {
+			continue
+		}
+
+		families := map[string]bool{}
+		for _, f := range resourceDefinitions[r].metricFamilies {
+			families[f.Name] = !f.OptIn
+		}
+		for _, rule := range recommended {
+			for _, name := range metricFamilyPattern.FindAllString(rule.Expr, -1) {
+				if !families[name] {
+					return nil, errors.Errorf("rule %s%s uses metric family %s, which is not exposed by resource %s by default", rule.Record, rule.Alert, name, r)
+				}
+			}
+		}
+
+		groups = append(groups, rules.RuleGroup{Name: "kube-state-metrics-" + r, Rules: recommended})
+	}
+	return groups, nil
+}
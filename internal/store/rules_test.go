@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/rules"
+)
+
+func TestRecommendedRules(t *testing.T) {
+	for r := range rules.Recommended {
+		if !resourceExists(r) {
+			t.Errorf("recommended rules of unknown resource %s", r)
+		}
+	}
+
+	// Every rule only uses metric families exposed by default.
+	groups, err := RecommendedRules(availableResources())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != len(rules.Recommended) {
+		t.Fatalf("expected %d rule groups, got %d", len(rules.Recommended), len(groups))
+	}
+	for _, g := range groups {
+		for _, r := range g.Rules {
+			if (r.Record == "") == (r.Alert == "") {
+				t.Errorf("expected either record or alert of rule in group %s, got %+v", g.Name, r)
+			}
+		}
+	}
+
+	if _, err := RecommendedRules([]string{"unknown"}); err == nil {
+		t.Error("expected error for unknown resource")
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package store
 
 import (
+	"crypto/x509"
+	"encoding/pem"
+
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -86,12 +89,74 @@ var (
 			Name: "kube_secret_created",
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(s.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_secret_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of secret.",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(s.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
+		{
+			Name: "kube_secret_data_bytes",
+			Type: metric.Gauge,
+			Help: "Total size in bytes of the keys and values stored in the secret.",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
+				var size int
+				for k, v := range s.Data {
+					size += len(k) + len(v)
+				}
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(size),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name: "kube_secret_data_keys",
+			Type: metric.Gauge,
+			Help: "Number of keys stored in the secret.",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(len(s.Data)),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:  "kube_secret_tls_cert_not_after",
+			Type:  metric.Gauge,
+			Help:  "Unix timestamp after which the certificate in a kubernetes.io/tls secret is no longer valid. One series for each certificate in the chain.",
+			OptIn: true,
 			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
 				ms := []*metric.Metric{}
 
-				if !s.CreationTimestamp.IsZero() {
+				if s.Type != v1.SecretTypeTLS {
+					return &metric.Family{
+						Metrics: ms,
+					}
+				}
+
+				for _, cert := range parseCertificates(s.Data[v1.TLSCertKey]) {
 					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
+						LabelKeys:   []string{"common_name", "serial_number"},
+						LabelValues: []string{cert.Subject.CommonName, cert.SerialNumber.String()},
+						Value:       float64(cert.NotAfter.Unix()),
 					})
 				}
 
@@ -101,18 +166,56 @@ var (
 			}),
 		},
 		{
-			Name: "kube_secret_metadata_resource_version",
+			Name: "kube_secret_owner",
 			Type: metric.Gauge,
-			Help: "Resource version representing a specific version of secret.",
+			Help: "Information about the Secret's owner.",
 			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
 				return &metric.Family{
-					Metrics: resourceVersionMetric(s.ObjectMeta.ResourceVersion),
+					Metrics: ownerMetrics(s.GetOwnerReferences()),
 				}
 			}),
 		},
 	}
 )
 
+// parseCertificates returns all certificates found in the PEM encoded data.
+// Blocks that are not certificates or cannot be parsed are skipped.
+func parseCertificates(data []byte) []*x509.Certificate {
+	certs := []*x509.Certificate{}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		certs = append(certs, cert)
+	}
+}
+
+// secretAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Secret as Prometheus labels.
+func secretAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_secret_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(s.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapSecretFunc(f func(*v1.Secret) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		secret := obj.(*v1.Secret)
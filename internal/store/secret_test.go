@@ -17,7 +17,15 @@ limitations under the License.
 package store
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,6 +36,8 @@ import (
 func TestSecretStore(t *testing.T) {
 	startTime := 1501569018
 	metav1StartTime := metav1.Unix(int64(startTime), 0)
+	tlsCert := append(generateTestCertificatePEM(t, "example.com", 1, time.Unix(1800000000, 0)),
+		generateTestCertificatePEM(t, "Example CA", 2, time.Unix(1900000000, 0))...)
 	cases := []generateMetricsTestCase{
 		{
 			Obj: &v1.Secret{
@@ -114,6 +124,64 @@ func TestSecretStore(t *testing.T) {
 `,
 			MetricNames: []string{"kube_secret_info", "kube_secret_metadata_resource_version", "kube_secret_created", "kube_secret_labels", "kube_secret_type"},
 		},
+		{
+			Obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret4",
+					Namespace: "ns4",
+				},
+				Type: v1.SecretTypeTLS,
+				Data: map[string][]byte{
+					v1.TLSCertKey: append(tlsCert, []byte("-----BEGIN GARBAGE-----\nZm9v\n-----END GARBAGE-----\n")...),
+				},
+			},
+			Want: `
+				# HELP kube_secret_tls_cert_not_after Unix timestamp after which the certificate in a kubernetes.io/tls secret is no longer valid. One series for each certificate in the chain.
+				# TYPE kube_secret_tls_cert_not_after gauge
+				kube_secret_tls_cert_not_after{common_name="example.com",namespace="ns4",secret="secret4",serial_number="1"} 1.8e+09
+				kube_secret_tls_cert_not_after{common_name="Example CA",namespace="ns4",secret="secret4",serial_number="2"} 1.9e+09
+			`,
+			MetricNames: []string{"kube_secret_tls_cert_not_after"},
+		},
+		{
+			Obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret5",
+					Namespace: "ns5",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					v1.TLSCertKey: tlsCert,
+				},
+			},
+			Want: `
+				# HELP kube_secret_tls_cert_not_after Unix timestamp after which the certificate in a kubernetes.io/tls secret is no longer valid. One series for each certificate in the chain.
+				# TYPE kube_secret_tls_cert_not_after gauge
+			`,
+			MetricNames: []string{"kube_secret_tls_cert_not_after"},
+		},
+		{
+			Obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret6",
+					Namespace: "ns6",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("hunter2"),
+				},
+			},
+			Want: `
+				# HELP kube_secret_data_bytes Total size in bytes of the keys and values stored in the secret.
+				# HELP kube_secret_data_keys Number of keys stored in the secret.
+				# TYPE kube_secret_data_bytes gauge
+				# TYPE kube_secret_data_keys gauge
+				kube_secret_data_bytes{namespace="ns6",secret="secret6"} 28
+				kube_secret_data_keys{namespace="ns6",secret="secret6"} 2
+			`,
+			MetricNames: []string{"kube_secret_data_bytes", "kube_secret_data_keys"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(secretMetricFamilies)
@@ -124,3 +192,23 @@ func TestSecretStore(t *testing.T) {
 
 	}
 }
+
+func generateTestCertificatePEM(t *testing.T, commonName string, serial int64, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
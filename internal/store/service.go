@@ -52,15 +52,9 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
-				if !s.CreationTimestamp.IsZero() {
-					m := metric.Metric{
-						LabelKeys:   nil,
-						LabelValues: nil,
-						Value:       float64(s.CreationTimestamp.Unix()),
-					}
-					return &metric.Family{Metrics: []*metric.Metric{&m}}
+				return &metric.Family{
+					Metrics: createdMetric(s.CreationTimestamp),
 				}
-				return &metric.Family{Metrics: []*metric.Metric{}}
 			}),
 		},
 		{
@@ -77,6 +71,72 @@ var (
 				return &metric.Family{Metrics: []*metric.Metric{&m}}
 			}),
 		},
+		{
+			Name:  "kube_service_spec_selector",
+			Type:  metric.Gauge,
+			Help:  "The selector of the service, with the selector keys converted to Prometheus labels.",
+			OptIn: true,
+			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				if len(s.Spec.Selector) == 0 {
+					return &metric.Family{Metrics: []*metric.Metric{}}
+				}
+				labelKeys, labelValues := mapToPrometheusLabels(s.Spec.Selector, "selector")
+				m := metric.Metric{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}
+				return &metric.Family{Metrics: []*metric.Metric{&m}}
+			}),
+		},
+		{
+			Name: "kube_service_spec_external_traffic_policy",
+			Type: metric.Gauge,
+			Help: "External traffic policy of the service.",
+			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				if s.Spec.ExternalTrafficPolicy == "" {
+					return &metric.Family{Metrics: []*metric.Metric{}}
+				}
+				m := metric.Metric{
+					LabelKeys:   []string{"external_traffic_policy"},
+					LabelValues: []string{string(s.Spec.ExternalTrafficPolicy)},
+					Value:       1,
+				}
+				return &metric.Family{Metrics: []*metric.Metric{&m}}
+			}),
+		},
+		{
+			Name: "kube_service_spec_session_affinity",
+			Type: metric.Gauge,
+			Help: "Session affinity of the service.",
+			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				if s.Spec.SessionAffinity == "" {
+					return &metric.Family{Metrics: []*metric.Metric{}}
+				}
+				m := metric.Metric{
+					LabelKeys:   []string{"session_affinity"},
+					LabelValues: []string{string(s.Spec.SessionAffinity)},
+					Value:       1,
+				}
+				return &metric.Family{Metrics: []*metric.Metric{&m}}
+			}),
+		},
+		{
+			Name: "kube_service_spec_ip_family",
+			Type: metric.Gauge,
+			Help: "IP family assigned to the service cluster IP.",
+			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				if s.Spec.IPFamily == nil {
+					return &metric.Family{Metrics: []*metric.Metric{}}
+				}
+				m := metric.Metric{
+					LabelKeys:   []string{"ip_family"},
+					LabelValues: []string{string(*s.Spec.IPFamily)},
+					Value:       1,
+				}
+				return &metric.Family{Metrics: []*metric.Metric{&m}}
+			}),
+		},
 		{
 			Name: descServiceLabelsName,
 			Type: metric.Gauge,
@@ -144,9 +204,44 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_service_owner",
+			Type: metric.Gauge,
+			Help: "Information about the Service's owner.",
+			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(s.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_service_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the Service.",
+			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(s.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// serviceAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a Service as Prometheus labels.
+func serviceAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_service_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(s.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapSvcFunc(f func(*v1.Service) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		svc := obj.(*v1.Service)
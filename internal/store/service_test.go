@@ -27,6 +27,8 @@ import (
 )
 
 func TestServiceStore(t *testing.T) {
+	ipv6 := v1.IPv6Protocol
+
 	// Fixed metadata on type and help text. We prepend this to every expected
 	// output so we only have to modify a single place when doing adjustments.
 	const metadata = `
@@ -34,14 +36,26 @@ func TestServiceStore(t *testing.T) {
 		# TYPE kube_service_info gauge
 		# HELP kube_service_created Unix creation timestamp
 		# TYPE kube_service_created gauge
+		# HELP kube_service_metadata_resource_version Resource version representing a specific version of the Service.
+		# TYPE kube_service_metadata_resource_version gauge
 		# HELP kube_service_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_service_labels gauge
 		# HELP kube_service_spec_type Type about service.
 		# TYPE kube_service_spec_type gauge
+		# HELP kube_service_spec_selector The selector of the service, with the selector keys converted to Prometheus labels.
+		# TYPE kube_service_spec_selector gauge
 		# HELP kube_service_spec_external_ip Service external ips. One series for each ip
 		# TYPE kube_service_spec_external_ip gauge
 		# HELP kube_service_status_load_balancer_ingress Service load balancer ingress status
 		# TYPE kube_service_status_load_balancer_ingress gauge
+		# HELP kube_service_spec_external_traffic_policy External traffic policy of the service.
+		# TYPE kube_service_spec_external_traffic_policy gauge
+		# HELP kube_service_spec_session_affinity Session affinity of the service.
+		# TYPE kube_service_spec_session_affinity gauge
+		# HELP kube_service_spec_ip_family IP family assigned to the service cluster IP.
+		# TYPE kube_service_spec_ip_family gauge
+		# HELP kube_service_owner Information about the Service's owner.
+		# TYPE kube_service_owner gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -101,6 +115,7 @@ func TestServiceStore(t *testing.T) {
 				kube_service_info{cluster_ip="1.2.3.5",external_name="",load_balancer_ip="",namespace="default",service="test-service2"} 1
 				kube_service_labels{label_app="example2",namespace="default",service="test-service2"} 1
 				kube_service_spec_type{namespace="default",service="test-service2",type="NodePort"} 1
+				kube_service_owner{service="test-service2",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 `,
 		},
 		{
@@ -124,6 +139,7 @@ func TestServiceStore(t *testing.T) {
 				kube_service_info{cluster_ip="1.2.3.6",external_name="",load_balancer_ip="1.2.3.7",namespace="default",service="test-service3"} 1
 				kube_service_labels{label_app="example3",namespace="default",service="test-service3"} 1
 				kube_service_spec_type{namespace="default",service="test-service3",type="LoadBalancer"} 1
+				kube_service_owner{service="test-service3",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 `,
 		},
 		{
@@ -146,6 +162,7 @@ func TestServiceStore(t *testing.T) {
 				kube_service_info{cluster_ip="",external_name="www.example.com",load_balancer_ip="",namespace="default",service="test-service4"} 1
 				kube_service_labels{label_app="example4",namespace="default",service="test-service4"} 1
 				kube_service_spec_type{namespace="default",service="test-service4",type="ExternalName"} 1
+				kube_service_owner{service="test-service4",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
 		{
@@ -178,6 +195,7 @@ func TestServiceStore(t *testing.T) {
 				kube_service_labels{label_app="example5",namespace="default",service="test-service5"} 1
 				kube_service_spec_type{namespace="default",service="test-service5",type="LoadBalancer"} 1
 				kube_service_status_load_balancer_ingress{hostname="www.example.com",ip="1.2.3.8",namespace="default",service="test-service5"} 1
+				kube_service_owner{service="test-service5",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
 		{
@@ -205,8 +223,76 @@ func TestServiceStore(t *testing.T) {
 				kube_service_spec_type{namespace="default",service="test-service6",type="ClusterIP"} 1
 				kube_service_spec_external_ip{external_ip="1.2.3.9",namespace="default",service="test-service6"} 1
 				kube_service_spec_external_ip{external_ip="1.2.3.10",namespace="default",service="test-service6"} 1
+				kube_service_owner{service="test-service6",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
+			`,
+		},
+		{
+			Obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-service7",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Namespace:         "default",
+					Labels: map[string]string{
+						"app": "example7",
+					},
+				},
+				Spec: v1.ServiceSpec{
+					ClusterIP:             "1.2.3.11",
+					Type:                  v1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
+					SessionAffinity:       v1.ServiceAffinityClientIP,
+				},
+			},
+			Want: metadata + `
+				kube_service_created{namespace="default",service="test-service7"} 1.5e+09
+				kube_service_info{cluster_ip="1.2.3.11",external_name="",load_balancer_ip="",namespace="default",service="test-service7"} 1
+				kube_service_labels{label_app="example7",namespace="default",service="test-service7"} 1
+				kube_service_spec_type{namespace="default",service="test-service7",type="LoadBalancer"} 1
+				kube_service_spec_external_traffic_policy{external_traffic_policy="Local",namespace="default",service="test-service7"} 1
+				kube_service_spec_session_affinity{namespace="default",service="test-service7",session_affinity="ClientIP"} 1
+				kube_service_owner{service="test-service7",namespace="default",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
 			`,
 		},
+		{
+			Obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-service8",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Namespace:         "default",
+				},
+				Spec: v1.ServiceSpec{
+					ClusterIP: "fd00::1",
+					Type:      v1.ServiceTypeClusterIP,
+					IPFamily:  &ipv6,
+				},
+			},
+			Want: `
+				# HELP kube_service_spec_ip_family IP family assigned to the service cluster IP.
+				# TYPE kube_service_spec_ip_family gauge
+				kube_service_spec_ip_family{ip_family="IPv6",namespace="default",service="test-service8"} 1
+			`,
+			MetricNames: []string{"kube_service_spec_ip_family"},
+		},
+		{
+			Obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-service-selector",
+					Namespace: "default",
+				},
+				Spec: v1.ServiceSpec{
+					Selector: map[string]string{
+						"app.kubernetes.io/name": "example",
+						"tier":                   "backend",
+					},
+				},
+			},
+			Want: `
+				# HELP kube_service_spec_selector The selector of the service, with the selector keys converted to Prometheus labels.
+				# TYPE kube_service_spec_selector gauge
+				kube_service_spec_selector{namespace="default",selector_app_kubernetes_io_name="example",selector_tier="backend",service="test-service-selector"} 1
+`,
+			MetricNames: []string{"kube_service_spec_selector"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = generator.ComposeMetricGenFuncs(serviceMetricFamilies)
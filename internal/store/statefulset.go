@@ -32,23 +32,21 @@ var (
 	descStatefulSetLabelsName          = "kube_statefulset_labels"
 	descStatefulSetLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descStatefulSetLabelsDefaultLabels = []string{"namespace", "statefulset"}
+)
 
-	statefulSetMetricFamilies = []generator.FamilyGenerator{
+// statefulSetMetricFamilies returns the metric families of StatefulSets. The
+// given tracker remembers since when StatefulSets have had fewer ready
+// replicas than desired, and is owned by the store the families are generated
+// for.
+func statefulSetMetricFamilies(drift *replicaDriftTracker) []generator.FamilyGenerator {
+	return []generator.FamilyGenerator{
 		{
 			Name: "kube_statefulset_created",
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapStatefulSetFunc(func(s *v1.StatefulSet) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(s.CreationTimestamp),
 				}
 			}),
 		},
@@ -94,6 +92,25 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_statefulset_status_replicas_unready_since",
+			Type: metric.Gauge,
+			Help: "Unix timestamp since when the StatefulSet has had fewer ready replicas than desired.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1.StatefulSet) *metric.Family {
+				ms := []*metric.Metric{}
+
+				drifting := s.Spec.Replicas != nil && s.Status.ReadyReplicas < *s.Spec.Replicas
+				if since, ok := drift.since(s.UID, drifting); ok {
+					ms = append(ms, &metric.Metric{
+						Value: float64(since.Unix()),
+					})
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
 		{
 			Name: "kube_statefulset_status_replicas_updated",
 			Type: metric.Gauge,
@@ -154,6 +171,20 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_statefulset_spec_generation_mismatch",
+			Type: metric.Gauge,
+			Help: "Whether the generation observed by the StatefulSet controller differs from the desired generation.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1.StatefulSet) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: boolFloat64(s.ObjectMeta.Generation != s.Status.ObservedGeneration),
+						},
+					},
+				}
+			}),
+		},
 		{
 			Name: descStatefulSetLabelsName,
 			Type: metric.Gauge,
@@ -203,8 +234,43 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_statefulset_owner",
+			Type: metric.Gauge,
+			Help: "Information about the StatefulSet's owner.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1.StatefulSet) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(s.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_statefulset_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the StatefulSet.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1.StatefulSet) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(s.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
-)
+}
+
+// statefulSetAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a StatefulSet as Prometheus labels.
+func statefulSetAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_statefulset_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapStatefulSetFunc(func(s *v1.StatefulSet) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(s.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
 
 func wrapStatefulSetFunc(f func(*v1.StatefulSet) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
@@ -69,6 +69,7 @@ func TestStatefulSetStore(t *testing.T) {
 				# HELP kube_statefulset_status_replicas The number of replicas per StatefulSet.
 				# HELP kube_statefulset_status_replicas_current The number of current replicas per StatefulSet.
 				# HELP kube_statefulset_status_replicas_ready The number of ready replicas per StatefulSet.
+				# HELP kube_statefulset_status_replicas_unready_since Unix timestamp since when the StatefulSet has had fewer ready replicas than desired.
 				# HELP kube_statefulset_status_replicas_updated The number of updated replicas per StatefulSet.
 				# HELP kube_statefulset_status_update_revision Indicates the version of the StatefulSet used to generate Pods in the sequence [replicas-updatedReplicas,replicas)
 				# TYPE kube_statefulset_created gauge
@@ -80,6 +81,7 @@ func TestStatefulSetStore(t *testing.T) {
 				# TYPE kube_statefulset_status_replicas gauge
 				# TYPE kube_statefulset_status_replicas_current gauge
 				# TYPE kube_statefulset_status_replicas_ready gauge
+				# TYPE kube_statefulset_status_replicas_unready_since gauge
 				# TYPE kube_statefulset_status_replicas_updated gauge
 				# TYPE kube_statefulset_status_update_revision gauge
 				kube_statefulset_status_update_revision{namespace="ns1",revision="ur1",statefulset="statefulset1"} 1
@@ -88,6 +90,7 @@ func TestStatefulSetStore(t *testing.T) {
  				kube_statefulset_status_replicas{namespace="ns1",statefulset="statefulset1"} 2
 				kube_statefulset_status_replicas_current{namespace="ns1",statefulset="statefulset1"} 0
 				kube_statefulset_status_replicas_ready{namespace="ns1",statefulset="statefulset1"} 0
+				kube_statefulset_status_replicas_unready_since{namespace="ns1",statefulset="statefulset1"} 1.6e+09
 				kube_statefulset_status_replicas_updated{namespace="ns1",statefulset="statefulset1"} 0
  				kube_statefulset_status_observed_generation{namespace="ns1",statefulset="statefulset1"} 1
  				kube_statefulset_replicas{namespace="ns1",statefulset="statefulset1"} 3
@@ -103,6 +106,7 @@ func TestStatefulSetStore(t *testing.T) {
 				"kube_statefulset_status_replicas",
 				"kube_statefulset_status_replicas_current",
 				"kube_statefulset_status_replicas_ready",
+				"kube_statefulset_status_replicas_unready_since",
 				"kube_statefulset_status_replicas_updated",
 				"kube_statefulset_status_update_revision",
 				"kube_statefulset_status_current_revision",
@@ -141,6 +145,7 @@ func TestStatefulSetStore(t *testing.T) {
 				# HELP kube_statefulset_status_replicas The number of replicas per StatefulSet.
 				# HELP kube_statefulset_status_replicas_current The number of current replicas per StatefulSet.
 				# HELP kube_statefulset_status_replicas_ready The number of ready replicas per StatefulSet.
+				# HELP kube_statefulset_status_replicas_unready_since Unix timestamp since when the StatefulSet has had fewer ready replicas than desired.
 				# HELP kube_statefulset_status_replicas_updated The number of updated replicas per StatefulSet.
 				# HELP kube_statefulset_status_update_revision Indicates the version of the StatefulSet used to generate Pods in the sequence [replicas-updatedReplicas,replicas)
 				# TYPE kube_statefulset_labels gauge
@@ -151,12 +156,14 @@ func TestStatefulSetStore(t *testing.T) {
 				# TYPE kube_statefulset_status_replicas gauge
 				# TYPE kube_statefulset_status_replicas_current gauge
 				# TYPE kube_statefulset_status_replicas_ready gauge
+				# TYPE kube_statefulset_status_replicas_unready_since gauge
 				# TYPE kube_statefulset_status_replicas_updated gauge
 				# TYPE kube_statefulset_status_update_revision gauge
 				kube_statefulset_status_update_revision{namespace="ns2",revision="ur2",statefulset="statefulset2"} 1
  				kube_statefulset_status_replicas{namespace="ns2",statefulset="statefulset2"} 5
 				kube_statefulset_status_replicas_current{namespace="ns2",statefulset="statefulset2"} 2
 				kube_statefulset_status_replicas_ready{namespace="ns2",statefulset="statefulset2"} 5
+				kube_statefulset_status_replicas_unready_since{namespace="ns2",statefulset="statefulset2"} 1.6e+09
 				kube_statefulset_status_replicas_updated{namespace="ns2",statefulset="statefulset2"} 3
  				kube_statefulset_status_observed_generation{namespace="ns2",statefulset="statefulset2"} 2
  				kube_statefulset_replicas{namespace="ns2",statefulset="statefulset2"} 6
@@ -172,6 +179,7 @@ func TestStatefulSetStore(t *testing.T) {
 				"kube_statefulset_status_replicas",
 				"kube_statefulset_status_replicas_current",
 				"kube_statefulset_status_replicas_ready",
+				"kube_statefulset_status_replicas_unready_since",
 				"kube_statefulset_status_replicas_updated",
 				"kube_statefulset_status_update_revision",
 				"kube_statefulset_status_current_revision",
@@ -206,6 +214,7 @@ func TestStatefulSetStore(t *testing.T) {
 				# HELP kube_statefulset_status_replicas The number of replicas per StatefulSet.
 				# HELP kube_statefulset_status_replicas_current The number of current replicas per StatefulSet.
 				# HELP kube_statefulset_status_replicas_ready The number of ready replicas per StatefulSet.
+				# HELP kube_statefulset_status_replicas_unready_since Unix timestamp since when the StatefulSet has had fewer ready replicas than desired.
 				# HELP kube_statefulset_status_replicas_updated The number of updated replicas per StatefulSet.
 				# HELP kube_statefulset_status_update_revision Indicates the version of the StatefulSet used to generate Pods in the sequence [replicas-updatedReplicas,replicas)
 				# TYPE kube_statefulset_labels gauge
@@ -215,12 +224,14 @@ func TestStatefulSetStore(t *testing.T) {
 				# TYPE kube_statefulset_status_replicas gauge
 				# TYPE kube_statefulset_status_replicas_current gauge
 				# TYPE kube_statefulset_status_replicas_ready gauge
+				# TYPE kube_statefulset_status_replicas_unready_since gauge
 				# TYPE kube_statefulset_status_replicas_updated gauge
 				# TYPE kube_statefulset_status_update_revision gauge
 				kube_statefulset_status_update_revision{namespace="ns3",revision="ur3",statefulset="statefulset3"} 1
  				kube_statefulset_status_replicas{namespace="ns3",statefulset="statefulset3"} 7
 				kube_statefulset_status_replicas_current{namespace="ns3",statefulset="statefulset3"} 0
 				kube_statefulset_status_replicas_ready{namespace="ns3",statefulset="statefulset3"} 0
+				kube_statefulset_status_replicas_unready_since{namespace="ns3",statefulset="statefulset3"} 1.6e+09
 				kube_statefulset_status_replicas_updated{namespace="ns3",statefulset="statefulset3"} 0
  				kube_statefulset_replicas{namespace="ns3",statefulset="statefulset3"} 9
  				kube_statefulset_metadata_generation{namespace="ns3",statefulset="statefulset3"} 36
@@ -234,15 +245,39 @@ func TestStatefulSetStore(t *testing.T) {
 				"kube_statefulset_status_replicas",
 				"kube_statefulset_status_replicas_current",
 				"kube_statefulset_status_replicas_ready",
+				"kube_statefulset_status_replicas_unready_since",
 				"kube_statefulset_status_replicas_updated",
 				"kube_statefulset_status_update_revision",
 				"kube_statefulset_status_current_revision",
 			},
 		},
+		{
+			Obj: &v1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "statefulset4",
+					Namespace:  "ns4",
+					Generation: 5,
+				},
+				Status: v1.StatefulSetStatus{
+					ObservedGeneration: 5,
+				},
+			},
+			Want: `
+				# HELP kube_statefulset_spec_generation_mismatch Whether the generation observed by the StatefulSet controller differs from the desired generation.
+				# TYPE kube_statefulset_spec_generation_mismatch gauge
+				kube_statefulset_spec_generation_mismatch{namespace="ns4",statefulset="statefulset4"} 0
+`,
+			MetricNames: []string{
+				"kube_statefulset_spec_generation_mismatch",
+			},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generator.ComposeMetricGenFuncs(statefulSetMetricFamilies)
-		c.Headers = generator.ExtractMetricFamilyHeaders(statefulSetMetricFamilies)
+		drift := newReplicaDriftTracker()
+		drift.now = func() time.Time { return time.Unix(1600000000, 0) }
+		families := statefulSetMetricFamilies(drift)
+		c.Func = generator.ComposeMetricGenFuncs(families)
+		c.Headers = generator.ExtractMetricFamilyHeaders(families)
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -62,14 +62,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapStorageClassFunc(func(s *storagev1.StorageClass) *metric.Family {
-				ms := []*metric.Metric{}
-				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
-				}
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(s.CreationTimestamp),
 				}
 			}),
 		},
@@ -90,9 +84,34 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_storageclass_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the StorageClass.",
+			GenerateFunc: wrapStorageClassFunc(func(s *storagev1.StorageClass) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(s.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// storageClassAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a StorageClass as Prometheus labels.
+func storageClassAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_storageclass_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapStorageClassFunc(func(s *storagev1.StorageClass) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(s.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapStorageClassFunc(f func(*storagev1.StorageClass) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		storageClass := obj.(*storagev1.StorageClass)
@@ -18,16 +18,24 @@ package store
 
 import (
 	"fmt"
+	"hash/fnv"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/util/validation"
 
+	v1batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 )
 
 var (
@@ -35,6 +43,20 @@ var (
 	conditionStatuses  = []v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown}
 )
 
+// createdMetric generates the metric for the creation timestamp of an object.
+// No metric is generated if the timestamp is not set.
+func createdMetric(created metav1.Time) []*metric.Metric {
+	if created.IsZero() {
+		return []*metric.Metric{}
+	}
+
+	return []*metric.Metric{
+		{
+			Value: float64(created.Unix()),
+		},
+	}
+}
+
 func resourceVersionMetric(rv string) []*metric.Metric {
 	v, err := strconv.ParseFloat(rv, 64)
 	if err != nil {
@@ -49,6 +71,106 @@ func resourceVersionMetric(rv string) []*metric.Metric {
 
 }
 
+// ownerMetrics generates one metric for each owner reference, labelled with
+// the owner's kind, name and whether it is the managing controller. Objects
+// without owners get a single metric with "<none>" label values.
+func ownerMetrics(owners []metav1.OwnerReference) []*metric.Metric {
+	labelKeys := []string{"owner_kind", "owner_name", "owner_is_controller"}
+
+	if len(owners) == 0 {
+		return []*metric.Metric{
+			{
+				LabelKeys:   labelKeys,
+				LabelValues: []string{"<none>", "<none>", "<none>"},
+				Value:       1,
+			},
+		}
+	}
+
+	ms := make([]*metric.Metric, len(owners))
+
+	for i, owner := range owners {
+		ownerIsController := "false"
+		if owner.Controller != nil {
+			ownerIsController = strconv.FormatBool(*owner.Controller)
+		}
+
+		ms[i] = &metric.Metric{
+			LabelKeys:   labelKeys,
+			LabelValues: []string{owner.Kind, owner.Name, ownerIsController},
+			Value:       1,
+		}
+	}
+
+	return ms
+}
+
+// annotationsMetric generates a metric exposing the allowed annotations as
+// Prometheus labels. A single "*" allows all annotations of the object.
+func annotationsMetric(annotations map[string]string, allowedAnnotations []string) []*metric.Metric {
+	allowed := annotations
+	if len(allowedAnnotations) != 1 || allowedAnnotations[0] != "*" {
+		allowed = make(map[string]string, len(allowedAnnotations))
+		for _, key := range allowedAnnotations {
+			if value, ok := annotations[key]; ok {
+				allowed[key] = value
+			}
+		}
+	}
+
+	labelKeys, labelValues := mapToPrometheusLabels(allowed, "annotation")
+
+	return []*metric.Metric{
+		{
+			LabelKeys:   labelKeys,
+			LabelValues: labelValues,
+			Value:       1,
+		},
+	}
+}
+
+// resourceUnit returns the unit of measure of the given resource. Resources
+// which are neither known to be measured in cores nor in bytes, e.g. extended
+// resources, are measured in integers.
+func resourceUnit(resourceName v1.ResourceName) constant.ResourceUnit {
+	switch resourceName {
+	case v1.ResourceCPU:
+		return constant.UnitCore
+	case v1.ResourceMemory, v1.ResourceStorage, v1.ResourceEphemeralStorage:
+		return constant.UnitByte
+	}
+
+	if isHugePageResourceName(resourceName) || isAttachableVolumeResourceName(resourceName) {
+		return constant.UnitByte
+	}
+
+	return constant.UnitInteger
+}
+
+// resourceListMetrics generates one metric per resource of the given list,
+// labelled with the resource name and its unit. Every resource is exposed, so
+// that new resource types show up without changes to kube-state-metrics.
+func resourceListMetrics(rl v1.ResourceList) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(rl))
+
+	for resourceName, val := range rl {
+		unit := resourceUnit(resourceName)
+
+		value := float64(val.Value())
+		if unit == constant.UnitCore {
+			value = float64(val.MilliValue()) / 1000
+		}
+
+		ms = append(ms, &metric.Metric{
+			LabelKeys:   []string{"resource", "unit"},
+			LabelValues: []string{sanitizeLabelName(string(resourceName)), string(unit)},
+			Value:       value,
+		})
+	}
+
+	return ms
+}
+
 func boolFloat64(b bool) float64 {
 	if b {
 		return 1
@@ -72,6 +194,462 @@ func addConditionMetrics(cs v1.ConditionStatus) []*metric.Metric {
 	return ms
 }
 
+// conditionTransitionTimeFunc returns the last transition time of the
+// condition the given series of a condition metric family was generated for
+// from the given object.
+type conditionTransitionTimeFunc func(obj interface{}, m *metric.Metric) metav1.Time
+
+// conditionMetricFamily is a metric family which exposes a series for each
+// possible status of a condition by means of addConditionMetrics, along with
+// the function returning the last transition times of its conditions.
+type conditionMetricFamily struct {
+	name           string
+	transitionTime conditionTransitionTimeFunc
+}
+
+// conditionMetricFamilies lists the condition metric families. The names are
+// declared like the names of family generators, one per line, so that they
+// are picked up as metric names by the documentation check.
+var conditionMetricFamilies = []conditionMetricFamily{
+	{
+		name:           "kube_daemonset_status_condition",
+		transitionTime: daemonSetConditionTransitionTime,
+	},
+	{
+		name:           "kube_deployment_status_condition",
+		transitionTime: deploymentConditionTransitionTime,
+	},
+	{
+		name:           "kube_horizontalpodautoscaler_status_condition",
+		transitionTime: hpaConditionTransitionTime,
+	},
+	{
+		name:           "kube_job_complete",
+		transitionTime: jobConditionTransitionTime(v1batch.JobComplete),
+	},
+	{
+		name:           "kube_job_failed",
+		transitionTime: jobConditionTransitionTime(v1batch.JobFailed),
+	},
+	{
+		name:           "kube_namespace_status_condition",
+		transitionTime: namespaceConditionTransitionTime,
+	},
+	{
+		name:           "kube_node_status_condition",
+		transitionTime: nodeConditionTransitionTime,
+	},
+	{
+		name:           "kube_persistentvolumeclaim_status_condition",
+		transitionTime: persistentVolumeClaimConditionTransitionTime,
+	},
+	{
+		name:           "kube_pod_status_ready",
+		transitionTime: podConditionTransitionTime(v1.PodReady),
+	},
+	{
+		name:           "kube_pod_status_scheduled",
+		transitionTime: podConditionTransitionTime(v1.PodScheduled),
+	},
+}
+
+// conditionTransitionTime returns the function returning the last transition
+// times of the conditions of the condition metric family with the given name.
+// It returns false if the metric family is not a condition metric family.
+func conditionTransitionTime(name string) (conditionTransitionTimeFunc, bool) {
+	for _, f := range conditionMetricFamilies {
+		if f.name == name {
+			return f.transitionTime, true
+		}
+	}
+	return nil, false
+}
+
+// conditionLabelValue returns the value of the condition label of the given
+// series of a condition metric family.
+func conditionLabelValue(m *metric.Metric) string {
+	for i, k := range m.LabelKeys {
+		if k == "condition" {
+			return m.LabelValues[i]
+		}
+	}
+	return ""
+}
+
+// familyMapper modifies a metric family generated from the given object.
+type familyMapper func(obj interface{}, family *metric.Family)
+
+// mapMetricFamilies wraps the given metric families, so that the families they
+// generate are modified by the mappers the given functions return for them.
+// The functions return nil for metric families they do not apply to, which
+// are not wrapped by them.
+func mapMetricFamilies(families []generator.FamilyGenerator, mappersFor ...func(generator.FamilyGenerator) familyMapper) []generator.FamilyGenerator {
+	wrapped := make([]generator.FamilyGenerator, len(families))
+
+	for i, f := range families {
+		wrapped[i] = f
+
+		mappers := []familyMapper{}
+		for _, mapperFor := range mappersFor {
+			if m := mapperFor(f); m != nil {
+				mappers = append(mappers, m)
+			}
+		}
+		if len(mappers) == 0 {
+			continue
+		}
+
+		generateFunc := f.GenerateFunc
+		wrapped[i].GenerateFunc = func(obj interface{}) *metric.Family {
+			family := generateFunc(obj)
+			for _, m := range mappers {
+				m(obj, family)
+			}
+			return family
+		}
+	}
+
+	return wrapped
+}
+
+// currentConditionStatusOnly returns the mapper of the condition metric
+// families, which only keeps the series of the current status of a condition
+// instead of one series for each possible status.
+func currentConditionStatusOnly(f generator.FamilyGenerator) familyMapper {
+	if _, ok := conditionTransitionTime(f.Name); !ok {
+		return nil
+	}
+
+	return func(_ interface{}, family *metric.Family) {
+		ms := family.Metrics[:0]
+		for _, m := range family.Metrics {
+			if m.Value != 0 {
+				ms = append(ms, m)
+			}
+		}
+		family.Metrics = ms
+	}
+}
+
+// labelLimits bounds the series of the metric families exposing the labels
+// and annotations of objects, so that objects with many or long labels or
+// annotations do not blow up the number and size of series.
+type labelLimits struct {
+	// maxCount is the maximum number of labels or annotations of an object
+	// that are exposed. Zero disables the limit.
+	maxCount int
+	// maxValueLength is the maximum length of an exposed label or annotation
+	// value. Zero disables the limit.
+	maxValueLength int
+	// hashValues replaces values exceeding maxValueLength by their hash
+	// instead of truncating them.
+	hashValues bool
+}
+
+// labelMetricFamilyPrefixes maps the name suffixes of the metric families
+// exposing the labels and annotations of objects to the prefix of the label
+// names they expose them with.
+var labelMetricFamilyPrefixes = map[string]string{
+	"_labels":      "label_",
+	"_annotations": "annotation_",
+}
+
+// labelMetricFamilyPrefix returns the prefix of the label names the metric
+// family with the given name exposes the labels or annotations of objects
+// with, or an empty string if it exposes neither.
+func labelMetricFamilyPrefix(name string) string {
+	for suffix, p := range labelMetricFamilyPrefixes {
+		if strings.HasSuffix(name, suffix) {
+			return p
+		}
+	}
+	return ""
+}
+
+// limitLabels returns the mappers of the label and annotation metric
+// families, which make them respect the given limits. Labels beyond the
+// maximum count are dropped in alphabetical order of their names.
+func limitLabels(limits labelLimits) func(generator.FamilyGenerator) familyMapper {
+	return func(f generator.FamilyGenerator) familyMapper {
+		prefix := labelMetricFamilyPrefix(f.Name)
+		if prefix == "" {
+			return nil
+		}
+
+		return func(_ interface{}, family *metric.Family) {
+			for _, m := range family.Metrics {
+				keys := make([]string, 0, len(m.LabelKeys))
+				values := make([]string, 0, len(m.LabelValues))
+				count := 0
+
+				for j, k := range m.LabelKeys {
+					v := m.LabelValues[j]
+					if strings.HasPrefix(k, prefix) {
+						count++
+						if limits.maxCount > 0 && count > limits.maxCount {
+							continue
+						}
+						v = limitLabelValue(v, limits)
+					}
+					keys, values = append(keys, k), append(values, v)
+				}
+
+				m.LabelKeys, m.LabelValues = keys, values
+			}
+		}
+	}
+}
+
+// limitLabelValue truncates or hashes the given value if it exceeds the
+// maximum value length of the given limits.
+func limitLabelValue(v string, limits labelLimits) string {
+	max := limits.maxValueLength
+	if max == 0 || len(v) <= max {
+		return v
+	}
+
+	if limits.hashValues {
+		h := fnv.New64a()
+		h.Write([]byte(v))
+		return fmt.Sprintf("%016x", h.Sum64())
+	}
+
+	// Do not cut a multi-byte character in half.
+	for max > 0 && !utf8.RuneStart(v[max]) {
+		max--
+	}
+	return v[:max]
+}
+
+const (
+	// labelNameSchemeUnderscore replaces the characters of label and
+	// annotation keys which are invalid in label names by underscores, e.g.
+	// app.kubernetes.io/name becomes label_app_kubernetes_io_name.
+	labelNameSchemeUnderscore = "underscore"
+	// labelNameSchemeStripPrefix additionally strips the prefix of keys, e.g.
+	// app.kubernetes.io/name becomes label_name.
+	labelNameSchemeStripPrefix = "strip-prefix"
+	// labelNameSchemeGeneric exposes one series per label or annotation,
+	// with its unmodified key and value as the key and value labels.
+	labelNameSchemeGeneric = "generic"
+)
+
+// labelNameSchemes lists the supported label name schemes.
+var labelNameSchemes = []string{labelNameSchemeUnderscore, labelNameSchemeStripPrefix, labelNameSchemeGeneric}
+
+// labelNameScheme returns the mappers of the label and annotation metric
+// families, which name the labels and annotations of objects according to
+// the given scheme.
+func labelNameScheme(scheme string) func(generator.FamilyGenerator) familyMapper {
+	return func(f generator.FamilyGenerator) familyMapper {
+		prefix := labelMetricFamilyPrefix(f.Name)
+		if prefix == "" {
+			return nil
+		}
+
+		return func(obj interface{}, family *metric.Family) {
+			o, err := meta.Accessor(obj)
+			if err != nil {
+				return
+			}
+
+			source := o.GetLabels()
+			if prefix == labelMetricFamilyPrefixes["_annotations"] {
+				source = o.GetAnnotations()
+			}
+
+			// Map the label names back to the keys they were generated from.
+			// Distinct keys like a.b and a_b are sanitized to the same label
+			// name, so each name maps to all of its keys in sorted order.
+			sorted := make([]string, 0, len(source))
+			for k := range source {
+				sorted = append(sorted, k)
+			}
+			sort.Strings(sorted)
+
+			keys := make(map[string][]string, len(source))
+			for _, k := range sorted {
+				name := prefix + sanitizeLabelName(k)
+				keys[name] = append(keys[name], k)
+			}
+
+			ms := []*metric.Metric{}
+			for _, m := range family.Metrics {
+				ms = append(ms, renameLabels(m, prefix, source, keys, scheme)...)
+			}
+			family.Metrics = ms
+		}
+	}
+}
+
+// renameLabels renames the labels of the given metric generated from the
+// keys of labels or annotations according to the given scheme.
+func renameLabels(m *metric.Metric, prefix string, source map[string]string, keys map[string][]string, scheme string) []*metric.Metric {
+	var (
+		commonKeys, commonValues []string
+		keyValues                [][2]string
+	)
+
+	used := map[string]struct{}{}
+	for i, k := range m.LabelKeys {
+		if key, ok := sourceKey(k, m.LabelValues[i], source, keys, used); ok && strings.HasPrefix(k, prefix) {
+			keyValues = append(keyValues, [2]string{key, m.LabelValues[i]})
+			continue
+		}
+		commonKeys = append(commonKeys, k)
+		commonValues = append(commonValues, m.LabelValues[i])
+	}
+
+	if scheme == labelNameSchemeGeneric {
+		ms := make([]*metric.Metric, len(keyValues))
+		for i, kv := range keyValues {
+			ms[i] = &metric.Metric{
+				LabelKeys:   append(append([]string{}, commonKeys...), "key", "value"),
+				LabelValues: append(append([]string{}, commonValues...), kv[0], kv[1]),
+				Value:       m.Value,
+			}
+		}
+		return ms
+	}
+
+	// Keys with the same name after stripping their prefixes are only
+	// exposed once, keeping the first key in alphabetical order.
+	seen := make(map[string]struct{}, len(keyValues))
+	for _, kv := range keyValues {
+		name := kv[0]
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		name = prefix + sanitizeLabelName(name)
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		commonKeys = append(commonKeys, name)
+		commonValues = append(commonValues, kv[1])
+	}
+
+	return []*metric.Metric{{
+		LabelKeys:   commonKeys,
+		LabelValues: commonValues,
+		Value:       m.Value,
+	}}
+}
+
+// sourceKey returns the key of labels or annotations the given label name and
+// value were generated from, among the keys which were not used yet. Of keys
+// sanitized to the same label name, the first one in sorted order with the
+// given value is preferred, as values may have been limited in length.
+func sourceKey(name, value string, source map[string]string, keys map[string][]string, used map[string]struct{}) (string, bool) {
+	unused := ""
+	for _, key := range keys[name] {
+		if _, ok := used[key]; ok {
+			continue
+		}
+		if source[key] == value {
+			unused = key
+			break
+		}
+		if unused == "" {
+			unused = key
+		}
+	}
+	if unused == "" {
+		return "", false
+	}
+
+	used[unused] = struct{}{}
+	return unused, true
+}
+
+// uidLabel returns the mapper of the info and created metric families, which
+// makes their series carry the UID of their object as uid label. Series which
+// already have a uid label are left as they are.
+func uidLabel(f generator.FamilyGenerator) familyMapper {
+	if !strings.HasSuffix(f.Name, "_info") && !strings.HasSuffix(f.Name, "_created") {
+		return nil
+	}
+
+	return func(obj interface{}, family *metric.Family) {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		uid := string(o.GetUID())
+
+	metrics:
+		for _, m := range family.Metrics {
+			for _, k := range m.LabelKeys {
+				if k == "uid" {
+					continue metrics
+				}
+			}
+			m.LabelKeys = append(m.LabelKeys[:len(m.LabelKeys):len(m.LabelKeys)], "uid")
+			m.LabelValues = append(m.LabelValues[:len(m.LabelValues):len(m.LabelValues)], uid)
+		}
+	}
+}
+
+// customLabels returns the mappers of all metric families, which make their
+// series carry the given static labels. Series which already have a label
+// with the same name keep their own value.
+func customLabels(labels map[string]string) func(generator.FamilyGenerator) familyMapper {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func(generator.FamilyGenerator) familyMapper {
+		return func(_ interface{}, family *metric.Family) {
+			for _, m := range family.Metrics {
+				keys := make([]string, len(m.LabelKeys), len(m.LabelKeys)+len(names))
+				values := make([]string, len(m.LabelValues), len(m.LabelValues)+len(names))
+				copy(keys, m.LabelKeys)
+				copy(values, m.LabelValues)
+
+			names:
+				for _, name := range names {
+					for _, k := range m.LabelKeys {
+						if k == name {
+							continue names
+						}
+					}
+					keys = append(keys, name)
+					values = append(values, labels[name])
+				}
+
+				m.LabelKeys, m.LabelValues = keys, values
+			}
+		}
+	}
+}
+
+// conditionSampleTimestamps returns the mapper of the condition metric
+// families, which sets the last transition time of the condition as sample
+// timestamp of the series of its current status. The series of the other
+// statuses are exposed without timestamps, as the condition did not
+// transition to them at that time.
+func conditionSampleTimestamps(f generator.FamilyGenerator) familyMapper {
+	transitionTime, ok := conditionTransitionTime(f.Name)
+	if !ok {
+		return nil
+	}
+
+	return func(obj interface{}, family *metric.Family) {
+		for _, m := range family.Metrics {
+			if m.Value == 0 {
+				continue
+			}
+			if t := transitionTime(obj, m); !t.IsZero() {
+				m.Timestamp = t.UnixNano() / int64(time.Millisecond)
+			}
+		}
+	}
+}
+
 func kubeLabelsToPrometheusLabels(labels map[string]string) ([]string, []string) {
 	return mapToPrometheusLabels(labels, "label")
 }
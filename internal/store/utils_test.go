@@ -18,9 +18,13 @@ package store
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
 )
 
 func TestIsHugePageSizeFromResourceName(t *testing.T) {
@@ -198,3 +202,420 @@ func TestKubeLabelsToPrometheusLabels(t *testing.T) {
 	}
 
 }
+
+func TestAnnotationsMetric(t *testing.T) {
+	annotations := map[string]string{
+		"example.com/team":  "platform",
+		"example.com/owner": "jane",
+		"checksum/config":   "abc",
+	}
+
+	testCases := []struct {
+		allowedAnnotations []string
+		expectKeys         []string
+		expectValues       []string
+	}{
+		{
+			allowedAnnotations: []string{"example.com/team"},
+			expectKeys:         []string{"annotation_example_com_team"},
+			expectValues:       []string{"platform"},
+		},
+		{
+			allowedAnnotations: []string{"example.com/team", "missing"},
+			expectKeys:         []string{"annotation_example_com_team"},
+			expectValues:       []string{"platform"},
+		},
+		{
+			allowedAnnotations: []string{"*"},
+			expectKeys:         []string{"annotation_checksum_config", "annotation_example_com_owner", "annotation_example_com_team"},
+			expectValues:       []string{"abc", "jane", "platform"},
+		},
+		{
+			allowedAnnotations: []string{"missing"},
+			expectKeys:         []string{},
+			expectValues:       []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("allowed annotations=%v", tc.allowedAnnotations), func(t *testing.T) {
+			ms := annotationsMetric(annotations, tc.allowedAnnotations)
+			if len(ms) != 1 {
+				t.Fatalf("Got %d metrics but expected 1", len(ms))
+			}
+
+			if !reflect.DeepEqual(ms[0].LabelKeys, tc.expectKeys) || !reflect.DeepEqual(ms[0].LabelValues, tc.expectValues) {
+				t.Errorf("Got labels %v=%v but expected %v=%v", ms[0].LabelKeys, ms[0].LabelValues, tc.expectKeys, tc.expectValues)
+			}
+		})
+	}
+}
+
+func TestCurrentConditionStatusOnly(t *testing.T) {
+	families := mapMetricFamilies(nodeMetricFamilies(newUnschedulableTracker()), currentConditionStatusOnly)
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "127.0.0.1",
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue},
+				{Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse},
+				{Type: v1.NodeDiskPressure, Status: v1.ConditionUnknown},
+			},
+		},
+	}
+
+	// Zero valued series of other families are kept.
+	c := generateMetricsTestCase{
+		Obj: node,
+		Want: `
+			# HELP kube_node_spec_unschedulable Whether a node can schedule new pods.
+			# HELP kube_node_status_condition The condition of a cluster node.
+			# TYPE kube_node_spec_unschedulable gauge
+			# TYPE kube_node_status_condition gauge
+			kube_node_spec_unschedulable{node="127.0.0.1"} 0
+			kube_node_status_condition{condition="DiskPressure",node="127.0.0.1",status="unknown"} 1
+			kube_node_status_condition{condition="MemoryPressure",node="127.0.0.1",status="false"} 1
+			kube_node_status_condition{condition="Ready",node="127.0.0.1",status="true"} 1
+		`,
+		MetricNames: []string{"kube_node_spec_unschedulable[ {]", "kube_node_status_condition"},
+		Func:        generator.ComposeMetricGenFuncs(families),
+		Headers:     generator.ExtractMetricFamilyHeaders(families),
+	}
+	if err := c.run(); err != nil {
+		t.Fatalf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestLimitLabelMetricFamilies(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+			Labels: map[string]string{
+				"app":     "web",
+				"release": "a-very-long-release-name",
+				"tier":    "frontend",
+				"unicode": "äöü",
+			},
+			Annotations: map[string]string{
+				"checksum": "0123456789abcdef",
+			},
+		},
+	}
+
+	tests := []struct {
+		limits labelLimits
+		want   string
+	}{
+		{
+			limits: labelLimits{maxCount: 2},
+			want: `
+				kube_pod_annotations{annotation_checksum="0123456789abcdef",namespace="ns1",pod="pod1"} 1
+				kube_pod_labels{label_app="web",label_release="a-very-long-release-name",namespace="ns1",pod="pod1"} 1
+			`,
+		},
+		{
+			limits: labelLimits{maxValueLength: 5},
+			want: `
+				kube_pod_annotations{annotation_checksum="01234",namespace="ns1",pod="pod1"} 1
+				kube_pod_labels{label_app="web",label_release="a-ver",label_tier="front",label_unicode="äö",namespace="ns1",pod="pod1"} 1
+			`,
+		},
+		{
+			limits: labelLimits{maxCount: 1, maxValueLength: 10, hashValues: true},
+			want: `
+				kube_pod_annotations{annotation_checksum="2e373913e5ad677d",namespace="ns1",pod="pod1"} 1
+				kube_pod_labels{label_app="web",namespace="ns1",pod="pod1"} 1
+			`,
+		},
+	}
+
+	for i, test := range tests {
+		families := mapMetricFamilies(
+			append(podMetricFamilies, podAnnotationsMetricFamily([]string{"*"})),
+			limitLabels(test.limits),
+		)
+
+		c := generateMetricsTestCase{
+			Obj: pod,
+			Want: `
+				# HELP kube_pod_annotations Kubernetes annotations converted to Prometheus labels.
+				# HELP kube_pod_labels Kubernetes labels converted to Prometheus labels.
+				# TYPE kube_pod_annotations gauge
+				# TYPE kube_pod_labels gauge
+			` + test.want,
+			MetricNames: []string{"kube_pod_labels", "kube_pod_annotations"},
+			Func:        generator.ComposeMetricGenFuncs(families),
+			Headers:     generator.ExtractMetricFamilyHeaders(families),
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("%d: unexpected collecting result:\n%s", i, err)
+		}
+	}
+}
+
+func TestLabelNameScheme(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+			Labels: map[string]string{
+				"app.kubernetes.io/name": "web",
+				"example.com/name":       "other",
+				"tier":                   "frontend",
+			},
+			Annotations: map[string]string{
+				"example.com/owner": "team-a",
+			},
+		},
+	}
+
+	tests := []struct {
+		scheme string
+		limits labelLimits
+		want   string
+	}{
+		{
+			scheme: labelNameSchemeStripPrefix,
+			want: `
+				kube_pod_annotations{annotation_owner="team-a",namespace="ns1",pod="pod1"} 1
+				kube_pod_labels{label_name="web",label_tier="frontend",namespace="ns1",pod="pod1"} 1
+			`,
+		},
+		{
+			scheme: labelNameSchemeGeneric,
+			want: `
+				kube_pod_annotations{key="example.com/owner",namespace="ns1",pod="pod1",value="team-a"} 1
+				kube_pod_labels{key="app.kubernetes.io/name",namespace="ns1",pod="pod1",value="web"} 1
+				kube_pod_labels{key="example.com/name",namespace="ns1",pod="pod1",value="other"} 1
+				kube_pod_labels{key="tier",namespace="ns1",pod="pod1",value="frontend"} 1
+			`,
+		},
+		{
+			scheme: labelNameSchemeGeneric,
+			limits: labelLimits{maxCount: 1, maxValueLength: 2},
+			want: `
+				kube_pod_annotations{key="example.com/owner",namespace="ns1",pod="pod1",value="te"} 1
+				kube_pod_labels{key="app.kubernetes.io/name",namespace="ns1",pod="pod1",value="we"} 1
+			`,
+		},
+	}
+
+	for i, test := range tests {
+		families := append(podMetricFamilies, podAnnotationsMetricFamily([]string{"*"}))
+		families = mapMetricFamilies(families, limitLabels(test.limits), labelNameScheme(test.scheme))
+
+		c := generateMetricsTestCase{
+			Obj: pod,
+			Want: `
+				# HELP kube_pod_annotations Kubernetes annotations converted to Prometheus labels.
+				# HELP kube_pod_labels Kubernetes labels converted to Prometheus labels.
+				# TYPE kube_pod_annotations gauge
+				# TYPE kube_pod_labels gauge
+			` + test.want,
+			MetricNames: []string{"kube_pod_labels", "kube_pod_annotations"},
+			Func:        generator.ComposeMetricGenFuncs(families),
+			Headers:     generator.ExtractMetricFamilyHeaders(families),
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("%d: unexpected collecting result:\n%s", i, err)
+		}
+	}
+}
+
+func TestLabelNameSchemeCollisions(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+			Labels: map[string]string{
+				"team.name": "a",
+				"team_name": "b",
+			},
+		},
+	}
+
+	families := mapMetricFamilies(podMetricFamilies, labelNameScheme(labelNameSchemeGeneric))
+
+	c := generateMetricsTestCase{
+		Obj: pod,
+		Want: `
+			# HELP kube_pod_labels Kubernetes labels converted to Prometheus labels.
+			# TYPE kube_pod_labels gauge
+			kube_pod_labels{key="team.name",namespace="ns1",pod="pod1",value="a"} 1
+			kube_pod_labels{key="team_name",namespace="ns1",pod="pod1",value="b"} 1
+		`,
+		MetricNames: []string{"kube_pod_labels"},
+		Func:        generator.ComposeMetricGenFuncs(families),
+		Headers:     generator.ExtractMetricFamilyHeaders(families),
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestConditionSampleTimestamps(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "127.0.0.1",
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Unix(1500000000, 0)},
+				{Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse},
+			},
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+		},
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: metav1.Unix(1500000000, 0)},
+				{Type: v1.PodReady, Status: v1.ConditionFalse, LastTransitionTime: metav1.Unix(1500000100, 0)},
+			},
+		},
+	}
+
+	tests := []struct {
+		obj         interface{}
+		families    []generator.FamilyGenerator
+		metricNames []string
+		want        string
+	}{
+		{
+			obj:         node,
+			families:    nodeMetricFamilies(newUnschedulableTracker()),
+			metricNames: []string{"kube_node_status_condition"},
+			want: `
+				# HELP kube_node_status_condition The condition of a cluster node.
+				# TYPE kube_node_status_condition gauge
+				kube_node_status_condition{condition="MemoryPressure",node="127.0.0.1",status="false"} 1
+				kube_node_status_condition{condition="MemoryPressure",node="127.0.0.1",status="true"} 0
+				kube_node_status_condition{condition="MemoryPressure",node="127.0.0.1",status="unknown"} 0
+				kube_node_status_condition{condition="Ready",node="127.0.0.1",status="false"} 0
+				kube_node_status_condition{condition="Ready",node="127.0.0.1",status="true"} 1
+				kube_node_status_condition{condition="Ready",node="127.0.0.1",status="unknown"} 0
+			`,
+		},
+		{
+			obj:         node,
+			families:    mapMetricFamilies(nodeMetricFamilies(newUnschedulableTracker()), conditionSampleTimestamps),
+			metricNames: []string{"kube_node_status_condition"},
+			want: `
+				# HELP kube_node_status_condition The condition of a cluster node.
+				# TYPE kube_node_status_condition gauge
+				kube_node_status_condition{condition="MemoryPressure",node="127.0.0.1",status="false"} 1
+				kube_node_status_condition{condition="MemoryPressure",node="127.0.0.1",status="true"} 0
+				kube_node_status_condition{condition="MemoryPressure",node="127.0.0.1",status="unknown"} 0
+				kube_node_status_condition{condition="Ready",node="127.0.0.1",status="false"} 0
+				kube_node_status_condition{condition="Ready",node="127.0.0.1",status="true"} 1 1500000000000
+				kube_node_status_condition{condition="Ready",node="127.0.0.1",status="unknown"} 0
+			`,
+		},
+		{
+			obj:         pod,
+			families:    mapMetricFamilies(podMetricFamilies, conditionSampleTimestamps),
+			metricNames: []string{"kube_pod_status_ready", "kube_pod_status_scheduled"},
+			want: `
+				# HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
+				# HELP kube_pod_status_ready_time Unix timestamp when pod moved into ready status.
+				# HELP kube_pod_status_scheduled Describes the status of the scheduling process for the pod.
+				# HELP kube_pod_status_scheduled_time Unix timestamp when pod moved into scheduled status
+				# TYPE kube_pod_status_ready gauge
+				# TYPE kube_pod_status_ready_time gauge
+				# TYPE kube_pod_status_scheduled gauge
+				# TYPE kube_pod_status_scheduled_time gauge
+				kube_pod_status_scheduled_time{namespace="ns1",pod="pod1"} 1.5e+09
+				kube_pod_status_ready{condition="false",namespace="ns1",pod="pod1"} 1 1500000100000
+				kube_pod_status_ready{condition="true",namespace="ns1",pod="pod1"} 0
+				kube_pod_status_ready{condition="unknown",namespace="ns1",pod="pod1"} 0
+				kube_pod_status_scheduled{condition="false",namespace="ns1",pod="pod1"} 0
+				kube_pod_status_scheduled{condition="true",namespace="ns1",pod="pod1"} 1 1500000000000
+				kube_pod_status_scheduled{condition="unknown",namespace="ns1",pod="pod1"} 0
+			`,
+		},
+	}
+
+	for i, test := range tests {
+		c := generateMetricsTestCase{
+			Obj:         test.obj,
+			Want:        test.want,
+			MetricNames: test.metricNames,
+			Func:        generator.ComposeMetricGenFuncs(test.families),
+			Headers:     generator.ExtractMetricFamilyHeaders(test.families),
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("%d: unexpected collecting result:\n%s", i, err)
+		}
+	}
+}
+
+func TestCustomLabels(t *testing.T) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "configmap1",
+			Namespace: "ns1",
+		},
+	}
+
+	families := mapMetricFamilies(configMapMetricFamilies, customLabels(map[string]string{
+		"region":    "eu-west-1",
+		"cluster":   "prod-eu1",
+		"namespace": "overridden",
+	}))
+
+	c := generateMetricsTestCase{
+		Obj: cm,
+		Want: `
+			# HELP kube_configmap_info Information about configmap.
+			# TYPE kube_configmap_info gauge
+			kube_configmap_info{cluster="prod-eu1",configmap="configmap1",namespace="ns1",region="eu-west-1"} 1
+		`,
+		MetricNames: []string{"kube_configmap_info"},
+		Func:        generator.ComposeMetricGenFuncs(families),
+		Headers:     generator.ExtractMetricFamilyHeaders(families),
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestUIDLabel(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pod1",
+			Namespace:         "ns1",
+			UID:               "abc-123",
+			CreationTimestamp: metav1.Unix(1500000000, 0),
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyAlways,
+		},
+	}
+
+	families := mapMetricFamilies(podMetricFamilies, uidLabel)
+
+	c := generateMetricsTestCase{
+		Obj: pod,
+		Want: `
+			# HELP kube_pod_created Unix creation timestamp
+			# HELP kube_pod_restart_policy Describes the restart policy in use by this pod.
+			# TYPE kube_pod_created gauge
+			# TYPE kube_pod_restart_policy gauge
+			kube_pod_created{namespace="ns1",pod="pod1",uid="abc-123"} 1.5e+09
+			kube_pod_restart_policy{namespace="ns1",pod="pod1",type="Always"} 1
+		`,
+		MetricNames: []string{"kube_pod_created", "kube_pod_restart_policy"},
+		Func:        generator.ComposeMetricGenFuncs(families),
+		Headers:     generator.ExtractMetricFamilyHeaders(families),
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
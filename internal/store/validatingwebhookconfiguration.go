@@ -52,15 +52,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp.",
 			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(vwc *admissionregistration.ValidatingWebhookConfiguration) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !vwc.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(vwc.CreationTimestamp.Unix()),
-					})
-				}
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: createdMetric(vwc.CreationTimestamp),
 				}
 			}),
 		},
@@ -88,6 +81,21 @@ func createValidatingWebhookConfigurationListWatch(kubeClient clientset.Interfac
 	}
 }
 
+// validatingWebhookConfigurationAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a ValidatingWebhookConfiguration as Prometheus labels.
+func validatingWebhookConfigurationAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_validatingwebhookconfiguration_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(vwc *admissionregistration.ValidatingWebhookConfiguration) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(vwc.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapValidatingWebhookConfigurationFunc(f func(*admissionregistration.ValidatingWebhookConfiguration) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		mutatingWebhookConfiguration := obj.(*admissionregistration.ValidatingWebhookConfiguration)
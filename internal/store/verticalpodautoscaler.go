@@ -210,6 +210,36 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_verticalpodautoscaler_owner",
+			Type: metric.Gauge,
+			Help: "Information about the VerticalPodAutoscaler's owner.",
+			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
+				return &metric.Family{
+					Metrics: ownerMetrics(a.GetOwnerReferences()),
+				}
+			}),
+		},
+		{
+			Name: "kube_verticalpodautoscaler_created",
+			Type: metric.Gauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
+				return &metric.Family{
+					Metrics: createdMetric(a.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name: "kube_verticalpodautoscaler_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the VerticalPodAutoscaler.",
+			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(a.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
@@ -239,6 +269,21 @@ func vpaResourcesToMetrics(containerName string, resources v1.ResourceList) []*m
 	return ms
 }
 
+// vpaAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a VerticalPodAutoscaler as Prometheus labels.
+func vpaAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_verticalpodautoscaler_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(a.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapVPAFunc(f func(*autoscaling.VerticalPodAutoscaler) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		vpa := obj.(*autoscaling.VerticalPodAutoscaler)
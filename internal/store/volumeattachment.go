@@ -72,15 +72,9 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1beta1.VolumeAttachment) *metric.Family {
-				if !va.CreationTimestamp.IsZero() {
-					m := metric.Metric{
-						LabelKeys:   nil,
-						LabelValues: nil,
-						Value:       float64(va.CreationTimestamp.Unix()),
-					}
-					return &metric.Family{Metrics: []*metric.Metric{&m}}
+				return &metric.Family{
+					Metrics: createdMetric(va.CreationTimestamp),
 				}
-				return &metric.Family{Metrics: []*metric.Metric{}}
 			}),
 		},
 		{
@@ -135,9 +129,34 @@ var (
 				}
 			}),
 		},
+		{
+			Name: "kube_volumeattachment_metadata_resource_version",
+			Type: metric.Gauge,
+			Help: "Resource version representing a specific version of the VolumeAttachment.",
+			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1beta1.VolumeAttachment) *metric.Family {
+				return &metric.Family{
+					Metrics: resourceVersionMetric(va.ObjectMeta.ResourceVersion),
+				}
+			}),
+		},
 	}
 )
 
+// volumeAttachmentAnnotationsMetricFamily returns the family generator
+// exposing the allowed annotations of a VolumeAttachment as Prometheus labels.
+func volumeAttachmentAnnotationsMetricFamily(allowedAnnotations []string) generator.FamilyGenerator {
+	return generator.FamilyGenerator{
+		Name: "kube_volumeattachment_annotations",
+		Type: metric.Gauge,
+		Help: "Kubernetes annotations converted to Prometheus labels.",
+		GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1beta1.VolumeAttachment) *metric.Family {
+			return &metric.Family{
+				Metrics: annotationsMetric(va.Annotations, allowedAnnotations),
+			}
+		}),
+	}
+}
+
 func wrapVolumeAttachmentFunc(f func(*storagev1beta1.VolumeAttachment) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
 		va := obj.(*storagev1beta1.VolumeAttachment)
@@ -0,0 +1,246 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+var (
+	descWorkloadLabelsDefaultLabels = []string{"namespace", "workload", "workload_type"}
+
+	workloadMetricFamilies = []generator.FamilyGenerator{
+		{
+			Name:  "kube_workload_replicas_desired",
+			Type:  metric.Gauge,
+			Help:  "The number of desired replicas of a Deployment, StatefulSet, DaemonSet or ReplicaSet.",
+			OptIn: true,
+			GenerateFunc: wrapWorkloadFunc(func(w *workload) *metric.Family {
+				return workloadReplicasFamily(w.desired)
+			}),
+		},
+		{
+			Name:  "kube_workload_replicas_ready",
+			Type:  metric.Gauge,
+			Help:  "The number of ready replicas of a Deployment, StatefulSet, DaemonSet or ReplicaSet.",
+			OptIn: true,
+			GenerateFunc: wrapWorkloadFunc(func(w *workload) *metric.Family {
+				return workloadReplicasFamily(w.ready)
+			}),
+		},
+		{
+			Name:  "kube_workload_replicas_updated",
+			Type:  metric.Gauge,
+			Help:  "The number of replicas of a Deployment, StatefulSet or DaemonSet running the latest revision.",
+			OptIn: true,
+			GenerateFunc: wrapWorkloadFunc(func(w *workload) *metric.Family {
+				return workloadReplicasFamily(w.updated)
+			}),
+		},
+		{
+			Name:  "kube_workload_replicas_available",
+			Type:  metric.Gauge,
+			Help:  "The number of available replicas of a Deployment, DaemonSet or ReplicaSet.",
+			OptIn: true,
+			GenerateFunc: wrapWorkloadFunc(func(w *workload) *metric.Family {
+				return workloadReplicasFamily(w.available)
+			}),
+		},
+	}
+)
+
+func wrapWorkloadFunc(f func(*workload) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		w := obj.(*workload)
+
+		metricFamily := f(w)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descWorkloadLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{w.Namespace, w.Name, w.kind}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+// workloadReplicasFamily returns a family with the given number of replicas,
+// or an empty family if the workload does not have such replicas.
+func workloadReplicasFamily(replicas *int32) *metric.Family {
+	if replicas == nil {
+		return &metric.Family{}
+	}
+	return &metric.Family{
+		Metrics: []*metric.Metric{
+			{
+				Value: float64(*replicas),
+			},
+		},
+	}
+}
+
+// workload holds the replicas of a Deployment, StatefulSet, DaemonSet or
+// ReplicaSet in a common form. Replicas a kind of workload does not have are
+// nil.
+type workload struct {
+	metav1.ObjectMeta
+	kind      string
+	desired   *int32
+	ready     *int32
+	updated   *int32
+	available *int32
+}
+
+// newWorkload converts the given Deployment, StatefulSet, DaemonSet or
+// ReplicaSet into a workload.
+func newWorkload(obj interface{}) (*workload, bool) {
+	var w *workload
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		w = &workload{
+			kind:      "Deployment",
+			desired:   o.Spec.Replicas,
+			ready:     &o.Status.ReadyReplicas,
+			updated:   &o.Status.UpdatedReplicas,
+			available: &o.Status.AvailableReplicas,
+		}
+		w.ObjectMeta = workloadMeta(o)
+	case *appsv1.StatefulSet:
+		w = &workload{
+			kind:    "StatefulSet",
+			desired: o.Spec.Replicas,
+			ready:   &o.Status.ReadyReplicas,
+			updated: &o.Status.UpdatedReplicas,
+		}
+		w.ObjectMeta = workloadMeta(o)
+	case *appsv1.DaemonSet:
+		w = &workload{
+			kind:      "DaemonSet",
+			desired:   &o.Status.DesiredNumberScheduled,
+			ready:     &o.Status.NumberReady,
+			updated:   &o.Status.UpdatedNumberScheduled,
+			available: &o.Status.NumberAvailable,
+		}
+		w.ObjectMeta = workloadMeta(o)
+	case *appsv1.ReplicaSet:
+		w = &workload{
+			kind:      "ReplicaSet",
+			desired:   o.Spec.Replicas,
+			ready:     &o.Status.ReadyReplicas,
+			available: &o.Status.AvailableReplicas,
+		}
+		w.ObjectMeta = workloadMeta(o)
+	default:
+		return nil, false
+	}
+	return w, true
+}
+
+func workloadMeta(o metav1.Object) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: o.GetNamespace(),
+		Name:      o.GetName(),
+		UID:       o.GetUID(),
+	}
+}
+
+// workloadStore hands the Deployments, StatefulSets, DaemonSets and
+// ReplicaSets fed in through the stores returned by kindStore, each registered
+// with its own reflector, over to the given MetricsStore as workloads.
+type workloadStore struct {
+	mutex sync.Mutex
+	// workloads holds the workloads by their kind and UID.
+	workloads map[string]map[types.UID]*workload
+	store     *metricsstore.MetricsStore
+}
+
+func newWorkloadStore(store *metricsstore.MetricsStore) *workloadStore {
+	return &workloadStore{
+		workloads: map[string]map[types.UID]*workload{},
+		store:     store,
+	}
+}
+
+// kindStore returns the store to register with the reflector of the workloads
+// of the given kind.
+func (s *workloadStore) kindStore(kind string) *callbackStore {
+	return &callbackStore{
+		add: func(obj interface{}) error {
+			w, ok := newWorkload(obj)
+			if !ok {
+				return nil
+			}
+
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			if _, ok := s.workloads[kind]; !ok {
+				s.workloads[kind] = map[types.UID]*workload{}
+			}
+			s.workloads[kind][w.UID] = w
+
+			return s.store.Add(w)
+		},
+		delete: func(obj interface{}) error {
+			w, ok := newWorkload(obj)
+			if !ok {
+				return nil
+			}
+
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			delete(s.workloads[kind], w.UID)
+
+			return s.store.Delete(w)
+		},
+		replace: func(list []interface{}) error {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			// Only replace the workloads of this kind, as the workloads of
+			// the other kinds are fed in by other reflectors.
+			for _, w := range s.workloads[kind] {
+				if err := s.store.Delete(w); err != nil {
+					return err
+				}
+			}
+			s.workloads[kind] = map[types.UID]*workload{}
+
+			for _, obj := range list {
+				w, ok := newWorkload(obj)
+				if !ok {
+					continue
+				}
+				s.workloads[kind][w.UID] = w
+				if err := s.store.Add(w); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
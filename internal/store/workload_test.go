@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	generator "k8s.io/kube-state-metrics/pkg/metric_generator"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestWorkloadStore(t *testing.T) {
+	const metadata = `
+		# HELP kube_workload_replicas_desired The number of desired replicas of a Deployment, StatefulSet, DaemonSet or ReplicaSet.
+		# TYPE kube_workload_replicas_desired gauge
+		# HELP kube_workload_replicas_ready The number of ready replicas of a Deployment, StatefulSet, DaemonSet or ReplicaSet.
+		# TYPE kube_workload_replicas_ready gauge
+		# HELP kube_workload_replicas_updated The number of replicas of a Deployment, StatefulSet or DaemonSet running the latest revision.
+		# TYPE kube_workload_replicas_updated gauge
+		# HELP kube_workload_replicas_available The number of available replicas of a Deployment, DaemonSet or ReplicaSet.
+		# TYPE kube_workload_replicas_available gauge
+	`
+
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns1",
+			UID:       types.UID("uid-" + name),
+		}
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: objectMeta("deployment1"),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32ptr(3),
+		},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas:     2,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 2,
+		},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: objectMeta("statefulset1"),
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32ptr(2),
+		},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas:   2,
+			UpdatedReplicas: 2,
+		},
+	}
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: objectMeta("daemonset1"),
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 5,
+			NumberReady:            4,
+			UpdatedNumberScheduled: 5,
+			NumberAvailable:        4,
+		},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: objectMeta("replicaset1"),
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: int32ptr(1),
+		},
+		Status: appsv1.ReplicaSetStatus{
+			ReadyReplicas:     1,
+			AvailableReplicas: 0,
+		},
+	}
+
+	ms := metricsstore.NewMetricsStore(
+		generator.ExtractMetricFamilyHeaders(workloadMetricFamilies),
+		generator.ComposeMetricGenFuncs(workloadMetricFamilies),
+	)
+	s := newWorkloadStore(ms)
+	deployments := s.kindStore("Deployment")
+	statefulSets := s.kindStore("StatefulSet")
+	daemonSets := s.kindStore("DaemonSet")
+	replicaSets := s.kindStore("ReplicaSet")
+
+	write := func() string {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		return strings.TrimSpace(w.String())
+	}
+
+	if err := deployments.Replace([]interface{}{deployment}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := statefulSets.Replace([]interface{}{statefulSet}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := daemonSets.Add(daemonSet); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaSets.Add(replicaSet); err != nil {
+		t.Fatal(err)
+	}
+
+	want := metadata + `
+		kube_workload_replicas_desired{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 5
+		kube_workload_replicas_desired{namespace="ns1",workload="deployment1",workload_type="Deployment"} 3
+		kube_workload_replicas_desired{namespace="ns1",workload="replicaset1",workload_type="ReplicaSet"} 1
+		kube_workload_replicas_desired{namespace="ns1",workload="statefulset1",workload_type="StatefulSet"} 2
+		kube_workload_replicas_ready{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 4
+		kube_workload_replicas_ready{namespace="ns1",workload="deployment1",workload_type="Deployment"} 2
+		kube_workload_replicas_ready{namespace="ns1",workload="replicaset1",workload_type="ReplicaSet"} 1
+		kube_workload_replicas_ready{namespace="ns1",workload="statefulset1",workload_type="StatefulSet"} 2
+		kube_workload_replicas_updated{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 5
+		kube_workload_replicas_updated{namespace="ns1",workload="deployment1",workload_type="Deployment"} 1
+		kube_workload_replicas_updated{namespace="ns1",workload="statefulset1",workload_type="StatefulSet"} 2
+		kube_workload_replicas_available{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 4
+		kube_workload_replicas_available{namespace="ns1",workload="deployment1",workload_type="Deployment"} 2
+		kube_workload_replicas_available{namespace="ns1",workload="replicaset1",workload_type="ReplicaSet"} 0
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing the workloads of a kind keeps the workloads of the other
+	// kinds, which are fed in by other reflectors.
+	if err := deployments.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := statefulSets.Delete(statefulSet); err != nil {
+		t.Fatal(err)
+	}
+
+	want = metadata + `
+		kube_workload_replicas_desired{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 5
+		kube_workload_replicas_desired{namespace="ns1",workload="replicaset1",workload_type="ReplicaSet"} 1
+		kube_workload_replicas_ready{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 4
+		kube_workload_replicas_ready{namespace="ns1",workload="replicaset1",workload_type="ReplicaSet"} 1
+		kube_workload_replicas_updated{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 5
+		kube_workload_replicas_available{namespace="ns1",workload="daemonset1",workload_type="DaemonSet"} 4
+		kube_workload_replicas_available{namespace="ns1",workload="replicaset1",workload_type="ReplicaSet"} 0
+	`
+	if err := compareOutput(want, write()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -18,34 +18,58 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"math"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/http2"
+	"k8s.io/apimachinery/pkg/util/clock"
 	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
 	clientset "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
 	"k8s.io/klog"
 
 	"k8s.io/kube-state-metrics/internal/store"
-	"k8s.io/kube-state-metrics/pkg/allowdenylist"
+	"k8s.io/kube-state-metrics/pkg/cloudmonitoring"
+	"k8s.io/kube-state-metrics/pkg/credentials"
+	"k8s.io/kube-state-metrics/pkg/export"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 	"k8s.io/kube-state-metrics/pkg/metricshandler"
+	"k8s.io/kube-state-metrics/pkg/optin"
 	"k8s.io/kube-state-metrics/pkg/options"
 	"k8s.io/kube-state-metrics/pkg/util/proc"
 	"k8s.io/kube-state-metrics/pkg/version"
+	"k8s.io/kube-state-metrics/pkg/watch"
 )
 
 const (
 	metricsPath = "/metrics"
 	healthzPath = "/healthz"
+
+	debugObjectPath = "/debug/object"
+	streamPath      = "/stream"
+	pprofPath       = "/debug/pprof/"
+
+	// shutdownTimeout is the time in-flight requests are given to finish
+	// once a termination signal has been received.
+	shutdownTimeout = 5 * time.Second
 )
 
 // promLogger implements promhttp.Logger
@@ -56,12 +80,35 @@ func (pl promLogger) Println(v ...interface{}) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == rbacCommand {
+		if err := runRBAC(os.Args[2:], os.Stdout); err != nil {
+			klog.Fatalf("Error: %s", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == rulesCommand {
+		if err := runRules(os.Args[2:], os.Stdout); err != nil {
+			klog.Fatalf("Error: %s", err)
+		}
+		return
+	}
+
 	opts := options.NewOptions()
 	opts.AddFlags()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Stop all reflectors and servers on termination, so that kube-state-metrics
+	// shuts down cleanly.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		klog.Infof("Received %s, shutting down", sig)
+		cancel()
+	}()
+
 	err := opts.Parse()
 	if err != nil {
 		klog.Fatalf("Error: %s", err)
@@ -76,6 +123,27 @@ func main() {
 		opts.Usage()
 		os.Exit(0)
 	}
+
+	if opts.ListCollectorsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(store.Collectors()); err != nil {
+			klog.Fatalf("Failed to list collectors: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	if len(opts.ListenHosts()) == 0 || len(opts.TelemetryListenHosts()) == 0 {
+		klog.Fatal("--host and --telemetry-host must not be empty")
+	}
+	if opts.ListenSocket != "" && len(opts.SourceCIDRAllowlist) > 0 {
+		klog.Fatal("--listen-socket and --source-cidr-allowlist are mutually exclusive")
+	}
+
+	if opts.EnableReloadAPI && opts.ConfigFile == "" {
+		klog.Fatal("--enable-reload-api requires --config")
+	}
+
 	storeBuilder := store.NewBuilder()
 
 	ksmMetricsRegistry := prometheus.NewRegistry()
@@ -94,37 +162,115 @@ func main() {
 		klog.Fatalf("Failed to set up resources: %v", err)
 	}
 
-	if len(opts.Namespaces) == 0 {
-		klog.Info("Using all namespace")
-		storeBuilder.WithNamespaces(options.DefaultNamespaces)
-	} else {
-		if opts.Namespaces.IsAllNamespaces() {
-			klog.Info("Using all namespace")
-		} else {
-			klog.Infof("Using %s namespaces", opts.Namespaces)
-		}
-		storeBuilder.WithNamespaces(opts.Namespaces)
+	configuredOpts, err := opts.LoadConfig()
+	if err != nil {
+		klog.Fatalf("Failed to load config: %v", err)
 	}
 
-	allowDenyList, err := allowdenylist.New(opts.MetricAllowlist, opts.MetricDenylist)
-	if err != nil {
+	if err := configureFilters(storeBuilder, configuredOpts); err != nil {
 		klog.Fatal(err)
 	}
 
-	err = allowDenyList.Parse()
+	optInList, err := optin.NewMetricFamilyFilter(opts.MetricOptInList)
 	if err != nil {
-		klog.Fatalf("error initializing the allowdeny list : %v", err)
+		klog.Fatalf("error initializing the opt-in metric list : %v", err)
+	}
+
+	klog.Infof("metric opt-in: %v", optInList.Status())
+
+	storeBuilder.WithOptInList(optInList)
+
+	if !opts.TrackUnscheduledPods {
+		klog.Info("Not tracking pods which have not been scheduled to a node")
+	}
+	storeBuilder.WithTrackUnscheduledPods(opts.TrackUnscheduledPods)
+
+	if (opts.ObjectOptInAnnotation == "") != (len(opts.ObjectOptInResources) == 0) {
+		klog.Fatal("--object-opt-in-annotation and --object-opt-in-resources must be set together")
+	}
+	if opts.ObjectOptInAnnotation != "" {
+		if err := storeBuilder.WithObjectOptIn(opts.ObjectOptInAnnotation, opts.ObjectOptInResources.AsSlice()); err != nil {
+			klog.Fatalf("Failed to set up object opt-in: %v", err)
+		}
+		klog.Infof("Only exposing the objects of resources %s with annotation %s", opts.ObjectOptInResources.String(), opts.ObjectOptInAnnotation)
 	}
 
-	klog.Infof("metric allow-denylisting: %v", allowDenyList.Status())
+	if opts.NamespaceSeriesLimit < 0 {
+		klog.Fatal("--namespace-series-limit must not be negative")
+	}
+	if opts.NamespaceSeriesLimit > 0 {
+		klog.Infof("Exposing at most %d series of every resource per namespace", opts.NamespaceSeriesLimit)
+	}
+	storeBuilder.WithNamespaceSeriesLimit(opts.NamespaceSeriesLimit)
 
-	storeBuilder.WithAllowDenyList(allowDenyList)
+	storeBuilder.WithOnlyCurrentConditionStatus(opts.OnlyCurrentConditionStatus)
+	storeBuilder.WithSampleTimestamps(opts.SampleTimestamps)
+	storeBuilder.WithUIDLabel(opts.UIDLabel)
+
+	if len(opts.CustomLabels) > 0 {
+		klog.Infof("Using custom labels: %s", opts.CustomLabels.String())
+	}
+	storeBuilder.WithCustomLabels(opts.CustomLabels)
+
+	if opts.MetricLabelsMaxCount < 0 || opts.MetricLabelsValueLengthLimit < 0 {
+		klog.Fatal("--metric-labels-max-count and --metric-labels-value-length-limit must not be negative")
+	}
+	storeBuilder.WithLabelLimits(opts.MetricLabelsMaxCount, opts.MetricLabelsValueLengthLimit, opts.MetricLabelsHashLongValues)
+
+	if err := storeBuilder.WithLabelNameScheme(opts.MetricLabelsNameScheme); err != nil {
+		klog.Fatalf("Failed to set up label name scheme: %v", err)
+	}
 
 	storeBuilder.WithGenerateStoreFunc(storeBuilder.DefaultGenerateStoreFunc())
 
+	changeFuncs := []func(kind string, c metricsstore.Change){}
+	rebuildFuncs := []func(){}
+	if opts.ExportURL != "" {
+		exporter, err := export.New(opts.ExportURL, opts.ExportSubject, export.NewMetrics(ksmMetricsRegistry))
+		if err != nil {
+			klog.Fatalf("Failed to set up export: %v", err)
+		}
+		klog.Infof("Exporting changes of metrics to subject %s", opts.ExportSubject)
+		changeFuncs = append(changeFuncs, exporter.OnChange)
+		rebuildFuncs = append(rebuildFuncs, exporter.OnRebuild)
+		go exporter.Run(ctx)
+	}
+	var stream *export.Stream
+	if opts.EnableStreamAPI {
+		stream = export.NewStream()
+		changeFuncs = append(changeFuncs, stream.OnChange)
+		rebuildFuncs = append(rebuildFuncs, stream.OnRebuild)
+	}
+	if opts.MetricAuditLog {
+		klog.Info("Logging changes of series as audit records")
+		changeFuncs = append(changeFuncs, export.NewAuditor().OnChange)
+	}
+	if len(changeFuncs) > 0 {
+		storeBuilder.WithChangeFunc(func(kind string, c metricsstore.Change) {
+			for _, f := range changeFuncs {
+				f(kind, c)
+			}
+		})
+	}
+	if len(rebuildFuncs) > 0 {
+		storeBuilder.WithRebuildFunc(func() {
+			for _, f := range rebuildFuncs {
+				f()
+			}
+		})
+	}
+
 	proc.StartReaper()
 
-	kubeClient, vpaClient, err := createKubeClient(opts.Apiserver, opts.Kubeconfig)
+	if opts.KubeAPIBackoffBase <= 0 || opts.KubeAPIBackoffMax < opts.KubeAPIBackoffBase {
+		klog.Fatal("--kube-api-backoff-base must be positive and must not exceed --kube-api-backoff-max")
+	}
+	watch.ReflectorBackoff.Duration = opts.KubeAPIBackoffBase
+	watch.ReflectorBackoff.Cap = opts.KubeAPIBackoffMax
+	// Keep doubling the backoff until it reaches the cap.
+	watch.ReflectorBackoff.Steps = math.MaxInt32
+
+	kubeClient, vpaClient, err := createKubeClient(opts, credentials.NewMetrics(ksmMetricsRegistry))
 	if err != nil {
 		klog.Fatalf("Failed to create client: %v", err)
 	}
@@ -132,17 +278,94 @@ func main() {
 	storeBuilder.WithVPAClient(vpaClient)
 	storeBuilder.WithSharding(opts.Shard, opts.TotalShards)
 
+	namespaces := &namespaceSelector{ctx: ctx, kubeClient: kubeClient}
+	if err := namespaces.set(storeBuilder, configuredOpts.NamespaceLabelSelector); err != nil {
+		klog.Fatal(err)
+	}
+
 	ksmMetricsRegistry.MustRegister(
 		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
 		prometheus.NewGoCollector(),
+		metric.SanitizedLabelValuesTotal,
+		rateLimitedScrapesTotal,
+		unauthorizedRequestsTotal,
 	)
-	go telemetryServer(ksmMetricsRegistry, opts.TelemetryHost, opts.TelemetryPort)
+	go func() {
+		if err := telemetryServer(ctx, ksmMetricsRegistry, opts); err != nil {
+			klog.Fatalf("Failed to run self metrics server: %v", err)
+		}
+	}()
 
-	serveMetrics(ctx, kubeClient, storeBuilder, opts, opts.Host, opts.Port, opts.EnableGZIPEncoding)
+	m := metricshandler.New(
+		opts,
+		kubeClient,
+		storeBuilder,
+		opts.EnableGZIPEncoding,
+	)
+	r := &reloader{opts: opts, handler: m, namespaces: namespaces}
+
+	if opts.MetricCacheFile != "" {
+		if opts.MetricCacheInterval <= 0 {
+			klog.Fatal("--metric-cache-interval must be positive")
+		}
+		klog.Infof("Persisting metrics to %s every %s", opts.MetricCacheFile, opts.MetricCacheInterval)
+		go m.RunMetricCache(ctx, opts.MetricCacheInterval)
+	}
+
+	if opts.CloudMonitoring {
+		if opts.CloudMonitoringInterval < cloudmonitoring.MinInterval {
+			klog.Fatalf("--cloud-monitoring-interval must be at least %s", cloudmonitoring.MinInterval)
+		}
+		exporter := cloudmonitoring.New(cloudmonitoring.Config{
+			Project:     opts.CloudMonitoringProject,
+			Location:    opts.CloudMonitoringLocation,
+			ClusterName: opts.CloudMonitoringClusterName,
+			Interval:    opts.CloudMonitoringInterval,
+		}, m.WriteAll, cloudmonitoring.NewMetrics(ksmMetricsRegistry))
+		klog.Infof("Exporting metrics to Cloud Monitoring every %s", opts.CloudMonitoringInterval)
+		go exporter.Run(ctx)
+	}
+
+	// Reload the config file on SIGHUP, following the convention of the
+	// Prometheus ecosystem.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				klog.Info("Received SIGHUP, reloading config")
+				if err := r.reload(); err != nil {
+					klog.Errorf("Failed to reload config: %v", err)
+					continue
+				}
+				klog.Info("Reloaded config")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var authorize func(http.Handler) http.Handler
+	if opts.ScrapeAuthorization {
+		klog.Info("Authorizing requests with TokenReviews and SubjectAccessReviews")
+		authorize = func(next http.Handler) http.Handler {
+			return newScrapeAuthorizer(kubeClient, storeBuilder, clock.RealClock{}, next)
+		}
+	}
+
+	var reload http.Handler
+	if opts.EnableReloadAPI {
+		reload = r
+	}
+
+	if err := serveMetrics(ctx, m, reload, stream, authorize, opts); err != nil {
+		klog.Fatalf("Failed to run metrics server: %v", err)
+	}
 }
 
-func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface, vpaclientset.Interface, error) {
-	config, err := clientcmd.BuildConfigFromFlags(apiserver, kubeconfig)
+func createKubeClient(opts *options.Options, credentialMetrics *credentials.Metrics) (clientset.Interface, vpaclientset.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags(opts.Apiserver, opts.Kubeconfig)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -150,6 +373,24 @@ func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface,
 	config.UserAgent = version.GetVersion().String()
 	config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
 	config.ContentType = "application/vnd.kubernetes.protobuf"
+	if err := configureTLS(config, opts); err != nil {
+		return nil, nil, err
+	}
+	config.QPS = opts.KubeAPIQPS
+	config.Burst = opts.KubeAPIBurst
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid proxy URL")
+		}
+		config.WrapTransport = transport.Wrappers(proxyTransport(proxyURL), config.WrapTransport)
+	}
+
+	config, err = credentials.InstrumentConfig(config, credentialMetrics)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to set up credentials")
+	}
 
 	kubeClient, err := clientset.NewForConfig(config)
 	if err != nil {
@@ -175,11 +416,59 @@ func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface,
 	return kubeClient, vpaClient, nil
 }
 
-func telemetryServer(registry prometheus.Gatherer, host string, port int) {
-	// Address to listen on for web interface and telemetry
-	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
+// configureTLS overrides the TLS settings of the given config with the ones
+// given on the command line.
+func configureTLS(config *rest.Config, opts *options.Options) error {
+	if (opts.APIServerClientCertFile == "") != (opts.APIServerClientKeyFile == "") {
+		return errors.New("--apiserver-client-cert-file and --apiserver-client-key-file must be set together")
+	}
+	if opts.APIServerInsecureSkipVerify && opts.APIServerCAFile != "" {
+		return errors.New("--apiserver-ca-file and --apiserver-insecure-skip-verify are mutually exclusive")
+	}
+
+	if opts.APIServerCAFile != "" {
+		config.TLSClientConfig.CAFile = opts.APIServerCAFile
+		config.TLSClientConfig.CAData = nil
+	}
+	if opts.APIServerInsecureSkipVerify {
+		klog.Warning("Not verifying the certificate of the apiserver, the connection to the apiserver is insecure")
+		// client-go refuses to skip the verification if a CA is configured.
+		config.TLSClientConfig.Insecure = true
+		config.TLSClientConfig.CAFile = ""
+		config.TLSClientConfig.CAData = nil
+	}
+	if opts.APIServerClientCertFile != "" {
+		config.TLSClientConfig.CertFile = opts.APIServerClientCertFile
+		config.TLSClientConfig.CertData = nil
+		config.TLSClientConfig.KeyFile = opts.APIServerClientKeyFile
+		config.TLSClientConfig.KeyData = nil
+	}
 
-	klog.Infof("Starting kube-state-metrics self metrics server: %s", listenAddress)
+	return nil
+}
+
+// proxyTransport returns a transport wrapper sending the requests of the
+// wrapped transport through the given proxy. It must wrap the transport
+// created by client-go, before any other wrappers.
+func proxyTransport(proxyURL *url.URL) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			klog.Warningf("Not using proxy %s for transport of type %T", proxyURL, rt)
+			return rt
+		}
+		// The transport is cached and shared by client-go, so change a copy.
+		t = t.Clone()
+		t.Proxy = http.ProxyURL(proxyURL)
+		return t
+	}
+}
+
+func telemetryServer(ctx context.Context, registry prometheus.Gatherer, opts *options.Options) error {
+	// Addresses to listen on for web interface and telemetry
+	listenAddresses := joinHostsPort(opts.TelemetryListenHosts(), opts.TelemetryPort)
+
+	klog.Infof("Starting kube-state-metrics self metrics server: %s", strings.Join(listenAddresses, ","))
 
 	mux := http.NewServeMux()
 
@@ -197,32 +486,48 @@ func telemetryServer(registry prometheus.Gatherer, host string, port int) {
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+	return listenAndServe(ctx, newServer(listenAddresses[0], mux, opts), opts.ServerEnableH2C, listenAddresses)
 }
 
-func serveMetrics(ctx context.Context, kubeClient clientset.Interface, storeBuilder *store.Builder, opts *options.Options, host string, port int, enableGZIPEncoding bool) {
-	// Address to listen on for web interface and telemetry
-	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
+// serveMetrics serves the metrics of the given handler. If authorize is not
+// nil, it wraps the handlers serving metrics. If reload is not nil, it is
+// served on reloadPath.
+func serveMetrics(ctx context.Context, m *metricshandler.MetricsHandler, reload http.Handler, stream *export.Stream, authorize func(http.Handler) http.Handler, opts *options.Options) error {
+	// Addresses to listen on for web interface and telemetry
+	listenAddresses := joinHostsPort(opts.ListenHosts(), opts.Port)
+	if opts.ListenSocket != "" {
+		listenAddresses = []string{opts.ListenSocket}
+	}
 
-	klog.Infof("Starting metrics server: %s", listenAddress)
+	klog.Infof("Starting metrics server: %s", strings.Join(listenAddresses, ","))
 
 	mux := http.NewServeMux()
 
+	if authorize == nil {
+		authorize = func(next http.Handler) http.Handler { return next }
+	}
+
 	// TODO: This doesn't belong into serveMetrics
-	mux.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
-	mux.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
-	mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
-	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
-	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	mux.Handle(pprofPath, authorize(http.HandlerFunc(pprof.Index)))
+	mux.Handle(pprofPath+"cmdline", authorize(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle(pprofPath+"profile", authorize(http.HandlerFunc(pprof.Profile)))
+	mux.Handle(pprofPath+"symbol", authorize(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle(pprofPath+"trace", authorize(http.HandlerFunc(pprof.Trace)))
 
-	m := metricshandler.New(
-		opts,
-		kubeClient,
-		storeBuilder,
-		enableGZIPEncoding,
-	)
 	go m.Run(ctx)
-	mux.Handle(metricsPath, m)
+	if opts.ScrapesPerMinutePerClient > 0 {
+		klog.Infof("Limiting scrapes to %d per minute per client", opts.ScrapesPerMinutePerClient)
+		mux.Handle(metricsPath, newScrapeRateLimiter(opts.ScrapesPerMinutePerClient, clock.RealClock{}, authorize(m)))
+	} else {
+		mux.Handle(metricsPath, authorize(m))
+	}
+	if reload != nil {
+		mux.Handle(reloadPath, authorize(reload))
+	}
+	mux.Handle(debugObjectPath, authorize(http.HandlerFunc(m.ServeObject)))
+	if stream != nil {
+		mux.Handle(streamPath, authorize(withoutWriteDeadline(stream)))
+	}
 
 	// Add healthzPath
 	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
@@ -242,5 +547,127 @@ func serveMetrics(ctx context.Context, kubeClient clientset.Interface, storeBuil
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+	var handler http.Handler = mux
+	if len(opts.SourceCIDRAllowlist) > 0 {
+		klog.Infof("Only allowing requests from %s", opts.SourceCIDRAllowlist.String())
+		handler = sourceCIDRAllowlist(opts.SourceCIDRAllowlist, handler)
+	}
+
+	server := newServer(listenAddresses[0], handler, opts)
+	if opts.ListenSocket != "" {
+		l, err := listenUnix(opts.ListenSocket)
+		if err != nil {
+			return err
+		}
+		return serve(ctx, server, opts.ServerEnableH2C, l)
+	}
+	return listenAndServe(ctx, server, opts.ServerEnableH2C, listenAddresses)
+}
+
+// joinHostsPort returns the addresses of the given port on each of the given
+// hosts.
+func joinHostsPort(hosts []string, port int) []string {
+	addrs := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+	return addrs
+}
+
+// newServer returns a server for the given address and handler with the
+// timeouts and limits given on the command line.
+func newServer(addr string, handler http.Handler, opts *options.Options) *http.Server {
+	return &http.Server{
+		Addr:           addr,
+		Handler:        handler,
+		ReadTimeout:    opts.ServerReadTimeout,
+		WriteTimeout:   opts.ServerWriteTimeout,
+		IdleTimeout:    opts.ServerIdleTimeout,
+		MaxHeaderBytes: opts.ServerMaxHeaderBytes,
+		ConnContext:    withConn,
+	}
+}
+
+// connKey is the context key of the connection a request was received on.
+type connKey struct{}
+
+// withConn returns the given context of a connection carrying the
+// connection, so that handlers can adjust its deadlines.
+func withConn(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// withoutWriteDeadline exempts the responses of the given handler from the
+// write timeout of the server, e.g. for long-lived streams, by clearing the
+// write deadline the server set on the connection before calling it. HTTP/2
+// connections are shared by concurrent requests, so their requests do not
+// carry the connection and remain subject to the write timeout.
+func withoutWriteDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, ok := r.Context().Value(connKey{}).(net.Conn); ok && r.ProtoMajor == 1 {
+			conn.SetWriteDeadline(time.Time{})
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listenUnix listens on the Unix domain socket at the given path, replacing
+// the socket left behind by a previous run.
+func listenUnix(path string) (net.Listener, error) {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, errors.Wrap(err, "remove stale socket")
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// listenAndServe runs the given server on the given TCP addresses until the
+// context is cancelled, and then gracefully shuts it down.
+func listenAndServe(ctx context.Context, server *http.Server, h2c bool, addrs []string) error {
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return err
+		}
+		listeners = append(listeners, l)
+	}
+	return serve(ctx, server, h2c, listeners...)
+}
+
+// serve runs the given server on the given listeners until the context is
+// cancelled, and then gracefully shuts it down. If h2c is set, HTTP/2 without
+// TLS is served as well.
+func serve(ctx context.Context, server *http.Server, h2c bool, listeners ...net.Listener) error {
+	if h2c {
+		h2 := &http2.Server{}
+		if err := http2.ConfigureServer(server, h2); err != nil {
+			return err
+		}
+		for i, l := range listeners {
+			listeners[i] = newH2CListener(l, server, h2)
+		}
+	}
+
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			errs <- server.Serve(l)
+		}(l)
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return server.Shutdown(shutdownCtx)
 }
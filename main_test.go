@@ -19,8 +19,15 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"io/ioutil"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -33,11 +40,13 @@ import (
 	"k8s.io/kube-state-metrics/pkg/options"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/http2"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 )
 
 func BenchmarkKubeStateMetrics(b *testing.B) {
@@ -173,15 +182,24 @@ kube_pod_labels{namespace="default",pod="pod0"} 1
 kube_pod_created{namespace="default",pod="pod0"} 1.5e+09
 # HELP kube_pod_deleted Unix deletion timestamp
 # TYPE kube_pod_deleted gauge
+# HELP kube_pod_deletion_grace_period_seconds The grace period in seconds a deleted pod is given to terminate, which is included in its deletion timestamp.
+# TYPE kube_pod_deletion_grace_period_seconds gauge
 # HELP kube_pod_restart_policy Describes the restart policy in use by this pod.
 # TYPE kube_pod_restart_policy gauge
 kube_pod_restart_policy{namespace="default",pod="pod0",type="Always"} 1
+# HELP kube_pod_spec_scheduler The scheduler responsible for scheduling the pod.
+# TYPE kube_pod_spec_scheduler gauge
+kube_pod_spec_scheduler{namespace="default",pod="pod0",scheduler=""} 1
+# HELP kube_pod_spec_termination_grace_period_seconds The duration in seconds the pod is given to terminate gracefully.
+# TYPE kube_pod_spec_termination_grace_period_seconds gauge
 # HELP kube_pod_status_scheduled_time Unix timestamp when pod moved into scheduled status
 # TYPE kube_pod_status_scheduled_time gauge
 # HELP kube_pod_status_phase The pods current phase.
 # TYPE kube_pod_status_phase gauge
 # HELP kube_pod_status_unschedulable Describes the unschedulable status for the pod.
 # TYPE kube_pod_status_unschedulable gauge
+# HELP kube_pod_status_unschedulable_since Unix timestamp since when the pod has been unschedulable.
+# TYPE kube_pod_status_unschedulable_since gauge
 kube_pod_status_phase{namespace="default",pod="pod0",phase="Pending"} 0
 kube_pod_status_phase{namespace="default",pod="pod0",phase="Succeeded"} 0
 kube_pod_status_phase{namespace="default",pod="pod0",phase="Failed"} 0
@@ -189,6 +207,8 @@ kube_pod_status_phase{namespace="default",pod="pod0",phase="Running"} 1
 kube_pod_status_phase{namespace="default",pod="pod0",phase="Unknown"} 0
 # HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
 # TYPE kube_pod_status_ready gauge
+# HELP kube_pod_status_ready_time Unix timestamp when pod moved into ready status.
+# TYPE kube_pod_status_ready_time gauge
 # HELP kube_pod_status_reason The pod status reasons
 # TYPE kube_pod_status_reason gauge
 kube_pod_status_reason{namespace="default",pod="pod0",reason="Evicted"} 0
@@ -197,8 +217,8 @@ kube_pod_status_reason{namespace="default",pod="pod0",reason="NodeLost"} 0
 # TYPE kube_pod_status_scheduled gauge
 # HELP kube_pod_container_info Information about a container in a pod.
 # TYPE kube_pod_container_info gauge
-kube_pod_container_info{namespace="default",pod="pod0",container="container2",image="k8s.gcr.io/hyperkube2",image_id="docker://sha256:bbb",container_id="docker://cd456"} 1
-kube_pod_container_info{namespace="default",pod="pod0",container="container3",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",container_id="docker://ef789"} 1
+kube_pod_container_info{namespace="default",pod="pod0",container="container2",image="k8s.gcr.io/hyperkube2",image_id="docker://sha256:bbb",image_digest="",image_pull_policy="",container_id="docker://cd456"} 1
+kube_pod_container_info{namespace="default",pod="pod0",container="container3",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",image_digest="",image_pull_policy="",container_id="docker://ef789"} 1
 # HELP kube_pod_init_container_info Information about an init container in a pod.
 # TYPE kube_pod_init_container_info gauge
 # HELP kube_pod_container_status_waiting Describes whether the container is currently in waiting state.
@@ -292,6 +312,8 @@ kube_pod_container_resource_requests{namespace="default",pod="pod0",container="p
 kube_pod_container_resource_requests{namespace="default",pod="pod0",container="pod1_con2",resource="memory",unit="byte"} 2e+08
 # HELP kube_pod_init_container_resource_limits The number of requested limit resource by the init container.
 # TYPE kube_pod_init_container_resource_limits gauge
+# HELP kube_pod_init_container_resource_requests The number of requested request resource by the init container.
+# TYPE kube_pod_init_container_resource_requests gauge
 # HELP kube_pod_container_resource_limits The number of requested limit resource by a container.
 # TYPE kube_pod_container_resource_limits gauge
 kube_pod_container_resource_limits{namespace="default",pod="pod0",container="pod1_con1",resource="nvidia_com_gpu",unit="integer"} 1
@@ -306,7 +328,9 @@ kube_pod_container_resource_limits{namespace="default",pod="pod0",container="pod
 # HELP kube_pod_spec_volumes_persistentvolumeclaims_readonly Describes whether a persistentvolumeclaim is mounted read only.
 # TYPE kube_pod_spec_volumes_persistentvolumeclaims_readonly gauge
 # HELP kube_pod_overhead The pod overhead associated with running a pod.
-# TYPE kube_pod_overhead gauge`
+# TYPE kube_pod_overhead gauge
+# HELP kube_pod_metadata_resource_version Resource version representing a specific version of the Pod.
+# TYPE kube_pod_metadata_resource_version gauge`
 
 	expectedSplit := strings.Split(strings.TrimSpace(expected), "\n")
 	sort.Strings(expectedSplit)
@@ -333,6 +357,270 @@ kube_pod_container_resource_limits{namespace="default",pod="pod0",container="pod
 	}
 }
 
+func TestConfigureTLS(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Opts    options.Options
+		Want    rest.TLSClientConfig
+		WantErr bool
+	}{
+		{
+			Desc: "no overrides",
+			Want: rest.TLSClientConfig{CAData: []byte("ca")},
+		},
+		{
+			Desc: "CA file",
+			Opts: options.Options{APIServerCAFile: "/etc/ca.pem"},
+			Want: rest.TLSClientConfig{CAFile: "/etc/ca.pem"},
+		},
+		{
+			Desc: "insecure",
+			Opts: options.Options{APIServerInsecureSkipVerify: true},
+			Want: rest.TLSClientConfig{Insecure: true},
+		},
+		{
+			Desc: "client certificate",
+			Opts: options.Options{APIServerClientCertFile: "/etc/cert.pem", APIServerClientKeyFile: "/etc/key.pem"},
+			Want: rest.TLSClientConfig{CAData: []byte("ca"), CertFile: "/etc/cert.pem", KeyFile: "/etc/key.pem"},
+		},
+		{
+			Desc:    "client certificate without key",
+			Opts:    options.Options{APIServerClientCertFile: "/etc/cert.pem"},
+			WantErr: true,
+		},
+		{
+			Desc:    "CA file and insecure",
+			Opts:    options.Options{APIServerCAFile: "/etc/ca.pem", APIServerInsecureSkipVerify: true},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		config := &rest.Config{TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")}}
+		err := configureTLS(config, &test.Opts)
+		if test.WantErr {
+			if err == nil {
+				t.Errorf("Test error for Desc: %s. Expected error", test.Desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(config.TLSClientConfig, test.Want) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Want, config.TLSClientConfig)
+		}
+	}
+}
+
+func TestProxyTransport(t *testing.T) {
+	var requested string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := &http.Transport{}
+	client := &http.Client{Transport: proxyTransport(proxyURL)(base)}
+	resp, err := client.Get("http://apiserver.invalid/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if requested != "http://apiserver.invalid/api" {
+		t.Fatalf("expected request to http://apiserver.invalid/api through proxy, got %q", requested)
+	}
+	if base.Proxy != nil {
+		t.Fatal("expected wrapped transport to be left unchanged")
+	}
+}
+
+func TestServeUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ksm-socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "ksm.sock")
+
+	// Leave a stale socket behind, as after a crash.
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := listenUnix(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serve(ctx, &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})}, false, l)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://unix/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" {
+		t.Fatalf("expected response ok, got %q", body)
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatalf("expected graceful shutdown, got %v", err)
+	}
+}
+
+func TestServeMultipleListeners(t *testing.T) {
+	if addrs := joinHostsPort([]string{"0.0.0.0", "::"}, 8080); !reflect.DeepEqual(addrs, []string{"0.0.0.0:8080", "[::]:8080"}) {
+		t.Fatalf("unexpected addresses %v", addrs)
+	}
+
+	var listeners []net.Listener
+	for i := 0; i < 2; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serve(ctx, &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})}, false, listeners...)
+	}()
+
+	for _, l := range listeners {
+		resp, err := http.Get("http://" + l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d on %s, got %d", http.StatusOK, l.Addr(), resp.StatusCode)
+		}
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatalf("expected graceful shutdown, got %v", err)
+	}
+}
+
+func TestServeH2C(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serve(ctx, &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		}), ReadTimeout: time.Minute}, true, l)
+	}()
+
+	h2c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+
+	for _, client := range []*http.Client{h2c, http.DefaultClient} {
+		resp, err := client.Get("http://" + l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != resp.Proto {
+			t.Fatalf("expected status %d served with %s, got %d served with %s", http.StatusOK, resp.Proto, resp.StatusCode, body)
+		}
+		if client == h2c && resp.ProtoMajor != 2 {
+			t.Fatalf("expected HTTP/2, got %s", resp.Proto)
+		}
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatalf("expected graceful shutdown, got %v", err)
+	}
+}
+
+func TestWithoutWriteDeadline(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/slow", slow)
+	mux.Handle("/stream", withoutWriteDeadline(slow))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serve(ctx, newServer("", mux, &options.Options{ServerWriteTimeout: 50 * time.Millisecond}), false, l)
+	}()
+
+	get := func(path string) error {
+		resp, err := http.Get("http://" + l.Addr().String() + path)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+	if err := get("/slow"); err == nil {
+		t.Fatal("expected the write timeout to cut off /slow")
+	}
+	if err := get("/stream"); err != nil {
+		t.Fatalf("expected /stream to be exempt from the write timeout, got %v", err)
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatalf("expected graceful shutdown, got %v", err)
+	}
+}
+
 // TestShardingEquivalenceScrapeCycle is a simple smoke test covering the entire cycle from
 // cache filling to scraping comparing a sharded with an unsharded setup.
 func TestShardingEquivalenceScrapeCycle(t *testing.T) {
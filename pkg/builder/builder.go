@@ -32,7 +32,7 @@ import (
 // Builder helps to build store. It follows the builder pattern
 // (https://en.wikipedia.org/wiki/Builder_pattern).
 type Builder struct {
-	internal ksmtypes.BuilderInterface
+	internal *internalstore.Builder
 }
 
 // NewBuilder returns a new builder.
@@ -84,6 +84,62 @@ func (b *Builder) WithAllowDenyList(l ksmtypes.AllowDenyLister) {
 	b.internal.WithAllowDenyList(l)
 }
 
+// WithOptInList configures which opt-in metrics are exposed by the store
+// build by the Builder.
+func (b *Builder) WithOptInList(l ksmtypes.OptInLister) {
+	b.internal.WithOptInList(l)
+}
+
+// WithAllowAnnotations configures which annotations are exposed as labels in
+// the annotations metric of each resource.
+func (b *Builder) WithAllowAnnotations(annotations map[string][]string) {
+	b.internal.WithAllowAnnotations(annotations)
+}
+
+// WithTrackUnscheduledPods configures whether pods that have not been
+// scheduled to a node yet are exposed by the pod collector.
+func (b *Builder) WithTrackUnscheduledPods(track bool) {
+	b.internal.WithTrackUnscheduledPods(track)
+}
+
+// WithOnlyCurrentConditionStatus configures whether condition metrics only
+// expose the series of the current status of a condition, instead of one
+// series for each of true, false and unknown.
+func (b *Builder) WithOnlyCurrentConditionStatus(only bool) {
+	b.internal.WithOnlyCurrentConditionStatus(only)
+}
+
+// WithUIDLabel configures whether info and created metrics carry the UID of
+// their object as uid label.
+func (b *Builder) WithUIDLabel(enabled bool) {
+	b.internal.WithUIDLabel(enabled)
+}
+
+// WithCustomLabels configures static labels which are added to every series.
+func (b *Builder) WithCustomLabels(labels map[string]string) {
+	b.internal.WithCustomLabels(labels)
+}
+
+// WithSampleTimestamps configures whether condition metrics expose the last
+// transition times of their conditions as sample timestamps.
+func (b *Builder) WithSampleTimestamps(enabled bool) {
+	b.internal.WithSampleTimestamps(enabled)
+}
+
+// WithLabelLimits configures the maximum number of labels and annotations of
+// an object exposed by the label and annotation metrics, and the maximum
+// length of their values. Longer values are truncated or, if hashValues is
+// set, replaced by their hash. Zero disables the respective limit.
+func (b *Builder) WithLabelLimits(maxCount, maxValueLength int, hashValues bool) {
+	b.internal.WithLabelLimits(maxCount, maxValueLength, hashValues)
+}
+
+// WithLabelNameScheme configures how the labels and annotations of objects
+// are named by the label and annotation metrics.
+func (b *Builder) WithLabelNameScheme(scheme string) error {
+	return b.internal.WithLabelNameScheme(scheme)
+}
+
 // WithGenerateStoreFunc configures a constom generate store function
 func (b *Builder) WithGenerateStoreFunc(f ksmtypes.BuildStoreFunc) {
 	b.internal.WithGenerateStoreFunc(f)
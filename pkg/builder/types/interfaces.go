@@ -38,6 +38,9 @@ type BuilderInterface interface {
 	WithKubeClient(c clientset.Interface)
 	WithVPAClient(c vpaclientset.Interface)
 	WithAllowDenyList(l AllowDenyLister)
+	WithOptInList(l OptInLister)
+	WithAllowAnnotations(annotations map[string][]string)
+	WithTrackUnscheduledPods(track bool)
 	WithGenerateStoreFunc(f BuildStoreFunc)
 	DefaultGenerateStoreFunc() BuildStoreFunc
 	Build() []cache.Store
@@ -54,3 +57,8 @@ type AllowDenyLister interface {
 	IsIncluded(string) bool
 	IsExcluded(string) bool
 }
+
+// OptInLister interface for opt-in lister that can enable opt-in metrics by there names
+type OptInLister interface {
+	IsOptedIn(string) bool
+}
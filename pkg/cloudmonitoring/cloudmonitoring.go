@@ -0,0 +1,394 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudmonitoring exports the metrics of kube-state-metrics to
+// Google Cloud Monitoring, formerly Stackdriver, for clusters without a
+// Prometheus deployment.
+package cloudmonitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+const (
+	// ResultSuccess is the result of exported series.
+	ResultSuccess = "success"
+	// ResultError is the result of series which failed to be exported.
+	ResultError = "error"
+	// ResultSkipped is the result of series which can not be represented
+	// in Cloud Monitoring, e.g. as they have too many labels, or which
+	// failed to parse.
+	ResultSkipped = "skipped"
+
+	// MinInterval is the minimum interval of exports, as Cloud Monitoring
+	// accepts at most one point of a time series every 5 seconds.
+	MinInterval = 5 * time.Second
+
+	// metricTypePrefix prefixes the metric types of the exported metric
+	// families, e.g. custom.googleapis.com/kube_state_metrics/kube_pod_info.
+	metricTypePrefix = "custom.googleapis.com/kube_state_metrics/"
+	// defaultEndpoint is the endpoint of the Cloud Monitoring API.
+	defaultEndpoint = "https://monitoring.googleapis.com/v3"
+	// maxSeriesPerRequest is the maximum number of time series Cloud
+	// Monitoring accepts in a single request.
+	maxSeriesPerRequest = 200
+	// maxLabels is the maximum number of labels of a custom metric.
+	maxLabels = 10
+	// maxLabelValueLength is the maximum length of a label value of a custom
+	// metric, in bytes.
+	maxLabelValueLength = 1024
+)
+
+// Metrics stores the pointer of the
+// kube_state_metrics_cloud_monitoring_series_total metric.
+type Metrics struct {
+	Series *prometheus.CounterVec
+}
+
+// NewMetrics takes in a prometheus registry and initializes and registers
+// the kube_state_metrics_cloud_monitoring_series_total metric. It returns the
+// registered metric.
+func NewMetrics(r *prometheus.Registry) *Metrics {
+	var m Metrics
+	m.Series = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_cloud_monitoring_series_total",
+			Help: "Number of series exported to Google Cloud Monitoring, by result",
+		},
+		[]string{"result"},
+	)
+	for _, result := range []string{ResultSuccess, ResultError, ResultSkipped} {
+		m.Series.WithLabelValues(result)
+	}
+	if r != nil {
+		r.MustRegister(m.Series)
+	}
+	return &m
+}
+
+// Config configures an Exporter. The project, location and cluster name are
+// read from the metadata server if empty.
+type Config struct {
+	Project     string
+	Location    string
+	ClusterName string
+	Interval    time.Duration
+}
+
+// Exporter periodically exports the metrics written by a source to Cloud
+// Monitoring.
+type Exporter struct {
+	config   Config
+	source   func(w io.Writer)
+	metrics  *Metrics
+	client   *http.Client
+	metadata *metadataClient
+	endpoint string
+	// start is the start time of the points of counters, which are exported
+	// as cumulative metrics.
+	start time.Time
+}
+
+// New returns an Exporter exporting the metrics written by the given source
+// in the Prometheus text format.
+func New(config Config, source func(w io.Writer), m *Metrics) *Exporter {
+	client := &http.Client{Timeout: time.Minute}
+	return &Exporter{
+		config:   config,
+		source:   source,
+		metrics:  m,
+		client:   client,
+		metadata: &metadataClient{url: defaultMetadataURL, client: client},
+		endpoint: defaultEndpoint,
+		start:    time.Now(),
+	}
+}
+
+// Run exports the metrics every interval until the given context is done.
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.export(ctx); err != nil {
+			klog.Errorf("Failed to export metrics to Cloud Monitoring: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolve reads the project, location and cluster name which were not
+// configured from the metadata server.
+func (e *Exporter) resolve(ctx context.Context) error {
+	for _, f := range []struct {
+		value *string
+		path  string
+	}{
+		{&e.config.Project, "project/project-id"},
+		{&e.config.Location, "instance/attributes/cluster-location"},
+		{&e.config.ClusterName, "instance/attributes/cluster-name"},
+	} {
+		if *f.value != "" {
+			continue
+		}
+		v, err := e.metadata.get(ctx, f.path)
+		if err != nil {
+			return err
+		}
+		*f.value = v
+	}
+	return nil
+}
+
+// export exports the current metrics of the source.
+func (e *Exporter) export(ctx context.Context) error {
+	if err := e.resolve(ctx); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	e.source(&buf)
+	samples, skipped, err := parse(&buf)
+	if err != nil {
+		return err
+	}
+	e.metrics.Series.WithLabelValues(ResultSkipped).Add(float64(skipped))
+
+	now := time.Now()
+	series := make([]timeSeries, 0, len(samples))
+	seen := make(map[string]bool, len(samples))
+	for _, s := range samples {
+		ts, ok := e.timeSeries(s, now)
+		if !ok {
+			e.metrics.Series.WithLabelValues(ResultSkipped).Inc()
+			continue
+		}
+		// Samples which only differ in labels dropped by the conversion
+		// would be rejected as duplicates.
+		key := ts.key()
+		if seen[key] {
+			e.metrics.Series.WithLabelValues(ResultSkipped).Inc()
+			continue
+		}
+		seen[key] = true
+		series = append(series, ts)
+	}
+
+	var errs []string
+	for len(series) > 0 {
+		n := maxSeriesPerRequest
+		if len(series) < n {
+			n = len(series)
+		}
+		if err := e.write(ctx, series[:n]); err != nil {
+			e.metrics.Series.WithLabelValues(ResultError).Add(float64(n))
+			errs = append(errs, err.Error())
+		} else {
+			e.metrics.Series.WithLabelValues(ResultSuccess).Add(float64(n))
+		}
+		series = series[n:]
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("failed to write %d of the requests: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// write writes the given time series to Cloud Monitoring.
+func (e *Exporter) write(ctx context.Context, series []timeSeries) error {
+	token, err := e.metadata.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(struct {
+		TimeSeries []timeSeries `json:"timeSeries"`
+	}{series})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/projects/%s/timeSeries", e.endpoint, e.config.Project), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// timeSeries is a time series of the Cloud Monitoring API.
+type timeSeries struct {
+	Metric     typedLabels `json:"metric"`
+	Resource   typedLabels `json:"resource"`
+	MetricKind string      `json:"metricKind"`
+	ValueType  string      `json:"valueType"`
+	Points     []point     `json:"points"`
+}
+
+type typedLabels struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type point struct {
+	Interval struct {
+		StartTime string `json:"startTime,omitempty"`
+		EndTime   string `json:"endTime"`
+	} `json:"interval"`
+	Value struct {
+		DoubleValue float64 `json:"doubleValue"`
+	} `json:"value"`
+}
+
+// key identifies the time series within a request.
+func (ts *timeSeries) key() string {
+	metricLabels, _ := json.Marshal(ts.Metric.Labels)
+	resourceLabels, _ := json.Marshal(ts.Resource.Labels)
+	return ts.Metric.Type + string(metricLabels) + ts.Resource.Type + string(resourceLabels)
+}
+
+// truncateLabelValue cuts the given label value to at most
+// maxLabelValueLength bytes, without cutting a multi-byte character in half.
+func truncateLabelValue(v string) string {
+	if len(v) <= maxLabelValueLength {
+		return v
+	}
+
+	max := maxLabelValueLength
+	for max > 0 && !utf8.RuneStart(v[max]) {
+		max--
+	}
+	return v[:max]
+}
+
+// timeSeries converts the given sample to a time series with a single point
+// at the given time. It returns false if the sample can not be represented
+// in Cloud Monitoring.
+//
+// Gauges are exported as GAUGE metrics and counters as CUMULATIVE metrics
+// starting at the start of the exporter. Samples of pods and containers are
+// attributed to the k8s_pod and k8s_container monitored resources, samples
+// of nodes to the k8s_node resource and all other samples to the k8s_cluster
+// resource. The labels identifying the monitored resource are removed from
+// the metric labels.
+func (e *Exporter) timeSeries(s sample, now time.Time) (timeSeries, bool) {
+	if math.IsNaN(s.value) || math.IsInf(s.value, 0) {
+		return timeSeries{}, false
+	}
+
+	labels := make(map[string]string, len(s.labels))
+	for k, v := range s.labels {
+		if v == "" {
+			continue
+		}
+		labels[k] = v
+	}
+
+	resource := typedLabels{
+		Type: "k8s_cluster",
+		Labels: map[string]string{
+			"project_id":   e.config.Project,
+			"location":     e.config.Location,
+			"cluster_name": e.config.ClusterName,
+		},
+	}
+	moveLabel := func(from, to string) {
+		resource.Labels[to] = labels[from]
+		delete(labels, from)
+	}
+	switch {
+	case strings.HasPrefix(s.name, "kube_pod_") && labels["namespace"] != "" && labels["pod"] != "":
+		resource.Type = "k8s_pod"
+		moveLabel("namespace", "namespace_name")
+		moveLabel("pod", "pod_name")
+		if labels["container"] != "" {
+			resource.Type = "k8s_container"
+			moveLabel("container", "container_name")
+		}
+	case strings.HasPrefix(s.name, "kube_node_") && labels["node"] != "":
+		resource.Type = "k8s_node"
+		moveLabel("node", "node_name")
+	}
+
+	if len(labels) > maxLabels {
+		return timeSeries{}, false
+	}
+	metricLabels := make(map[string]string, len(labels))
+	for k, v := range labels {
+		// Label keys of custom metrics must start with a lower case
+		// letter. Keys which only differ in case would be merged, so
+		// such series are skipped rather than silently losing a label.
+		k = strings.ToLower(k)
+		if k[0] < 'a' || k[0] > 'z' {
+			return timeSeries{}, false
+		}
+		if _, ok := metricLabels[k]; ok {
+			return timeSeries{}, false
+		}
+		metricLabels[k] = truncateLabelValue(v)
+	}
+
+	ts := timeSeries{
+		Metric:     typedLabels{Type: metricTypePrefix + s.name, Labels: metricLabels},
+		Resource:   resource,
+		MetricKind: "GAUGE",
+		ValueType:  "DOUBLE",
+		Points:     make([]point, 1),
+	}
+	p := &ts.Points[0]
+	p.Interval.EndTime = now.UTC().Format(time.RFC3339Nano)
+	p.Value.DoubleValue = s.value
+	if s.typ == metric.Counter {
+		ts.MetricKind = "CUMULATIVE"
+		p.Interval.StartTime = e.start.UTC().Format(time.RFC3339Nano)
+	}
+
+	return ts, true
+}
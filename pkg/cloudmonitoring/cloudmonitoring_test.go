@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestParse(t *testing.T) {
+	text := `# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{namespace="default",pod="pod1",node=""} 1
+# HELP kube_pod_container_status_restarts_total The number of container restarts per container.
+# TYPE kube_pod_container_status_restarts_total counter
+kube_pod_container_status_restarts_total{namespace="default",pod="pod1",container="c1"} 3
+kube_pod_labels{namespace="default",pod="pod1",label_a="b} \"c\"\\d"} 1 1600000000000
+`
+	samples, skipped, err := parse(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 0 {
+		t.Fatalf("expected no skipped samples, got %d", skipped)
+	}
+
+	expected := []sample{
+		{"kube_pod_info", metric.Gauge, map[string]string{"namespace": "default", "pod": "pod1", "node": ""}, 1},
+		{"kube_pod_container_status_restarts_total", metric.Counter, map[string]string{"namespace": "default", "pod": "pod1", "container": "c1"}, 3},
+		{"kube_pod_labels", metric.Gauge, map[string]string{"namespace": "default", "pod": "pod1", "label_a": `b} "c"\d`}, 1},
+	}
+	if !reflect.DeepEqual(samples, expected) {
+		t.Fatalf("expected samples %+v, got %+v", expected, samples)
+	}
+
+	// Malformed samples are skipped without affecting the other samples of
+	// their family.
+	for _, line := range []string{"kube_pod_info", `kube_pod_info{pod="pod1} 1`, "kube_pod_info one"} {
+		text := "# TYPE kube_pod_info gauge\nkube_pod_info{pod=\"pod0\"} 1\n" + line + "\nkube_pod_info{pod=\"pod2\"} 1\n"
+		samples, skipped, err := parse(strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(samples) != 2 || samples[0].labels["pod"] != "pod0" || samples[1].labels["pod"] != "pod2" || skipped != 1 {
+			t.Errorf("expected only %q to be skipped, got samples %+v and %d skipped", line, samples, skipped)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing Metadata-Flavor header", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/project/project-id":
+			io.WriteString(w, "my-project")
+		case "/instance/attributes/cluster-location":
+			io.WriteString(w, "europe-west1")
+		case "/instance/service-accounts/default/token":
+			io.WriteString(w, `{"access_token":"token1","expires_in":3600,"token_type":"Bearer"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer metadata.Close()
+
+	var written []timeSeries
+	monitoring := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/my-project/timeSeries" || r.Header.Get("Authorization") != "Bearer token1" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var body struct {
+			TimeSeries []timeSeries `json:"timeSeries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		written = append(written, body.TimeSeries...)
+	}))
+	defer monitoring.Close()
+
+	source := func(w io.Writer) {
+		io.WriteString(w, `# TYPE kube_node_info gauge
+kube_node_info{node="node1",kernel_version="5.4"} 1
+# TYPE kube_pod_container_status_restarts_total counter
+kube_pod_container_status_restarts_total{namespace="default",pod="pod1",container="c1"} 3
+# TYPE kube_namespace_created gauge
+kube_namespace_created{namespace="default"} 1.6e+09
+kube_namespace_created{namespace="broken} 1
+# TYPE kube_pod_labels gauge
+kube_pod_labels{namespace="default",pod="pod1",label_1="a",label_2="b",label_3="c",label_4="d",label_5="e",label_6="f",label_7="g",label_8="h",label_9="i",label_10="j",label_11="k"} 1
+kube_pod_labels{namespace="default",pod="pod2",label_App="a",label_app="b"} 1
+`)
+	}
+
+	m := NewMetrics(prometheus.NewRegistry())
+	e := New(Config{ClusterName: "my-cluster", Interval: time.Minute}, source, m)
+	e.metadata.url = metadata.URL
+	e.endpoint = monitoring.URL
+
+	if err := e.export(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(written) != 3 {
+		t.Fatalf("expected 3 time series, got %d: %+v", len(written), written)
+	}
+
+	cluster := map[string]string{"project_id": "my-project", "location": "europe-west1", "cluster_name": "my-cluster"}
+	withCluster := func(labels map[string]string) map[string]string {
+		for k, v := range cluster {
+			labels[k] = v
+		}
+		return labels
+	}
+	expected := []struct {
+		metric   typedLabels
+		resource typedLabels
+		kind     string
+		value    float64
+	}{
+		{
+			typedLabels{"custom.googleapis.com/kube_state_metrics/kube_node_info", map[string]string{"kernel_version": "5.4"}},
+			typedLabels{"k8s_node", withCluster(map[string]string{"node_name": "node1"})},
+			"GAUGE", 1,
+		},
+		{
+			typedLabels{"custom.googleapis.com/kube_state_metrics/kube_pod_container_status_restarts_total", nil},
+			typedLabels{"k8s_container", withCluster(map[string]string{"namespace_name": "default", "pod_name": "pod1", "container_name": "c1"})},
+			"CUMULATIVE", 3,
+		},
+		{
+			typedLabels{"custom.googleapis.com/kube_state_metrics/kube_namespace_created", map[string]string{"namespace": "default"}},
+			typedLabels{"k8s_cluster", cluster},
+			"GAUGE", 1.6e9,
+		},
+	}
+	for i, want := range expected {
+		got := written[i]
+		if !reflect.DeepEqual(got.Metric, want.metric) || !reflect.DeepEqual(got.Resource, want.resource) || got.MetricKind != want.kind || got.Points[0].Value.DoubleValue != want.value {
+			t.Errorf("%d: unexpected time series %+v", i, got)
+		}
+		if (got.Points[0].Interval.StartTime != "") != (want.kind == "CUMULATIVE") {
+			t.Errorf("%d: unexpected interval %+v", i, got.Points[0].Interval)
+		}
+	}
+
+	for result, want := range map[string]float64{ResultSuccess: 3, ResultSkipped: 3, ResultError: 0} {
+		if v := counterValue(t, m.Series.WithLabelValues(result)); v != want {
+			t.Errorf("expected %v series with result %s, got %v", want, result, v)
+		}
+	}
+}
+
+func counterValue(t *testing.T, m prometheus.Metric) float64 {
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		t.Fatal(err)
+	}
+	return pb.GetCounter().GetValue()
+}
+
+func TestTruncateLabelValue(t *testing.T) {
+	ascii := strings.Repeat("a", maxLabelValueLength)
+	tests := []struct {
+		value, expected string
+	}{
+		{ascii, ascii},
+		{ascii + "b", ascii},
+		// The two-byte character at the boundary is left out as a whole.
+		{ascii[1:] + "ä", ascii[1:]},
+		{ascii[2:] + "ä", ascii[2:] + "ä"},
+		// So are three-byte characters overlapping the boundary by either
+		// one or two bytes.
+		{ascii[1:] + "€", ascii[1:]},
+		{ascii[2:] + "€", ascii[2:]},
+	}
+	for i, test := range tests {
+		got := truncateLabelValue(test.value)
+		if got != test.expected {
+			t.Errorf("%d: expected value of %d bytes, got %d bytes", i, len(test.expected), len(got))
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%d: expected valid UTF-8, got %q", i, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultMetadataURL is the URL of the metadata server of GCE and GKE,
+	// including GKE Workload Identity.
+	defaultMetadataURL = "http://metadata.google.internal/computeMetadata/v1"
+
+	// tokenExpiryMargin is the time before the expiry of an access token at
+	// which it is refreshed.
+	tokenExpiryMargin = time.Minute
+)
+
+// metadataClient reads the project, cluster and access tokens of the service
+// account of kube-state-metrics from the metadata server.
+type metadataClient struct {
+	url    string
+	client *http.Client
+
+	mtx    sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// get returns the value of the given path of the metadata server, e.g.
+// project/project-id.
+func (c *metadataClient) get(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, c.url+"/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", errors.Wrapf(err, "get %s from metadata server", path)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "get %s from metadata server", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("get %s from metadata server: unexpected status %s", path, resp.Status)
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
+
+// accessToken returns an access token of the default service account,
+// refreshing it shortly before it expires.
+func (c *metadataClient) accessToken(ctx context.Context) (string, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiry) {
+		return c.token, nil
+	}
+
+	body, err := c.get(ctx, "instance/service-accounts/default/token")
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal([]byte(body), &token); err != nil {
+		return "", errors.Wrap(err, "parse access token")
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("metadata server returned empty access token")
+	}
+
+	c.token = token.AccessToken
+	c.expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return c.token, nil
+}
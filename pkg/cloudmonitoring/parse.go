@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudmonitoring
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+// sample is a sample parsed from the Prometheus text format.
+type sample struct {
+	name   string
+	typ    metric.Type
+	labels map[string]string
+	value  float64
+}
+
+// parse parses the samples written by kube-state-metrics in the Prometheus
+// text format with expfmt. Samples of families without a TYPE line are
+// gauges. The timestamps of samples are ignored. Samples which fail to
+// parse, or which are neither gauges nor counters, are skipped and counted,
+// so that a single malformed series does not abort the whole export.
+func parse(r io.Reader) (samples []sample, skipped int, err error) {
+	samples = []sample{}
+
+	// The text is parsed family by family, i.e. in blocks of comment lines
+	// followed by the samples of the family.
+	var comments, lines []string
+	flush := func() {
+		s, n := parseFamily(comments, lines)
+		samples = append(samples, s...)
+		skipped += n
+		comments, lines = nil, nil
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, readErr := br.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			if len(lines) > 0 {
+				flush()
+			}
+			comments = append(comments, line)
+		default:
+			lines = append(lines, line)
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, 0, readErr
+		}
+	}
+	flush()
+
+	return samples, skipped, nil
+}
+
+// parseFamily parses the given sample lines of a metric family preceded by
+// the given comment lines. If the family fails to parse, its samples are
+// parsed one by one to skip only the malformed ones.
+func parseFamily(comments, lines []string) ([]sample, int) {
+	if len(lines) == 0 {
+		return nil, 0
+	}
+
+	text := strings.Join(append(append([]string{}, comments...), lines...), "\n") + "\n"
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(text))
+	if err != nil {
+		if len(lines) == 1 {
+			klog.Errorf("Skipping sample %q which failed to parse: %v", lines[0], err)
+			return nil, 1
+		}
+
+		samples, skipped := []sample{}, 0
+		for _, line := range lines {
+			s, n := parseFamily(comments, []string{line})
+			samples = append(samples, s...)
+			skipped += n
+		}
+		return samples, skipped
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	samples, skipped := []sample{}, 0
+	for _, name := range names {
+		f := families[name]
+		for _, m := range f.GetMetric() {
+			s, ok := newSample(name, f.GetType(), m)
+			if !ok {
+				skipped++
+				continue
+			}
+			samples = append(samples, s)
+		}
+	}
+	return samples, skipped
+}
+
+// newSample returns the sample of the given metric of the metric family with
+// the given name and type. It returns false unless the metric is a gauge or a
+// counter.
+func newSample(name string, typ dto.MetricType, m *dto.Metric) (sample, bool) {
+	s := sample{
+		name:   name,
+		labels: make(map[string]string, len(m.GetLabel())),
+	}
+	for _, l := range m.GetLabel() {
+		s.labels[l.GetName()] = l.GetValue()
+	}
+
+	switch typ {
+	case dto.MetricType_GAUGE:
+		s.typ, s.value = metric.Gauge, m.GetGauge().GetValue()
+	case dto.MetricType_UNTYPED:
+		s.typ, s.value = metric.Gauge, m.GetUntyped().GetValue()
+	case dto.MetricType_COUNTER:
+		s.typ, s.value = metric.Counter, m.GetCounter().GetValue()
+	default:
+		return sample{}, false
+	}
+	return s, true
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials instruments the credentials kube-state-metrics
+// authenticates to the apiserver with, so that failures to refresh them are
+// exposed as self metrics instead of only being logged.
+package credentials
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/oauth2"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+const (
+	// ReasonTokenFile is the reason of failures to read a bearer token
+	// file, e.g. a rotated service account token.
+	ReasonTokenFile = "token_file"
+	// ReasonExec is the reason of failures of an exec credential plugin.
+	ReasonExec = "exec"
+	// ReasonUnauthorized is the reason of requests the apiserver rejected
+	// the credentials of.
+	ReasonUnauthorized = "unauthorized"
+
+	// tokenFilePeriod is the time after which a bearer token file is read
+	// again. It matches the period of client-go, which is half of the time
+	// between the kubelet refreshing a projected service account token and
+	// the expiry of the previous token.
+	tokenFilePeriod = time.Minute
+)
+
+// Metrics stores the pointer of the
+// kube_state_metrics_apiserver_credential_failures_total metric.
+type Metrics struct {
+	Failures *prometheus.CounterVec
+}
+
+// NewMetrics takes in a prometheus registry and initializes and registers
+// the kube_state_metrics_apiserver_credential_failures_total metric. It
+// returns the registered metric.
+func NewMetrics(r *prometheus.Registry) *Metrics {
+	var m Metrics
+	m.Failures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_apiserver_credential_failures_total",
+			Help: "Number of failures to refresh or use the credentials kube-state-metrics authenticates to the apiserver with, by reason",
+		},
+		[]string{"reason"},
+	)
+	for _, reason := range []string{ReasonTokenFile, ReasonExec, ReasonUnauthorized} {
+		m.Failures.WithLabelValues(reason)
+	}
+	if r != nil {
+		r.MustRegister(m.Failures)
+	}
+	return &m
+}
+
+// InstrumentConfig returns a copy of the given config whose credential
+// failures are counted by the given metrics. The bearer token file of the
+// config, if any, is read again every minute, so that rotated tokens are
+// picked up without a restart.
+func InstrumentConfig(config *rest.Config, m *Metrics) (*rest.Config, error) {
+	config = rest.CopyConfig(config)
+	if config.BearerTokenFile != "" {
+		// The token source of client-go caches the token and keeps using
+		// the previous one if the file can not be read, which would hide
+		// the failures of its own file token source.
+		ts := transport.NewCachedTokenSource(&tokenFileSource{path: config.BearerTokenFile, metrics: m})
+		if _, err := ts.Token(); err != nil {
+			return nil, err
+		}
+		config.Wrap(transport.TokenSourceWrapTransport(ts))
+		config.BearerToken = ""
+		config.BearerTokenFile = ""
+	}
+
+	// The exec credential plugin wraps the transport outside of
+	// WrapTransport, so requests failing before reaching WrapTransport
+	// failed to get credentials from the plugin.
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &sentRoundTripper{rt: rt}
+	})
+	rt, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	instrumented := rest.AnonymousClientConfig(config)
+	instrumented.TLSClientConfig = rest.TLSClientConfig{}
+	instrumented.Dial = nil
+	instrumented.Transport = &roundTripper{rt: rt, exec: config.ExecProvider != nil, metrics: m}
+	return instrumented, nil
+}
+
+// tokenFileSource reads a bearer token from a file, counting failures to read
+// it.
+type tokenFileSource struct {
+	path    string
+	metrics *Metrics
+}
+
+// Token implements the oauth2.TokenSource interface. The token expires after
+// tokenFilePeriod, so that the file is read again.
+func (s *tokenFileSource) Token() (*oauth2.Token, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if err == nil && len(strings.TrimSpace(string(b))) == 0 {
+		err = errors.New("token file is empty")
+	}
+	if err != nil {
+		s.metrics.Failures.WithLabelValues(ReasonTokenFile).Inc()
+		return nil, errors.Wrapf(err, "read token file %s", s.path)
+	}
+
+	return &oauth2.Token{
+		AccessToken: strings.TrimSpace(string(b)),
+		Expiry:      time.Now().Add(tokenFilePeriod),
+	}, nil
+}
+
+// sentKey is the context key of the flag sentRoundTripper sets on the
+// requests it sends.
+type sentKey struct{}
+
+// sentRoundTripper flags the requests passing it to the wrapped round
+// tripper as sent.
+type sentRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (t *sentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if sent, ok := req.Context().Value(sentKey{}).(*bool); ok {
+		*sent = true
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// WrappedRoundTripper returns the wrapped round tripper, so that client-go
+// can cancel requests.
+func (t *sentRoundTripper) WrappedRoundTripper() http.RoundTripper { return t.rt }
+
+// roundTripper counts the failures of the credentials of the requests of
+// the wrapped round tripper.
+type roundTripper struct {
+	rt      http.RoundTripper
+	exec    bool
+	metrics *Metrics
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	sent := false
+	resp, err := t.rt.RoundTrip(req.WithContext(context.WithValue(req.Context(), sentKey{}, &sent)))
+	if err != nil {
+		// client-go does not return typed errors of exec credential
+		// plugins, but they fail before the request is sent.
+		if t.exec && !sent {
+			t.metrics.Failures.WithLabelValues(ReasonExec).Inc()
+		}
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		t.metrics.Failures.WithLabelValues(ReasonUnauthorized).Inc()
+	}
+	return resp, nil
+}
+
+// WrappedRoundTripper returns the wrapped round tripper, so that client-go
+// can cancel requests.
+func (t *roundTripper) WrappedRoundTripper() http.RoundTripper { return t.rt }
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestInstrumentConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ksm-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(path, []byte("token1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	m := NewMetrics(prometheus.NewRegistry())
+
+	config, err := InstrumentConfig(&rest.Config{Host: server.URL, BearerTokenFile: path}, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt, err := rest.TransportFor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if authorization != "Bearer token1" {
+		t.Fatalf("expected request authenticated with token1, got %q", authorization)
+	}
+	if v := counterValue(t, m.Failures.WithLabelValues(ReasonUnauthorized)); v != 1 {
+		t.Fatalf("expected 1 unauthorized request, got %v", v)
+	}
+
+	config, err = InstrumentConfig(&rest.Config{Host: server.URL, ExecProvider: &clientcmdapi.ExecConfig{
+		Command:    "false",
+		APIVersion: "client.authentication.k8s.io/v1beta1",
+	}}, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt, err = rest.TransportFor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (&http.Client{Transport: rt}).Get(server.URL); err == nil {
+		t.Fatal("expected error of failing exec credential plugin")
+	}
+	if v := counterValue(t, m.Failures.WithLabelValues(ReasonExec)); v != 1 {
+		t.Fatalf("expected 1 exec failure, got %v", v)
+	}
+}
+
+func TestTokenFileSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ksm-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	m := NewMetrics(prometheus.NewRegistry())
+	s := &tokenFileSource{path: path, metrics: m}
+
+	if _, err := s.Token(); err == nil {
+		t.Fatal("expected error for missing token file")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("token1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.Token()
+	if err != nil || token.AccessToken != "token1" {
+		t.Fatalf("expected token1, got %v, %v", token, err)
+	}
+	if !token.Expiry.After(time.Now()) {
+		t.Fatalf("expected token to expire in the future, got %s", token.Expiry)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Token(); err == nil {
+		t.Fatal("expected error for empty token file")
+	}
+
+	if v := counterValue(t, m.Failures.WithLabelValues(ReasonTokenFile)); v != 2 {
+		t.Fatalf("expected 2 token file failures, got %v", v)
+	}
+}
+
+func counterValue(t *testing.T, m prometheus.Metric) float64 {
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		t.Fatal(err)
+	}
+	return pb.GetCounter().GetValue()
+}
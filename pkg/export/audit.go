@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// Value classes of AuditRecord.Changed. Changes of values within the same
+// class, e.g. of a counter going up, are not audited.
+const (
+	ValueClassZero     = "zero"
+	ValueClassPositive = "positive"
+	ValueClassNegative = "negative"
+	ValueClassInf      = "inf"
+	ValueClassNaN      = "nan"
+)
+
+// AuditRecord is the JSON record logged by an Auditor for every change of the
+// metrics of an object in which series appeared, disappeared or changed
+// their value class.
+type AuditRecord struct {
+	Timestamp   time.Time     `json:"timestamp"`
+	Event       string        `json:"event"`
+	Kind        string        `json:"kind,omitempty"`
+	Namespace   string        `json:"namespace,omitempty"`
+	Name        string        `json:"name,omitempty"`
+	UID         types.UID     `json:"uid,omitempty"`
+	Appeared    []Series      `json:"appeared,omitempty"`
+	Disappeared []Series      `json:"disappeared,omitempty"`
+	Changed     []ClassChange `json:"changed,omitempty"`
+}
+
+// ClassChange is a series whose value changed its class, e.g. from zero to
+// positive.
+type ClassChange struct {
+	Series   string `json:"series"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+	OldClass string `json:"oldClass"`
+	NewClass string `json:"newClass"`
+}
+
+// Auditor logs the changes of the metrics of objects as structured JSON
+// records, e.g. to debug flapping metrics or to validate changes of
+// collectors. Only changes of the value class of series are logged, so that
+// counters and resource quantities going up do not flood the log, while
+// conditions flapping between 0 and 1 still stand out. As every object is
+// logged once when it is listed on startup, it is meant for debugging and
+// staging clusters rather than large clusters.
+type Auditor struct {
+	now func() time.Time
+	log func(record []byte)
+}
+
+// NewAuditor returns a new Auditor logging its records with klog.
+func NewAuditor() *Auditor {
+	return &Auditor{
+		now: time.Now,
+		log: func(record []byte) { klog.Infof("Metric audit: %s", record) },
+	}
+}
+
+// OnChange logs a record of the given change of the metrics of an object of
+// the given kind, unless no series appeared, disappeared or changed its
+// value class.
+func (a *Auditor) OnChange(kind string, c metricsstore.Change) {
+	record := AuditRecord{
+		Timestamp: a.now().UTC(),
+		Kind:      kind,
+		Namespace: c.Namespace,
+		Name:      c.Name,
+		UID:       c.UID,
+	}
+	switch {
+	case c.Old == nil:
+		record.Event = EventAdded
+	case c.New == nil:
+		record.Event = EventDeleted
+	default:
+		record.Event = EventUpdated
+	}
+
+	oldSeries := parseSeries(c.Old)
+	newSeries := parseSeries(c.New)
+	for _, s := range newSeries.order {
+		newValue := newSeries.values[s]
+		oldValue, ok := oldSeries.values[s]
+		if !ok {
+			record.Appeared = append(record.Appeared, Series{Series: s, Value: newValue})
+			continue
+		}
+		if oldClass, newClass := valueClass(oldValue), valueClass(newValue); oldClass != newClass {
+			record.Changed = append(record.Changed, ClassChange{
+				Series:   s,
+				OldValue: oldValue,
+				NewValue: newValue,
+				OldClass: oldClass,
+				NewClass: newClass,
+			})
+		}
+	}
+	for _, s := range oldSeries.order {
+		if _, ok := newSeries.values[s]; !ok {
+			record.Disappeared = append(record.Disappeared, Series{Series: s, Value: oldSeries.values[s]})
+		}
+	}
+
+	if len(record.Appeared) == 0 && len(record.Disappeared) == 0 && len(record.Changed) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		klog.Errorf("Failed to encode audit record of %s %s/%s: %v", kind, c.Namespace, c.Name, err)
+		return
+	}
+	a.log(data)
+}
+
+// valueClass returns the class of the given value of a sample, which may be
+// followed by its timestamp.
+func valueClass(value string) string {
+	if i := strings.IndexByte(value, ' '); i >= 0 {
+		value = value[:i]
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	switch {
+	case err != nil || math.IsNaN(v):
+		return ValueClassNaN
+	case math.IsInf(v, 0):
+		return ValueClassInf
+	case v == 0:
+		return ValueClassZero
+	case v > 0:
+		return ValueClassPositive
+	default:
+		return ValueClassNegative
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestAuditor(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := [][]byte{}
+	a := &Auditor{
+		now: func() time.Time { return now },
+		log: func(record []byte) { records = append(records, record) },
+	}
+
+	old := [][]byte{
+		[]byte("kube_pod_info{pod=\"pod1\",node=\"node1\"} 1\n"),
+		[]byte("kube_pod_container_status_restarts_total{pod=\"pod1\"} 3\nkube_pod_status_ready{pod=\"pod1\",condition=\"true\"} 1\n"),
+	}
+	new := [][]byte{
+		[]byte("kube_pod_info{pod=\"pod1\",node=\"node2\"} 1\n"),
+		[]byte("kube_pod_container_status_restarts_total{pod=\"pod1\"} 4\nkube_pod_status_ready{pod=\"pod1\",condition=\"true\"} 0\n"),
+	}
+	restarted := [][]byte{
+		[]byte("kube_pod_info{pod=\"pod1\",node=\"node2\"} 1\n"),
+		[]byte("kube_pod_container_status_restarts_total{pod=\"pod1\"} 5\nkube_pod_status_ready{pod=\"pod1\",condition=\"true\"} 0\n"),
+	}
+
+	a.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", Old: old, New: new})
+	// Changes of values within their class are not audited.
+	a.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", Old: new, New: restarted})
+
+	expected := []AuditRecord{
+		{
+			Timestamp:   now,
+			Event:       EventUpdated,
+			Kind:        "Pod",
+			Namespace:   "default",
+			Name:        "pod1",
+			UID:         "uid1",
+			Appeared:    []Series{{`kube_pod_info{pod="pod1",node="node2"}`, "1"}},
+			Disappeared: []Series{{`kube_pod_info{pod="pod1",node="node1"}`, "1"}},
+			Changed: []ClassChange{{
+				Series:   `kube_pod_status_ready{pod="pod1",condition="true"}`,
+				OldValue: "1",
+				NewValue: "0",
+				OldClass: ValueClassPositive,
+				NewClass: ValueClassZero,
+			}},
+		},
+	}
+
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(records))
+	}
+	for i, want := range expected {
+		var got AuditRecord
+		if err := json.Unmarshal(records[i], &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: expected record %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestValueClass(t *testing.T) {
+	for value, want := range map[string]string{
+		"0":               ValueClassZero,
+		"-0":              ValueClassZero,
+		"1.5e+09":         ValueClassPositive,
+		"-1":              ValueClassNegative,
+		"+Inf":            ValueClassInf,
+		"NaN":             ValueClassNaN,
+		"1 1577836800000": ValueClassPositive,
+	} {
+		if got := valueClass(value); got != want {
+			t.Errorf("expected class %s of value %q, got %s", want, value, got)
+		}
+	}
+}
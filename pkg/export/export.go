@@ -0,0 +1,406 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export publishes the changes of the metrics of objects as JSON
+// messages to a message broker, so that systems such as asset inventories
+// can consume the changes of the state of a cluster without polling
+// /metrics.
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+const (
+	// EventAdded is the event of messages of objects which were added.
+	EventAdded = "added"
+	// EventUpdated is the event of messages of objects whose metrics
+	// changed.
+	EventUpdated = "updated"
+	// EventDeleted is the event of messages of objects which were deleted.
+	EventDeleted = "deleted"
+	// EventSynced is the event of the message marking the end of the
+	// snapshot of the objects sent to new subscribers of a Stream.
+	EventSynced = "synced"
+
+	// ResultSuccess is the result of published messages.
+	ResultSuccess = "success"
+	// ResultError is the result of messages which failed to be published.
+	ResultError = "error"
+	// ResultDropped is the result of messages which were dropped as the
+	// queue of messages was full.
+	ResultDropped = "dropped"
+
+	// queueSize is the number of messages which are buffered while the
+	// broker is slow or unavailable.
+	queueSize = 10000
+	// retryInterval is the time waited before reconnecting to the broker
+	// after a failure.
+	retryInterval = 5 * time.Second
+)
+
+// Message is the JSON message published for every change of the metrics of
+// an object.
+type Message struct {
+	Timestamp time.Time `json:"timestamp"`
+	Event     string    `json:"event"`
+	Kind      string    `json:"kind,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	UID       types.UID `json:"uid,omitempty"`
+	Added     []Series  `json:"added,omitempty"`
+	Updated   []Series  `json:"updated,omitempty"`
+	Deleted   []Series  `json:"deleted,omitempty"`
+}
+
+// Series is a series in the Prometheus text format, e.g.
+// kube_pod_info{namespace="default",pod="pod1"}, and its value. The value
+// may be followed by the timestamp of the sample.
+type Series struct {
+	Series string `json:"series"`
+	Value  string `json:"value"`
+}
+
+// Metrics stores the pointer of the
+// kube_state_metrics_export_messages_total metric.
+type Metrics struct {
+	Messages *prometheus.CounterVec
+}
+
+// NewMetrics takes in a prometheus registry and initializes and registers
+// the kube_state_metrics_export_messages_total metric. It returns the
+// registered metric.
+func NewMetrics(r *prometheus.Registry) *Metrics {
+	var m Metrics
+	m.Messages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_export_messages_total",
+			Help: "Number of messages of changes of the metrics of objects exported to the message broker, by result",
+		},
+		[]string{"result"},
+	)
+	for _, result := range []string{ResultSuccess, ResultError, ResultDropped} {
+		m.Messages.WithLabelValues(result)
+	}
+	if r != nil {
+		r.MustRegister(m.Messages)
+	}
+	return &m
+}
+
+// publisher publishes messages to a message broker.
+type publisher interface {
+	// publish publishes the given message, connecting to the broker if
+	// necessary.
+	publish(data []byte) error
+	// close closes the connection to the broker, if any.
+	close()
+}
+
+// Exporter publishes the changes of the metrics of objects reported to
+// OnChange to a message broker.
+type Exporter struct {
+	publisher publisher
+	metrics   *Metrics
+	queue     chan []byte
+	now       func() time.Time
+
+	// mtx protects objects.
+	mtx     sync.Mutex
+	objects objectSet
+}
+
+// New returns an Exporter publishing to the broker at the given URL, e.g.
+// nats://nats.example.com:4222, or tls://nats.example.com:4222 to require
+// TLS. Messages are published to the given subject.
+func New(brokerURL, subject string, m *Metrics) (*Exporter, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse broker URL")
+	}
+
+	var p publisher
+	switch u.Scheme {
+	case "nats", "tls":
+		p, err = newNATSPublisher(u, subject)
+		if err != nil {
+			return nil, err
+		}
+	case "kafka":
+		return nil, errors.New("exporting to Kafka is not supported yet, use NATS instead")
+	default:
+		return nil, errors.Errorf("unsupported broker URL scheme %q, expected nats or tls", u.Scheme)
+	}
+
+	return newExporter(p, m), nil
+}
+
+func newExporter(p publisher, m *Metrics) *Exporter {
+	return &Exporter{
+		publisher: p,
+		metrics:   m,
+		queue:     make(chan []byte, queueSize),
+		now:       time.Now,
+		objects:   objectSet{},
+	}
+}
+
+// OnChange queues a message of the given change of the metrics of an object
+// of the given kind. Changes which did not change any series are ignored. If
+// the queue is full, the message is dropped.
+func (e *Exporter) OnChange(kind string, c metricsstore.Change) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	e.objects.update(kind, c)
+	if data, ok := encodeMessage(e.now(), kind, c); ok {
+		e.enqueue(data)
+	}
+}
+
+// OnRebuild queues deleted messages of all objects, as the stores reporting
+// their changes are rebuilt, e.g. after resharding or a reload of the
+// config. The objects which are still present are added again by the new
+// stores.
+func (e *Exporter) OnRebuild() {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	for _, data := range e.objects.reset(e.now()) {
+		e.enqueue(data)
+	}
+}
+
+// enqueue queues the given message. If the queue is full, the message is
+// dropped.
+func (e *Exporter) enqueue(data []byte) {
+	select {
+	case e.queue <- data:
+	default:
+		e.metrics.Messages.WithLabelValues(ResultDropped).Inc()
+	}
+}
+
+// Run publishes the queued messages until the given context is done. A
+// message which fails to be published is retried after reconnecting to the
+// broker.
+func (e *Exporter) Run(ctx context.Context) {
+	defer e.publisher.close()
+
+	for {
+		var data []byte
+		select {
+		case <-ctx.Done():
+			return
+		case data = <-e.queue:
+		}
+
+		for {
+			err := e.publisher.publish(data)
+			if err == nil {
+				e.metrics.Messages.WithLabelValues(ResultSuccess).Inc()
+				break
+			}
+			e.metrics.Messages.WithLabelValues(ResultError).Inc()
+			klog.Errorf("Failed to publish message, retrying in %s: %v", retryInterval, err)
+			e.publisher.close()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+}
+
+// objectSet holds the current metrics of the objects reported by the stores
+// by their UIDs, so that they can be reported as deleted once the stores are
+// rebuilt. The metric families are shared with the stores, which never
+// modify them.
+type objectSet map[types.UID]exportedObject
+
+type exportedObject struct {
+	kind      string
+	namespace string
+	name      string
+	families  [][]byte
+}
+
+// update records the given change of the metrics of an object of the given
+// kind.
+func (o objectSet) update(kind string, c metricsstore.Change) {
+	if c.New == nil {
+		delete(o, c.UID)
+		return
+	}
+	o[c.UID] = exportedObject{kind: kind, namespace: c.Namespace, name: c.Name, families: c.New}
+}
+
+// reset removes all objects, and returns the deleted messages of the ones
+// with any series.
+func (o objectSet) reset(now time.Time) [][]byte {
+	deleted := make([][]byte, 0, len(o))
+	for uid, obj := range o {
+		if data, ok := encodeMessage(now, obj.kind, metricsstore.Change{UID: uid, Namespace: obj.namespace, Name: obj.name, Old: obj.families}); ok {
+			deleted = append(deleted, data)
+		}
+		delete(o, uid)
+	}
+	return deleted
+}
+
+// encodeMessage returns the JSON message of the given change of the metrics
+// of an object of the given kind. It returns false if the change did not
+// change any series.
+func encodeMessage(now time.Time, kind string, c metricsstore.Change) ([]byte, bool) {
+	msg := Message{
+		Timestamp: now.UTC(),
+		Kind:      kind,
+		Namespace: c.Namespace,
+		Name:      c.Name,
+		UID:       c.UID,
+	}
+	switch {
+	case c.Old == nil:
+		msg.Event = EventAdded
+	case c.New == nil:
+		msg.Event = EventDeleted
+	default:
+		msg.Event = EventUpdated
+	}
+
+	msg.Added, msg.Updated, msg.Deleted = diff(c.Old, c.New)
+	if len(msg.Added) == 0 && len(msg.Updated) == 0 && len(msg.Deleted) == 0 {
+		return nil, false
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		klog.Errorf("Failed to encode message of %s %s/%s: %v", kind, c.Namespace, c.Name, err)
+		return nil, false
+	}
+	return data, true
+}
+
+// diff returns the series which were added, whose value changed and which
+// were deleted between the given metric families.
+func diff(old, new [][]byte) (added, updated, deleted []Series) {
+	oldSeries := parseSeries(old)
+	newSeries := parseSeries(new)
+
+	for _, s := range newSeries.order {
+		oldValue, ok := oldSeries.values[s]
+		switch {
+		case !ok:
+			added = append(added, Series{Series: s, Value: newSeries.values[s]})
+		case oldValue != newSeries.values[s]:
+			updated = append(updated, Series{Series: s, Value: newSeries.values[s]})
+		}
+	}
+	for _, s := range oldSeries.order {
+		if _, ok := newSeries.values[s]; !ok {
+			deleted = append(deleted, Series{Series: s, Value: oldSeries.values[s]})
+		}
+	}
+
+	return added, updated, deleted
+}
+
+// seriesSet holds the values of series, along with the order the series were
+// parsed in.
+type seriesSet struct {
+	order  []string
+	values map[string]string
+}
+
+// parseSeries parses the series of the given metric families in the
+// Prometheus text format. Metric families which fail to parse are skipped.
+func parseSeries(families [][]byte) seriesSet {
+	set := seriesSet{values: map[string]string{}}
+	for _, f := range families {
+		var parser expfmt.TextParser
+		parsed, err := parser.TextToMetricFamilies(bytes.NewReader(f))
+		if err != nil {
+			klog.Errorf("Failed to parse metric family %q: %v", f, err)
+			continue
+		}
+
+		names := make([]string, 0, len(parsed))
+		for name := range parsed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, m := range parsed[name].GetMetric() {
+				series, value := formatSeries(name, m)
+				if _, ok := set.values[series]; !ok {
+					set.order = append(set.order, series)
+				}
+				set.values[series] = value
+			}
+		}
+	}
+	return set
+}
+
+// labelValueEscaper escapes label values in the Prometheus text format.
+var labelValueEscaper = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`)
+
+// formatSeries returns the series of the given metric of the metric family
+// with the given name in the Prometheus text format, with its labels in the
+// order they were parsed in, and its value, followed by its timestamp if any.
+func formatSeries(name string, m *dto.Metric) (series, value string) {
+	var b strings.Builder
+	b.WriteString(name)
+	for i, l := range m.GetLabel() {
+		if i == 0 {
+			b.WriteByte('{')
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.GetName())
+		b.WriteString(`="`)
+		labelValueEscaper.WriteString(&b, l.GetValue())
+		b.WriteByte('"')
+	}
+	if len(m.GetLabel()) > 0 {
+		b.WriteByte('}')
+	}
+
+	value = strconv.FormatFloat(m.GetUntyped().GetValue(), 'g', -1, 64)
+	if m.TimestampMs != nil {
+		value += " " + strconv.FormatInt(m.GetTimestampMs(), 10)
+	}
+	return b.String(), value
+}
@@ -0,0 +1,393 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestParseSeries(t *testing.T) {
+	families := [][]byte{
+		[]byte("kube_pod_info 1\n"),
+		[]byte("kube_pod_labels{label_a=\"b} c\",label_d=\"e\\\"} f\"} 1 1600000000000\n"),
+		[]byte("kube_pod_broken{pod=\"pod1\" 1\n"),
+		[]byte("kube_pod_status_ready{pod=\"pod1\",condition=\"true\"} 0.5\nkube_pod_status_ready{pod=\"pod1\",condition=\"false\"} NaN\n"),
+	}
+
+	// Malformed metric families are skipped.
+	expected := seriesSet{
+		order: []string{
+			`kube_pod_info`,
+			`kube_pod_labels{label_a="b} c",label_d="e\"} f"}`,
+			`kube_pod_status_ready{pod="pod1",condition="true"}`,
+			`kube_pod_status_ready{pod="pod1",condition="false"}`,
+		},
+		values: map[string]string{
+			`kube_pod_info`: `1`,
+			`kube_pod_labels{label_a="b} c",label_d="e\"} f"}`:    `1 1600000000000`,
+			`kube_pod_status_ready{pod="pod1",condition="true"}`:  `0.5`,
+			`kube_pod_status_ready{pod="pod1",condition="false"}`: `NaN`,
+		},
+	}
+
+	if got := parseSeries(families); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	e := newExporter(nil, NewMetrics(prometheus.NewRegistry()))
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+
+	old := [][]byte{
+		[]byte("kube_pod_info{pod=\"pod1\",node=\"node1\"} 1\n"),
+		[]byte("kube_pod_status_ready{pod=\"pod1\",condition=\"true\"} 1\nkube_pod_status_ready{pod=\"pod1\",condition=\"false\"} 0\n"),
+	}
+	new := [][]byte{
+		[]byte("kube_pod_info{pod=\"pod1\",node=\"node2\"} 1\n"),
+		[]byte("kube_pod_status_ready{pod=\"pod1\",condition=\"true\"} 0\nkube_pod_status_ready{pod=\"pod1\",condition=\"false\"} 0\n"),
+	}
+
+	e.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", Old: old, New: new})
+	e.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", Old: new, New: new})
+	e.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", Old: new})
+
+	expected := []Message{
+		{
+			Timestamp: now,
+			Event:     EventUpdated,
+			Kind:      "Pod",
+			Namespace: "default",
+			Name:      "pod1",
+			UID:       "uid1",
+			Added:     []Series{{`kube_pod_info{pod="pod1",node="node2"}`, "1"}},
+			Updated:   []Series{{`kube_pod_status_ready{pod="pod1",condition="true"}`, "0"}},
+			Deleted:   []Series{{`kube_pod_info{pod="pod1",node="node1"}`, "1"}},
+		},
+		{
+			Timestamp: now,
+			Event:     EventDeleted,
+			Kind:      "Pod",
+			Namespace: "default",
+			Name:      "pod1",
+			UID:       "uid1",
+			Deleted: []Series{
+				{`kube_pod_info{pod="pod1",node="node2"}`, "1"},
+				{`kube_pod_status_ready{pod="pod1",condition="true"}`, "0"},
+				{`kube_pod_status_ready{pod="pod1",condition="false"}`, "0"},
+			},
+		},
+	}
+
+	if len(e.queue) != len(expected) {
+		t.Fatalf("expected %d messages, got %d", len(expected), len(e.queue))
+	}
+	for i, want := range expected {
+		var got Message
+		if err := json.Unmarshal(<-e.queue, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: expected message %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestOnRebuild(t *testing.T) {
+	e := newExporter(nil, NewMetrics(prometheus.NewRegistry()))
+
+	pod1 := [][]byte{[]byte("kube_pod_info{pod=\"pod1\"} 1\n")}
+	e.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", New: pod1})
+	e.OnChange("Pod", metricsstore.Change{UID: "uid2", Namespace: "default", Name: "pod2", New: [][]byte{[]byte("kube_pod_info{pod=\"pod2\"} 1\n")}})
+	e.OnChange("Pod", metricsstore.Change{UID: "uid2", Namespace: "default", Name: "pod2"})
+	for len(e.queue) > 0 {
+		<-e.queue
+	}
+
+	// Only the objects present when the stores are rebuilt are deleted.
+	e.OnRebuild()
+	if len(e.queue) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(e.queue))
+	}
+	var msg Message
+	if err := json.Unmarshal(<-e.queue, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Event != EventDeleted || msg.UID != "uid1" || len(msg.Deleted) != 1 {
+		t.Fatalf("expected pod1 to be deleted, got %+v", msg)
+	}
+
+	e.OnRebuild()
+	if len(e.queue) != 0 {
+		t.Fatalf("expected no messages after a second rebuild, got %d", len(e.queue))
+	}
+}
+
+func TestNATSExporter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		io.WriteString(conn, "INFO {\"max_payload\":1048576}\r\n")
+		r := bufio.NewReader(conn)
+		connect, err := r.ReadString('\n')
+		if err != nil || !strings.HasPrefix(connect, "CONNECT ") || !strings.Contains(connect, `"auth_token":"secret"`) {
+			t.Errorf("expected CONNECT with token, got %q, %v", connect, err)
+			return
+		}
+
+		// The exporter has to answer pings to keep the connection open.
+		io.WriteString(conn, "PING\r\n")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Errorf("failed to read from exporter: %v", err)
+				return
+			}
+			if strings.TrimSpace(line) == "PONG" {
+				continue
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) != 3 || fields[0] != "PUB" || fields[1] != "kube-state-metrics" {
+				t.Errorf("expected PUB to subject kube-state-metrics, got %q", line)
+				return
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				t.Error(err)
+				return
+			}
+			received <- string(payload[:n])
+			return
+		}
+	}()
+
+	m := NewMetrics(prometheus.NewRegistry())
+	e, err := New("nats://secret@"+l.Addr().String(), "kube-state-metrics", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx)
+
+	e.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", New: [][]byte{[]byte("kube_pod_info{pod=\"pod1\"} 1\n")}})
+
+	select {
+	case payload := <-received:
+		var msg Message
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Event != EventAdded || msg.Name != "pod1" || len(msg.Added) != 1 {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+// serveNATS accepts connections on the given listener as a NATS server,
+// requiring TLS if tlsConfig is set, and sends the payloads published on them
+// to received.
+func serveNATS(t *testing.T, l net.Listener, tlsConfig *tls.Config, received chan<- string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+
+			fmt.Fprintf(conn, "INFO {\"max_payload\":1048576,\"tls_required\":%t}\r\n", tlsConfig != nil)
+			if tlsConfig != nil {
+				conn = tls.Server(conn, tlsConfig)
+			}
+			r := bufio.NewReader(conn)
+			if connect, err := r.ReadString('\n'); err != nil || !strings.HasPrefix(connect, "CONNECT ") {
+				t.Errorf("expected CONNECT, got %q, %v", connect, err)
+				return
+			}
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				fields := strings.Fields(line)
+				if len(fields) != 3 || fields[0] != "PUB" {
+					t.Errorf("expected PUB, got %q", line)
+					return
+				}
+				n, err := strconv.Atoi(fields[2])
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				payload := make([]byte, n+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					t.Error(err)
+					return
+				}
+				received <- string(payload[:n])
+			}
+		}(conn)
+	}
+}
+
+func TestNATSPublisherTLS(t *testing.T) {
+	// Borrow the certificate of an httptest server, which is valid for
+	// 127.0.0.1.
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := make(chan string, 1)
+	go serveNATS(t, l, certServer.TLS, received)
+
+	u, err := url.Parse("nats://" + l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := newNATSPublisher(u, "kube-state-metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.close()
+	p.tlsConfig.RootCAs = certServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	if err := p.publish([]byte("message")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case payload := <-received:
+		if payload != "message" {
+			t.Fatalf("expected payload %q, got %q", "message", payload)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+// failingConn is a connection whose writes fail.
+type failingConn struct {
+	net.Conn
+	closed bool
+}
+
+func (c *failingConn) Write(b []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func (c *failingConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *failingConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNATSPublisherReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := make(chan string, 1)
+	go serveNATS(t, l, nil, received)
+
+	u, err := url.Parse("nats://" + l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := newNATSPublisher(u, "kube-state-metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.close()
+
+	broken := &failingConn{}
+	p.conn = broken
+	if err := p.publish([]byte("lost")); err == nil {
+		t.Fatal("expected publishing on a broken connection to fail")
+	}
+	if !broken.closed || p.conn != nil {
+		t.Fatal("expected the broken connection to be closed and dropped")
+	}
+
+	if err := p.publish([]byte("message")); err != nil {
+		t.Fatalf("expected to publish on a new connection, got %v", err)
+	}
+	select {
+	case payload := <-received:
+		if payload != "message" {
+			t.Fatalf("expected payload %q, got %q", "message", payload)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func TestNewUnsupportedBroker(t *testing.T) {
+	for _, u := range []string{"kafka://kafka:9092", "http://nats:4222", "nats://"} {
+		if _, err := New(u, "kube-state-metrics", NewMetrics(nil)); err == nil {
+			t.Errorf("expected error for broker URL %s", u)
+		}
+	}
+}
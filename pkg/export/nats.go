@@ -0,0 +1,220 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+const (
+	// natsDefaultPort is the port of NATS servers if the URL does not
+	// contain one.
+	natsDefaultPort = "4222"
+	// natsTimeout bounds connecting to the NATS server and writing to it.
+	natsTimeout = 10 * time.Second
+)
+
+// natsInfo holds the fields of the INFO message of a NATS server which are
+// relevant to publishing.
+type natsInfo struct {
+	MaxPayload  int  `json:"max_payload"`
+	TLSRequired bool `json:"tls_required"`
+}
+
+// natsConnect is the CONNECT message sent to a NATS server.
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+// natsPublisher publishes messages to a subject of a NATS server, using the
+// text based core protocol of NATS. Messages are published at most once, as
+// in core NATS. Connections are upgraded to TLS if the server requires it or
+// the URL has the tls scheme.
+type natsPublisher struct {
+	addr       string
+	subject    string
+	connect    natsConnect
+	requireTLS bool
+	tlsConfig  *tls.Config
+
+	mtx        sync.Mutex
+	conn       net.Conn
+	maxPayload int
+}
+
+func newNATSPublisher(u *url.URL, subject string) (*natsPublisher, error) {
+	if u.Hostname() == "" {
+		return nil, errors.New("NATS URL has no host")
+	}
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, errors.Errorf("invalid NATS subject %q", subject)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+
+	p := &natsPublisher{
+		addr:       net.JoinHostPort(u.Hostname(), port),
+		subject:    subject,
+		connect:    natsConnect{Name: "kube-state-metrics", Lang: "go"},
+		requireTLS: u.Scheme == "tls",
+		tlsConfig:  &tls.Config{ServerName: u.Hostname()},
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			p.connect.User, p.connect.Pass = u.User.Username(), pass
+		} else {
+			p.connect.Token = u.User.Username()
+		}
+	}
+	return p, nil
+}
+
+func (p *natsPublisher) publish(data []byte) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.conn == nil {
+		if err := p.dial(); err != nil {
+			return errors.Wrapf(err, "connect to NATS server %s", p.addr)
+		}
+	}
+	if p.maxPayload > 0 && len(data) > p.maxPayload {
+		klog.Errorf("Dropping message of %d bytes exceeding the maximum payload of the NATS server of %d bytes", len(data), p.maxPayload)
+		return nil
+	}
+
+	p.conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+	if _, err := fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\n", p.subject, len(data), data); err != nil {
+		// The server discards the connection after a partial write, so
+		// the next message is published on a new one.
+		p.conn.Close()
+		p.conn = nil
+		return errors.Wrap(err, "publish to NATS server")
+	}
+	return nil
+}
+
+// dial connects to the NATS server. p.mtx must be held.
+func (p *natsPublisher) dial() error {
+	conn, err := net.DialTimeout("tcp", p.addr, natsTimeout)
+	if err != nil {
+		return err
+	}
+
+	conn.SetDeadline(time.Now().Add(natsTimeout))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "read INFO")
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return errors.Errorf("expected INFO, got %q", strings.TrimSpace(line))
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		conn.Close()
+		return errors.Wrap(err, "parse INFO")
+	}
+	if info.TLSRequired || p.requireTLS {
+		tlsConn := tls.Client(conn, p.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return errors.Wrap(err, "TLS handshake")
+		}
+		conn, r = tlsConn, bufio.NewReader(tlsConn)
+	}
+
+	connect, err := json.Marshal(p.connect)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", connect); err != nil {
+		conn.Close()
+		return errors.Wrap(err, "send CONNECT")
+	}
+	conn.SetDeadline(time.Time{})
+
+	p.conn = conn
+	p.maxPayload = info.MaxPayload
+	go p.readLoop(conn, r)
+	return nil
+}
+
+// readLoop answers the pings of the NATS server on the given connection, so
+// that the server keeps it open, and logs the errors it reports. It closes
+// the connection once reading from it fails.
+func (p *natsPublisher) readLoop(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			p.mtx.Lock()
+			if p.conn == conn {
+				klog.Errorf("Connection to NATS server %s failed: %v", p.addr, err)
+				p.conn.Close()
+				p.conn = nil
+			}
+			p.mtx.Unlock()
+			return
+		}
+
+		switch line = strings.TrimSpace(line); {
+		case line == "PING":
+			p.mtx.Lock()
+			conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+			_, err = conn.Write([]byte("PONG\r\n"))
+			p.mtx.Unlock()
+			if err != nil {
+				klog.Errorf("Failed to answer ping of NATS server %s: %v", p.addr, err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			klog.Errorf("NATS server %s reported error: %s", p.addr, strings.TrimPrefix(line, "-ERR "))
+		}
+	}
+}
+
+func (p *natsPublisher) close() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
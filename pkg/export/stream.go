@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// subscriberBufferSize is the number of messages buffered for a subscriber
+// of a Stream. Subscribers falling further behind are disconnected.
+const subscriberBufferSize = 10000
+
+// Stream streams the metrics of objects to HTTP clients as newline delimited
+// JSON messages. Every subscriber first receives a snapshot of the metrics of
+// all objects as added messages, followed by a synced message, and then the
+// changes of the metrics of objects as they happen.
+type Stream struct {
+	now func() time.Time
+
+	// mtx protects objects and subscribers.
+	mtx sync.Mutex
+	// objects holds the current metrics of every object, which the
+	// snapshots of new subscribers are built from.
+	objects     objectSet
+	subscribers map[chan []byte]struct{}
+}
+
+// NewStream returns a new Stream without subscribers.
+func NewStream() *Stream {
+	return &Stream{
+		now:         time.Now,
+		objects:     objectSet{},
+		subscribers: map[chan []byte]struct{}{},
+	}
+}
+
+// OnChange sends a message of the given change of the metrics of an object
+// of the given kind to all subscribers.
+func (s *Stream) OnChange(kind string, c metricsstore.Change) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.objects.update(kind, c)
+	if data, ok := encodeMessage(s.now(), kind, c); ok {
+		s.send(data)
+	}
+}
+
+// OnRebuild sends deleted messages of all objects to all subscribers, as the
+// stores reporting their changes are rebuilt, e.g. after resharding or a
+// reload of the config. The objects which are still present are added again
+// by the new stores.
+func (s *Stream) OnRebuild() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, data := range s.objects.reset(s.now()) {
+		s.send(data)
+	}
+}
+
+// send sends the given message to all subscribers, disconnecting the ones
+// falling behind. s.mtx must be held.
+func (s *Stream) send(data []byte) {
+	data = append(data, '\n')
+	for sub := range s.subscribers {
+		select {
+		case sub <- data:
+		default:
+			klog.Warning("Disconnecting stream subscriber falling behind")
+			close(sub)
+			delete(s.subscribers, sub)
+		}
+	}
+}
+
+// subscribe returns a snapshot of the metrics of all objects and the channel
+// of the messages of later changes. The channel is closed if the subscriber
+// falls behind.
+func (s *Stream) subscribe() ([][]byte, chan []byte) {
+	sub := make(chan []byte, subscriberBufferSize)
+
+	// Only copy the objects while holding the lock, so that encoding the
+	// snapshot does not block changes.
+	s.mtx.Lock()
+	now := s.now()
+	objects := make(objectSet, len(s.objects))
+	for uid, o := range s.objects {
+		objects[uid] = o
+	}
+	s.subscribers[sub] = struct{}{}
+	s.mtx.Unlock()
+
+	snapshot := make([][]byte, 0, len(objects)+1)
+	for uid, o := range objects {
+		if data, ok := encodeMessage(now, o.kind, metricsstore.Change{UID: uid, Namespace: o.namespace, Name: o.name, New: o.families}); ok {
+			snapshot = append(snapshot, append(data, '\n'))
+		}
+	}
+	synced, _ := json.Marshal(Message{Timestamp: now.UTC(), Event: EventSynced})
+	snapshot = append(snapshot, append(synced, '\n'))
+
+	return snapshot, sub
+}
+
+func (s *Stream) unsubscribe(sub chan []byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		close(sub)
+		delete(s.subscribers, sub)
+	}
+}
+
+// ServeHTTP implements the http.Handler interface. It streams the messages
+// to the client until the client disconnects or falls behind.
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	snapshot, sub := s.subscribe()
+	defer s.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	for _, data := range snapshot {
+		if !writeMessage(w, data) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data, ok := <-sub:
+			if !ok {
+				return
+			}
+			if !writeMessage(w, data) {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeMessage writes the given newline terminated message. It returns false
+// if the write failed, e.g. as the client disconnected.
+func writeMessage(w http.ResponseWriter, data []byte) bool {
+	if _, err := w.Write(data); err != nil {
+		return false
+	}
+	return true
+}
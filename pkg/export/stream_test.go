@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestStream(t *testing.T) {
+	s := NewStream()
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	pod1 := [][]byte{[]byte("kube_pod_info{pod=\"pod1\"} 1\n")}
+	s.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", New: pod1})
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("expected content type application/x-ndjson, got %q", ct)
+	}
+	r := bufio.NewReader(resp.Body)
+
+	next := func() Message {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	// The snapshot holds the objects added before subscribing.
+	if msg := next(); msg.Event != EventAdded || msg.Name != "pod1" || len(msg.Added) != 1 {
+		t.Fatalf("expected snapshot of pod1, got %+v", msg)
+	}
+	if msg := next(); msg.Event != EventSynced {
+		t.Fatalf("expected synced message, got %+v", msg)
+	}
+
+	// Rebuilding the stores deletes all objects, and the new stores add the
+	// ones still present again.
+	s.OnRebuild()
+	if msg := next(); msg.Event != EventDeleted || msg.Name != "pod1" || len(msg.Deleted) != 1 {
+		t.Fatalf("expected pod1 to be deleted on rebuild, got %+v", msg)
+	}
+	s.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", New: pod1})
+	if msg := next(); msg.Event != EventAdded || msg.Name != "pod1" {
+		t.Fatalf("expected pod1 to be added again, got %+v", msg)
+	}
+	s.OnChange("Pod", metricsstore.Change{UID: "uid2", Namespace: "default", Name: "pod2", New: [][]byte{[]byte("kube_pod_info{pod=\"pod2\"} 1\n")}})
+	if msg := next(); msg.Event != EventAdded || msg.Name != "pod2" {
+		t.Fatalf("expected pod2 to be added, got %+v", msg)
+	}
+
+	s.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", Old: pod1})
+	if msg := next(); msg.Event != EventDeleted || msg.Name != "pod1" || len(msg.Deleted) != 1 {
+		t.Fatalf("expected pod1 to be deleted, got %+v", msg)
+	}
+}
+
+func TestStreamRebuild(t *testing.T) {
+	s := NewStream()
+	s.OnChange("Pod", metricsstore.Change{UID: "uid1", Namespace: "default", Name: "pod1", New: [][]byte{[]byte("kube_pod_info{pod=\"pod1\"} 1\n")}})
+	s.OnRebuild()
+
+	// Objects which are not added again by the rebuilt stores are not part
+	// of the snapshots of new subscribers.
+	snapshot, sub := s.subscribe()
+	defer s.unsubscribe(sub)
+	if len(snapshot) != 1 {
+		t.Fatalf("expected snapshot with only the synced message, got %q", snapshot)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// filterListerWatcher implements cache.ListerWatcher, wrapping a
+// cache.ListerWatcher and only passing on the objects accepted by a function.
+type filterListerWatcher struct {
+	keep func(metav1.Object) bool
+	next cache.ListerWatcher
+}
+
+// NewFilterListerWatcher returns a cache.ListerWatcher wrapping the given next
+// cache.ListerWatcher, which only lists and watches the objects for which the
+// given keep function returns true. Updates of objects which are no longer
+// kept are passed on as deletions.
+func NewFilterListerWatcher(keep func(metav1.Object) bool, next cache.ListerWatcher) cache.ListerWatcher {
+	return &filterListerWatcher{
+		keep: keep,
+		next: next,
+	}
+}
+
+// List lists the wrapped next listerwatcher List result, but filtering the
+// objects which are not kept from the result.
+func (w *filterListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	list, err := w.next.List(options)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	metaObj, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+
+	l := metav1.List{}
+	for _, obj := range objs {
+		acc, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		if w.keep(acc) {
+			l.Items = append(l.Items, runtime.RawExtension{Object: obj})
+		}
+	}
+
+	l.ListMeta.ResourceVersion = metaObj.GetResourceVersion()
+	return &l, nil
+}
+
+// Watch watches the wrapped next listerwatcher, dropping the additions of
+// objects which are not kept and turning the modifications of such objects
+// into deletions, so that objects which are no longer kept are removed.
+func (w *filterListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	nextWatch, err := w.next.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(nextWatch, func(in watch.Event) (watch.Event, bool) {
+		acc, err := meta.Accessor(in.Object)
+		if err != nil {
+			return in, true
+		}
+		if w.keep(acc) {
+			return in, true
+		}
+
+		switch in.Type {
+		case watch.Added:
+			return in, false
+		case watch.Modified:
+			in.Type = watch.Deleted
+		}
+		return in, true
+	}), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestFilterListerWatcher(t *testing.T) {
+	newPod := func(name, scrape string) *v1.Pod {
+		p := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1"}}
+		if scrape != "" {
+			p.Annotations = map[string]string{"metrics.example.com/scrape": scrape}
+		}
+		return p
+	}
+
+	fake := watch.NewFake()
+	keep := func(o metav1.Object) bool {
+		return o.GetAnnotations()["metrics.example.com/scrape"] == "true"
+	}
+	lw := NewFilterListerWatcher(keep, &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return &v1.PodList{
+				ListMeta: metav1.ListMeta{ResourceVersion: "10"},
+				Items:    []v1.Pod{*newPod("pod1", "true"), *newPod("pod2", "false"), *newPod("pod3", "")},
+			}, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return fake, nil
+		},
+	})
+
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].(*v1.Pod).Name != "pod1" {
+		t.Fatalf("expected only pod1 to be listed, got %v", items)
+	}
+	if rv, _ := meta.NewAccessor().ResourceVersion(list); rv != "10" {
+		t.Fatalf("expected resource version 10, got %q", rv)
+	}
+
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	go func() {
+		fake.Add(newPod("pod4", ""))
+		fake.Add(newPod("pod5", "true"))
+		fake.Modify(newPod("pod1", "false"))
+		fake.Delete(newPod("pod6", ""))
+	}()
+
+	for _, want := range []struct {
+		eventType watch.EventType
+		name      string
+	}{
+		{watch.Added, "pod5"},
+		{watch.Deleted, "pod1"},
+		{watch.Deleted, "pod6"},
+	} {
+		select {
+		case event := <-w.ResultChan():
+			if event.Type != want.eventType || event.Object.(*v1.Pod).Name != want.name {
+				t.Fatalf("expected %s event of %s, got %s event of %s", want.eventType, want.name, event.Type, event.Object.(*v1.Pod).Name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s event of %s", want.eventType, want.name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// WatchNamespaceSelector watches the namespaces matching the given label
+// selector and calls update with their names whenever namespaces gain or
+// lose the selected labels, until ctx is done. It returns once the matching
+// namespaces have been listed and passed to update for the first time. The
+// namespaces only select namespaced objects; cluster-scoped objects like
+// nodes are listed regardless of them.
+func WatchNamespaceSelector(ctx context.Context, kubeClient clientset.Interface, selector labels.Selector, update func([]string)) error {
+	lw := &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.LabelSelector = selector.String()
+			return kubeClient.CoreV1().Namespaces().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.LabelSelector = selector.String()
+			return kubeClient.CoreV1().Namespaces().Watch(opts)
+		},
+	}
+
+	var store cache.Store
+	updateNamespaces := func() {
+		namespaces := store.ListKeys()
+		sort.Strings(namespaces)
+		klog.V(2).Infof("Namespaces matching label selector %q: %v", selector, namespaces)
+		update(namespaces)
+	}
+
+	store, informer := cache.NewInformer(lw, &v1.Namespace{}, 0, cache.ResourceEventHandlerFuncs{
+		// Namespaces gaining or losing the selected labels are added to or
+		// deleted from the watch results.
+		AddFunc: func(interface{}) {
+			updateNamespaces()
+		},
+		DeleteFunc: func(interface{}) {
+			updateNamespaces()
+		},
+	})
+
+	go informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return errors.New("waiting for namespace informer cache to sync failed")
+	}
+	updateNamespaces()
+
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatchNamespaceSelector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"team": "payments"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", Labels: map[string]string{"team": "other"}}},
+	)
+
+	updates := make(chan []string, 10)
+	err := WatchNamespaceSelector(ctx, client, labels.SelectorFromSet(labels.Set{"team": "payments"}), func(namespaces []string) {
+		updates <- namespaces
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(want []string) {
+		t.Helper()
+		select {
+		case got := <-updates:
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected namespaces %v, got %v", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected namespaces %v, got no update", want)
+		}
+	}
+
+	// The informer reports the listed namespace before the initial update.
+	expect([]string{"ns1"})
+	expect([]string{"ns1"})
+
+	if _, err := client.CoreV1().Namespaces().Create(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns3", Labels: map[string]string{"team": "payments"}}}); err != nil {
+		t.Fatal(err)
+	}
+	expect([]string{"ns1", "ns3"})
+}
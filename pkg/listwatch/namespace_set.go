@@ -0,0 +1,209 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// NamespaceSet holds a set of namespaces which can be changed at runtime.
+type NamespaceSet struct {
+	mtx        sync.RWMutex
+	namespaces []string
+	// changed is closed and replaced once the namespaces change.
+	changed chan struct{}
+}
+
+// NewNamespaceSet returns a new NamespaceSet holding the given namespaces.
+func NewNamespaceSet(namespaces []string) *NamespaceSet {
+	return &NamespaceSet{
+		namespaces: sortedNamespaces(namespaces),
+		changed:    make(chan struct{}),
+	}
+}
+
+// Set replaces the namespaces of the set. Lister watchers created with
+// DynamicMultiNamespaceListerWatcher end their watches once the namespaces
+// changed, so that their reflectors list the new namespaces.
+func (s *NamespaceSet) Set(namespaces []string) {
+	namespaces = sortedNamespaces(namespaces)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if equalNamespaces(s.namespaces, namespaces) {
+		return
+	}
+
+	s.namespaces = namespaces
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Get returns the current namespaces of the set.
+func (s *NamespaceSet) Get() []string {
+	namespaces, _ := s.get()
+	return namespaces
+}
+
+// get returns the current namespaces of the set and a chan which is closed
+// once they change.
+func (s *NamespaceSet) get() ([]string, <-chan struct{}) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.namespaces, s.changed
+}
+
+func sortedNamespaces(namespaces []string) []string {
+	sorted := append([]string{}, namespaces...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func equalNamespaces(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DynamicMultiNamespaceListerWatcher is like MultiNamespaceListerWatcher, but
+// follows the namespaces of the given NamespaceSet. Once they change, the
+// current watch ends with an expired error, making the reflector list the new
+// namespaces. The store of the reflector then drops the objects of removed
+// namespaces on Replace.
+func DynamicMultiNamespaceListerWatcher(namespaces *NamespaceSet, deniedNamespaces []string, f func(string) cache.ListerWatcher) cache.ListerWatcher {
+	return &dynamicListerWatcher{
+		namespaces:       namespaces,
+		deniedNamespaces: deniedNamespaces,
+		f:                f,
+	}
+}
+
+// dynamicListerWatcher implements cache.ListerWatcher for the namespaces of
+// a NamespaceSet.
+type dynamicListerWatcher struct {
+	namespaces       *NamespaceSet
+	deniedNamespaces []string
+	f                func(string) cache.ListerWatcher
+
+	// mtx protects lw and changed, the lister watcher of the namespaces of
+	// the last List call and the chan closed once they change.
+	mtx     sync.Mutex
+	lw      cache.ListerWatcher
+	changed <-chan struct{}
+}
+
+// List implements the ListerWatcher interface.
+// It lists the current namespaces of the set.
+func (d *dynamicListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	namespaces, changed := d.namespaces.get()
+
+	var lw cache.ListerWatcher
+	if len(namespaces) > 0 {
+		lw = MultiNamespaceListerWatcher(namespaces, d.deniedNamespaces, d.f)
+	}
+
+	d.mtx.Lock()
+	d.lw, d.changed = lw, changed
+	d.mtx.Unlock()
+
+	if lw == nil {
+		return &metav1.List{}, nil
+	}
+	return lw.List(options)
+}
+
+// Watch implements the ListerWatcher interface.
+// It watches the namespaces of the last List call until they change.
+func (d *dynamicListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	d.mtx.Lock()
+	lw, changed := d.lw, d.changed
+	d.mtx.Unlock()
+
+	if changed == nil {
+		return nil, apierrors.NewResourceExpired("watch requested before list")
+	}
+
+	var next watch.Interface = watch.NewFake()
+	if lw != nil {
+		var err error
+		next, err = lw.Watch(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newNamespaceSetWatch(changed, next), nil
+}
+
+// newNamespaceSetWatch creates a new watch.Interface wrapping the given next
+// watcher, which ends with an expired error once the given changed chan is
+// closed.
+func newNamespaceSetWatch(changed <-chan struct{}, next watch.Interface) watch.Interface {
+	var (
+		result = make(chan watch.Event)
+		proxy  = watch.NewProxyWatcher(result)
+	)
+
+	go func() {
+		defer close(result)
+		defer next.Stop()
+
+		for {
+			select {
+			case event, ok := <-next.ResultChan():
+				if !ok {
+					return
+				}
+
+				select {
+				case result <- event:
+				case <-proxy.StopChan():
+					return
+				}
+			case <-changed:
+				klog.V(4).Info("namespaces changed, ending watch")
+				status := apierrors.NewResourceExpired("namespaces changed").ErrStatus
+
+				select {
+				case result <- watch.Event{Type: watch.Error, Object: &status}:
+				case <-proxy.StopChan():
+				}
+				return
+			case <-proxy.StopChan():
+				return
+			}
+		}
+	}()
+
+	return proxy
+}
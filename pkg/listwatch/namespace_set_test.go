@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDynamicMultiNamespaceListerWatcher(t *testing.T) {
+	f := func(ns string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+				return &v1.PodList{Items: []v1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: ns}},
+				}}, nil
+			},
+			WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+				return watch.NewFake(), nil
+			},
+		}
+	}
+
+	namespaces := NewNamespaceSet([]string{"ns1"})
+	lw := DynamicMultiNamespaceListerWatcher(namespaces, nil, f)
+
+	expectItems := func(want int) {
+		t.Helper()
+		list, err := lw.List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != want {
+			t.Fatalf("expected %d items, got %d", want, len(items))
+		}
+	}
+
+	expectExpired := func(w watch.Interface) {
+		t.Helper()
+		select {
+		case event := <-w.ResultChan():
+			if event.Type != watch.Error || !apierrors.IsResourceExpired(apierrors.FromObject(event.Object)) {
+				t.Fatalf("expected expired error event, got %v", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected watch to end after namespaces changed")
+		}
+	}
+
+	expectItems(1)
+
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespaces.Set([]string{"ns2", "ns1"})
+	expectExpired(w)
+
+	expectItems(2)
+
+	// Setting the same namespaces in a different order is not a change.
+	namespaces.Set([]string{"ns1", "ns2"})
+	w, err = lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-w.ResultChan():
+		t.Fatalf("expected no event, got %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	namespaces.Set(nil)
+	expectExpired(w)
+
+	expectItems(0)
+	w.Stop()
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package metric
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -38,13 +39,73 @@ func (f Family) Inspect(inspect func(Family)) {
 	inspect(f)
 }
 
-// ByteSlice returns the given Family in its string representation.
+// ByteSlice returns the given Family in its string representation. The
+// series are sorted, so that their order does not depend on the order in
+// which they were generated, e.g. from the entries of a map.
 func (f Family) ByteSlice() []byte {
+	// Most families have few series, so their line ends fit on the stack.
+	var endsBuf [16]int
+	ends := endsBuf[:0]
+
 	b := strings.Builder{}
 	for _, m := range f.Metrics {
 		b.WriteString(f.Name)
 		m.Write(&b)
+		ends = append(ends, b.Len())
+	}
+
+	l := familyLines{s: b.String(), ends: ends}
+	// Series are mostly generated in order already, in which case they are
+	// written out as they are.
+	if l.sorted() {
+		return []byte(l.s)
+	}
+
+	order := make([]int, len(ends))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Sort(sortedLines{familyLines: l, order: order})
+
+	out := make([]byte, 0, len(l.s))
+	for _, i := range order {
+		out = append(out, l.line(i)...)
+	}
+
+	return out
+}
+
+// familyLines holds the written series of a family and the end offset of
+// each of them.
+type familyLines struct {
+	s    string
+	ends []int
+}
+
+func (l familyLines) line(i int) string {
+	start := 0
+	if i > 0 {
+		start = l.ends[i-1]
 	}
+	return l.s[start:l.ends[i]]
+}
 
-	return []byte(b.String())
+func (l familyLines) sorted() bool {
+	for i := 1; i < len(l.ends); i++ {
+		if l.line(i) < l.line(i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedLines sorts the order of the series of familyLines without moving
+// the series themselves.
+type sortedLines struct {
+	familyLines
+	order []int
 }
+
+func (l sortedLines) Len() int           { return len(l.order) }
+func (l sortedLines) Less(i, j int) bool { return l.line(l.order[i]) < l.line(l.order[j]) }
+func (l sortedLines) Swap(i, j int)      { l.order[i], l.order[j] = l.order[j], l.order[i] }
@@ -22,6 +22,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -35,6 +38,15 @@ var (
 			return &b
 		},
 	}
+
+	// SanitizedLabelValuesTotal counts the label values which contained
+	// invalid UTF-8 and were sanitized.
+	SanitizedLabelValuesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_sanitized_label_values_total",
+			Help: "Number of label values containing invalid UTF-8 which were sanitized.",
+		},
+	)
 )
 
 // Type represents the type of a metric e.g. a counter. See
@@ -53,6 +65,9 @@ type Metric struct {
 	LabelKeys   []string
 	LabelValues []string
 	Value       float64
+	// Timestamp is the time of the sample in milliseconds since the Unix
+	// epoch. Samples with a zero timestamp are written without one.
+	Timestamp int64
 }
 
 func (m *Metric) Write(s *strings.Builder) {
@@ -66,6 +81,10 @@ func (m *Metric) Write(s *strings.Builder) {
 	labelsToString(s, m.LabelKeys, m.LabelValues)
 	s.WriteByte(' ')
 	writeFloat(s, m.Value)
+	if m.Timestamp != 0 {
+		s.WriteByte(' ')
+		writeInt(s, m.Timestamp)
+	}
 	s.WriteByte('\n')
 }
 
@@ -91,12 +110,28 @@ var (
 )
 
 // escapeString replaces '\' by '\\', new line character by '\n', and '"' by
-// '\"'.
+// '\"'. Invalid UTF-8 is replaced by the Unicode replacement character, as a
+// single invalid label value would make Prometheus reject the whole scrape.
 // Taken from github.com/prometheus/common/expfmt/text_create.go.
 func escapeString(m *strings.Builder, v string) {
+	if !utf8.ValidString(v) {
+		v = strings.ToValidUTF8(v, string(utf8.RuneError))
+		SanitizedLabelValuesTotal.Inc()
+	}
 	escapeWithDoubleQuote.WriteString(m, v)
 }
 
+// writeInt is equivalent to fmt.Fprint with an int64 argument but uses
+// strconv.AppendInt with a byte slice taken from a sync.Pool to avoid
+// allocations.
+// Taken from github.com/prometheus/common/expfmt/text_create.go.
+func writeInt(w *strings.Builder, i int64) {
+	bp := numBufPool.Get().(*[]byte)
+	*bp = strconv.AppendInt((*bp)[:0], i, 10)
+	w.Write(*bp)
+	numBufPool.Put(bp)
+}
+
 // writeFloat is equivalent to fmt.Fprint with a float64 argument but hardcodes
 // a few common cases for increased efficiency. For non-hardcoded cases, it uses
 // strconv.AppendFloat to avoid allocations, similar to writeInt.
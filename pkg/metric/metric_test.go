@@ -19,6 +19,8 @@ package metric
 import (
 	"strings"
 	"testing"
+
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestFamilyString(t *testing.T) {
@@ -41,6 +43,71 @@ func TestFamilyString(t *testing.T) {
 	}
 }
 
+func TestFamilyStringSortsMetrics(t *testing.T) {
+	f := Family{
+		Name: "kube_node_status_capacity",
+		Metrics: []*Metric{
+			{LabelKeys: []string{"resource"}, LabelValues: []string{"pods"}, Value: 110},
+			{LabelKeys: []string{"resource"}, LabelValues: []string{"cpu"}, Value: 4},
+			{LabelKeys: []string{"resource"}, LabelValues: []string{"memory"}, Value: 1024},
+		},
+	}
+
+	expected := `kube_node_status_capacity{resource="cpu"} 4
+kube_node_status_capacity{resource="memory"} 1024
+kube_node_status_capacity{resource="pods"} 110
+`
+	got := string(f.ByteSlice())
+
+	if got != expected {
+		t.Fatalf("expected %v but got %v", expected, got)
+	}
+}
+
+func TestWriteSanitizesLabelValues(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  string
+		sanitized bool
+	}{
+		{value: "äöü", expected: `äöü`},
+		{value: "line\nbreak \"quoted\"", expected: `line\nbreak \"quoted\"`},
+		{value: "invalid\xffutf8", expected: "invalid\uFFFDutf8", sanitized: true},
+		{value: "truncated\xe2\x82", expected: "truncated\uFFFD", sanitized: true},
+		{value: "null\x00tab\tdel\x7f", expected: "null\x00tab\tdel\x7f"},
+		{value: "c1\u0085control", expected: "c1\u0085control"},
+	}
+
+	for i, test := range tests {
+		before := sanitizedLabelValues(t)
+
+		m := Metric{
+			LabelKeys:   []string{"label"},
+			LabelValues: []string{test.value},
+			Value:       1,
+		}
+		b := strings.Builder{}
+		m.Write(&b)
+
+		expected := `{label="` + test.expected + `"} 1` + "\n"
+		if b.String() != expected {
+			t.Errorf("%d: expected %q but got %q", i, expected, b.String())
+		}
+
+		if sanitized := sanitizedLabelValues(t) > before; sanitized != test.sanitized {
+			t.Errorf("%d: expected sanitized to be %t", i, test.sanitized)
+		}
+	}
+}
+
+func sanitizedLabelValues(t *testing.T) float64 {
+	pb := &dto.Metric{}
+	if err := SanitizedLabelValuesTotal.Write(pb); err != nil {
+		t.Fatal(err)
+	}
+	return pb.GetCounter().GetValue()
+}
+
 func BenchmarkMetricWrite(b *testing.B) {
 	tests := []struct {
 		testName       string
@@ -86,3 +153,23 @@ func BenchmarkMetricWrite(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkFamilyByteSlice(b *testing.B) {
+	sorted := Family{Name: "kube_node_status_capacity"}
+	for _, r := range []string{"cpu", "ephemeral_storage", "hugepages_2Mi", "memory", "pods"} {
+		sorted.Metrics = append(sorted.Metrics, &Metric{LabelKeys: []string{"resource"}, LabelValues: []string{r}, Value: 1})
+	}
+	unsorted := Family{Name: sorted.Name}
+	for i := len(sorted.Metrics) - 1; i >= 0; i-- {
+		unsorted.Metrics = append(unsorted.Metrics, sorted.Metrics[i])
+	}
+
+	for name, f := range map[string]Family{"sorted": sorted, "unsorted": unsorted} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				f.ByteSlice()
+			}
+		})
+	}
+}
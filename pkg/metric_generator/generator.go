@@ -17,6 +17,7 @@ limitations under the License.
 package generator
 
 import (
+	"sort"
 	"strings"
 
 	"k8s.io/kube-state-metrics/pkg/metric"
@@ -28,6 +29,7 @@ type FamilyGenerator struct {
 	Name         string
 	Help         string
 	Type         metric.Type
+	OptIn        bool
 	GenerateFunc func(obj interface{}) *metric.Family
 }
 
@@ -89,7 +91,8 @@ type allowDenyLister interface {
 }
 
 // FilterMetricFamilies takes a allow- and a denylist and a slice of metric
-// families and returns a filtered slice.
+// families and returns a filtered slice, sorted by the family names so that
+// the families are exposed in a stable order.
 func FilterMetricFamilies(l allowDenyLister, families []FamilyGenerator) []FamilyGenerator {
 	filtered := []FamilyGenerator{}
 
@@ -99,5 +102,28 @@ func FilterMetricFamilies(l allowDenyLister, families []FamilyGenerator) []Famil
 		}
 	}
 
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Name < filtered[j].Name
+	})
+
+	return filtered
+}
+
+type optInLister interface {
+	IsOptedIn(string) bool
+}
+
+// FilterOptInMetricFamilies takes an opt-in list and a slice of metric
+// families and returns a slice without the opt-in families that have not been
+// enabled. A nil list enables none of them.
+func FilterOptInMetricFamilies(l optInLister, families []FamilyGenerator) []FamilyGenerator {
+	filtered := []FamilyGenerator{}
+
+	for _, f := range families {
+		if !f.OptIn || (l != nil && l.IsOptedIn(f.Name)) {
+			filtered = append(filtered, f)
+		}
+	}
+
 	return filtered
 }
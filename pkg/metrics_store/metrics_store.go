@@ -17,10 +17,15 @@ limitations under the License.
 package metricsstore
 
 import (
+	"bytes"
 	"io"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/kube-state-metrics/pkg/metric"
@@ -37,25 +42,124 @@ type MetricsStore struct {
 	// grouped by metric families in order to zip families with their help text in
 	// MetricsStore.WriteAll().
 	metrics map[types.UID][][]byte
+	// resourceVersions contains the resource version of each object the
+	// metrics were generated from. If skipUnchanged is set, the metrics are
+	// only regenerated once the object changed.
+	resourceVersions map[types.UID]string
+	skipUnchanged    bool
+	// keys contains the namespace and name of each object, by which the
+	// metrics of the objects are sorted in MetricsStore.WriteAll().
+	keys map[types.UID]string
+	// order caches the ids of the objects sorted by their keys. It is reset
+	// whenever an object is added or deleted. As MetricsStore.WriteAll() only
+	// holds a read lock of mutex, computing order is protected by orderMutex.
+	order      []types.UID
+	orderMutex sync.Mutex
 	// headers contains the header (TYPE and HELP) of each metric family. It is
 	// later on zipped with with their corresponding metric families in
 	// MetricStore.WriteAll().
-	headers []string
+	headers [][]byte
 
 	// generateMetricsFunc generates metrics based on a given Kubernetes object
 	// and returns them grouped by metric family.
 	generateMetricsFunc func(interface{}) []metric.FamilyInterface
+
+	// changeFunc, if set, is called with the changes of the metrics of the
+	// objects in the store.
+	changeFunc func(Change)
+
+	// namespaceSeriesLimit, if positive, is the maximum number of series
+	// written per namespace. series contains the number of series of each
+	// object, and admitted caches the ids of the objects within the limit in
+	// the order of their keys. Like order, admitted is reset whenever an
+	// object is added or deleted and protected by orderMutex.
+	namespaceSeriesLimit int
+	series               map[types.UID]int
+	admitted             []types.UID
+	// droppedFunc is called with the number of series dropped in a
+	// namespace whenever it changed.
+	droppedFunc func(namespace string, series int)
+	dropped     map[string]int
+}
+
+// Change is a change of the metrics of an object in a MetricsStore. The
+// metrics are grouped by metric family, in the order of the headers of the
+// store. Old is nil for added objects and New is nil for deleted objects.
+type Change struct {
+	UID       types.UID
+	Namespace string
+	Name      string
+	Old       [][]byte
+	New       [][]byte
 }
 
 // NewMetricsStore returns a new MetricsStore
 func NewMetricsStore(headers []string, generateFunc func(interface{}) []metric.FamilyInterface) *MetricsStore {
+	// The headers are written on every scrape, so they are converted to
+	// newline terminated byte slices once upfront.
+	headerBytes := make([][]byte, len(headers))
+	for i, header := range headers {
+		headerBytes[i] = []byte(header + "\n")
+	}
+
 	return &MetricsStore{
 		generateMetricsFunc: generateFunc,
-		headers:             headers,
+		headers:             headerBytes,
 		metrics:             map[types.UID][][]byte{},
+		resourceVersions:    map[types.UID]string{},
+		keys:                map[types.UID]string{},
+		series:              map[types.UID]int{},
+		dropped:             map[string]int{},
 	}
 }
 
+// WithChangeFunc configures the store to call the given function with every
+// change of the metrics of its objects. The function is called without the
+// store being locked, in the order of the changes of each object.
+func (s *MetricsStore) WithChangeFunc(f func(Change)) {
+	s.changeFunc = f
+}
+
+// WithSkipUnchanged configures the store to only regenerate the metrics of an
+// object once its resource version changed, so that relists and updates
+// without changes are cheap. It must only be set if the metrics of the store
+// only depend on the objects themselves, and not on state kept across updates
+// or on the time they are generated at.
+func (s *MetricsStore) WithSkipUnchanged() {
+	s.skipUnchanged = true
+}
+
+// WithNamespaceSeriesLimit configures the store to write at most limit series
+// per namespace. The limit only covers the series of the store, so it applies
+// to each resource separately. Once an object of a namespace, in the order of
+// their names, exceeds the limit, the metrics of it and all further objects of
+// the namespace are dropped as a whole. The given function, if set, is called
+// with the number of series dropped in a namespace whenever it changed, which
+// is determined when the metrics are written. Cluster-scoped objects are not
+// limited.
+func (s *MetricsStore) WithNamespaceSeriesLimit(limit int, dropped func(namespace string, series int)) {
+	s.namespaceSeriesLimit = limit
+	s.droppedFunc = dropped
+}
+
+// NewNamespaceSeriesDroppedMetric takes in a prometheus registry and
+// initializes and registers the kube_state_metrics_namespace_series_dropped
+// metric, which reports the number of series a store dropped in a namespace
+// because of its namespace series limit. It returns the registered metric.
+func NewNamespaceSeriesDroppedMetric(r *prometheus.Registry) *prometheus.GaugeVec {
+	dropped := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_namespace_series_dropped",
+			Help: "Number of series of a resource not exposed in a namespace because of the namespace series limit",
+		},
+		[]string{"resource", "namespace"},
+	)
+	if r != nil {
+		r.MustRegister(dropped)
+	}
+	return dropped
+}
+
 // Implementing k8s.io/client-go/tools/cache.Store interface
 
 // Add inserts adds to the MetricsStore by calling the metrics generator functions and
@@ -66,24 +170,58 @@ func (s *MetricsStore) Add(obj interface{}) error {
 		return err
 	}
 
+	if change := s.add(obj, o); change != nil && s.changeFunc != nil {
+		s.changeFunc(*change)
+	}
+
+	return nil
+}
+
+// add adds the metrics of the given object to the store. It returns the
+// change of the metrics of the object, or nil if they were not regenerated.
+func (s *MetricsStore) add(obj interface{}, o metav1.Object) *Change {
+	uid, resourceVersion := o.GetUID(), o.GetResourceVersion()
+	key := o.GetNamespace() + "/" + o.GetName()
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	// Objects without a resource version, e.g. aggregates computed by
+	// kube-state-metrics itself, are always regenerated.
+	old, ok := s.metrics[uid]
+	if s.skipUnchanged && ok && resourceVersion != "" && s.resourceVersions[uid] == resourceVersion {
+		return nil
+	}
+
 	families := s.generateMetricsFunc(obj)
 	familyStrings := make([][]byte, len(families))
+	series := 0
 
 	for i, f := range families {
 		familyStrings[i] = f.ByteSlice()
+		series += bytes.Count(familyStrings[i], []byte("\n"))
 	}
 
-	s.metrics[o.GetUID()] = familyStrings
+	s.metrics[uid] = familyStrings
+	s.series[uid] = series
+	s.admitted = nil
+	if k, ok := s.keys[uid]; !ok || k != key {
+		s.keys[uid] = key
+		s.order = nil
+	}
+	if resourceVersion != "" {
+		s.resourceVersions[uid] = resourceVersion
+	} else {
+		delete(s.resourceVersions, uid)
+	}
 
-	return nil
+	return &Change{UID: uid, Namespace: o.GetNamespace(), Name: o.GetName(), Old: old, New: familyStrings}
 }
 
-// Update updates the existing entry in the MetricsStore.
+// Update updates the existing entry in the MetricsStore. If the store skips
+// unchanged objects, the metrics are only regenerated if the resource version
+// of the object changed.
 func (s *MetricsStore) Update(obj interface{}) error {
-	// TODO: For now, just call Add, in the future one could check if the resource version changed?
 	return s.Add(obj)
 }
 
@@ -96,9 +234,18 @@ func (s *MetricsStore) Delete(obj interface{}) error {
 	}
 
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
+	old, ok := s.metrics[o.GetUID()]
 	delete(s.metrics, o.GetUID())
+	delete(s.resourceVersions, o.GetUID())
+	delete(s.keys, o.GetUID())
+	delete(s.series, o.GetUID())
+	s.order = nil
+	s.admitted = nil
+	s.mutex.Unlock()
+
+	if ok && s.changeFunc != nil {
+		s.changeFunc(Change{UID: o.GetUID(), Namespace: o.GetNamespace(), Name: o.GetName(), Old: old})
+	}
 
 	return nil
 }
@@ -124,12 +271,40 @@ func (s *MetricsStore) GetByKey(key string) (item interface{}, exists bool, err
 }
 
 // Replace will delete the contents of the store, using instead the
-// given list.
+// given list. If the store skips unchanged objects, the metrics of objects
+// whose resource version did not change are kept as they are.
 func (s *MetricsStore) Replace(list []interface{}, _ string) error {
+	uids := make(map[types.UID]struct{}, len(list))
+	for _, obj := range list {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		uids[o.GetUID()] = struct{}{}
+	}
+
+	deleted := []Change{}
 	s.mutex.Lock()
-	s.metrics = map[types.UID][][]byte{}
+	for uid, old := range s.metrics {
+		if _, ok := uids[uid]; !ok {
+			if s.changeFunc != nil {
+				namespace, name := splitKey(s.keys[uid])
+				deleted = append(deleted, Change{UID: uid, Namespace: namespace, Name: name, Old: old})
+			}
+			delete(s.metrics, uid)
+			delete(s.resourceVersions, uid)
+			delete(s.keys, uid)
+			delete(s.series, uid)
+			s.order = nil
+			s.admitted = nil
+		}
+	}
 	s.mutex.Unlock()
 
+	for _, change := range deleted {
+		s.changeFunc(change)
+	}
+
 	for _, o := range list {
 		err := s.Add(o)
 		if err != nil {
@@ -146,16 +321,162 @@ func (s *MetricsStore) Resync() error {
 }
 
 // WriteAll writes all metrics of the store into the given writer, zipped with the
-// help text of each metric family.
+// help text of each metric family. The metrics of each family are written in
+// the order of the namespaces and names of their objects. Objects beyond the
+// series limit of their namespace are left out.
 func (s *MetricsStore) WriteAll(w io.Writer) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	for i, help := range s.headers {
-		w.Write([]byte(help))
-		w.Write([]byte{'\n'})
-		for _, metricFamilies := range s.metrics {
-			w.Write(metricFamilies[i])
+	order := s.admittedUIDs()
+
+	for i, header := range s.headers {
+		w.Write(header)
+		for _, uid := range order {
+			w.Write(s.metrics[uid][i])
+		}
+	}
+}
+
+// WriteNamespace writes the metrics of the objects in the given namespace into
+// the given writer, zipped with the help text of each metric family, like
+// WriteAll. Cluster-scoped objects are not written.
+func (s *MetricsStore) WriteNamespace(w io.Writer, namespace string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	// The objects are sorted by their keys, so the objects of the namespace
+	// are adjacent.
+	order := s.admittedUIDs()
+	prefix := namespace + "/"
+	start := sort.Search(len(order), func(i int) bool { return s.keys[order[i]] >= prefix })
+	end := start
+	for end < len(order) && strings.HasPrefix(s.keys[order[end]], prefix) {
+		end++
+	}
+
+	for i, header := range s.headers {
+		w.Write(header)
+		for _, uid := range order[start:end] {
+			w.Write(s.metrics[uid][i])
+		}
+	}
+}
+
+// WriteObject writes the metrics of the object with the given namespace and
+// name into the given writer, zipped with the help text of each metric
+// family. Cluster-scoped objects have an empty namespace. It returns false if
+// the store does not hold the object, or if the object is beyond the series
+// limit of its namespace.
+func (s *MetricsStore) WriteObject(w io.Writer, namespace, name string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	key := namespace + "/" + name
+	uids := []types.UID{}
+	for _, uid := range s.admittedUIDs() {
+		if s.keys[uid] == key {
+			uids = append(uids, uid)
+		}
+	}
+	if len(uids) == 0 {
+		return false
+	}
+
+	for i, header := range s.headers {
+		w.Write(header)
+		for _, uid := range uids {
+			w.Write(s.metrics[uid][i])
+		}
+	}
+	return true
+}
+
+// splitKey splits the key of an object into its namespace and name.
+func splitKey(key string) (namespace, name string) {
+	i := strings.Index(key, "/")
+	return key[:i], key[i+1:]
+}
+
+// sortedUIDs returns the ids of the objects in the store sorted by their
+// namespaces and names. It must be called with mutex held.
+func (s *MetricsStore) sortedUIDs() []types.UID {
+	s.orderMutex.Lock()
+	defer s.orderMutex.Unlock()
+
+	return s.sortUIDs()
+}
+
+// sortUIDs is like sortedUIDs, but must be called with orderMutex held as well.
+func (s *MetricsStore) sortUIDs() []types.UID {
+	if s.order == nil {
+		order := make([]types.UID, 0, len(s.metrics))
+		for uid := range s.metrics {
+			order = append(order, uid)
+		}
+		sort.Slice(order, func(i, j int) bool {
+			ki, kj := s.keys[order[i]], s.keys[order[j]]
+			if ki != kj {
+				return ki < kj
+			}
+			return order[i] < order[j]
+		})
+		s.order = order
+	}
+
+	return s.order
+}
+
+// admittedUIDs returns the ids of the objects in the store within the series
+// limit of their namespaces, sorted by their namespaces and names. It must be
+// called with mutex held.
+func (s *MetricsStore) admittedUIDs() []types.UID {
+	s.orderMutex.Lock()
+	defer s.orderMutex.Unlock()
+
+	order := s.sortUIDs()
+	if s.namespaceSeriesLimit <= 0 {
+		return order
+	}
+
+	if s.admitted == nil {
+		admitted := make([]types.UID, 0, len(order))
+		written := map[string]int{}
+		dropped := map[string]int{}
+		for _, uid := range order {
+			namespace, _ := splitKey(s.keys[uid])
+			series := s.series[uid]
+			// Once an object of a namespace is beyond the limit, all further
+			// objects of the namespace are, even if they would still fit.
+			_, full := dropped[namespace]
+			if namespace != "" && (full || written[namespace]+series > s.namespaceSeriesLimit) {
+				dropped[namespace] += series
+				continue
+			}
+			written[namespace] += series
+			admitted = append(admitted, uid)
+		}
+		s.admitted = admitted
+		s.updateDropped(dropped)
+	}
+
+	return s.admitted
+}
+
+// updateDropped reports the namespaces whose number of dropped series changed
+// to droppedFunc. It must be called with orderMutex held.
+func (s *MetricsStore) updateDropped(dropped map[string]int) {
+	if s.droppedFunc != nil {
+		for namespace := range s.dropped {
+			if _, ok := dropped[namespace]; !ok {
+				s.droppedFunc(namespace, 0)
+			}
+		}
+		for namespace, series := range dropped {
+			if s.dropped[namespace] != series {
+				s.droppedFunc(namespace, series)
+			}
 		}
 	}
+	s.dropped = dropped
 }
@@ -90,3 +90,379 @@ func TestObjectsSameNameDifferentNamespaces(t *testing.T) {
 		}
 	}
 }
+
+func TestRegenerateOnResourceVersionChange(t *testing.T) {
+	generated := 0
+
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		generated++
+
+		metricFamily := metric.Family{
+			Name: "kube_service_info",
+			Metrics: []*metric.Metric{
+				{
+					LabelKeys:   []string{"uid", "resource_version"},
+					LabelValues: []string{string(o.GetUID()), o.GetResourceVersion()},
+					Value:       float64(1),
+				},
+			},
+		}
+
+		return []metric.FamilyInterface{&metricFamily}
+	}
+
+	ms := NewMetricsStore([]string{"Information about service."}, genFunc)
+
+	newService := func(uid, resourceVersion string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            uid,
+				Namespace:       "default",
+				UID:             types.UID(uid),
+				ResourceVersion: resourceVersion,
+			},
+		}
+	}
+
+	steps := []struct {
+		op        func() error
+		generated int
+	}{
+		// Unless configured otherwise, unchanged objects are regenerated, as
+		// their metrics may depend on more than the objects themselves.
+		{func() error { return ms.Add(newService("a", "1")) }, 1},
+		{func() error { return ms.Update(newService("a", "1")) }, 2},
+		{func() error { ms.WithSkipUnchanged(); return nil }, 2},
+		{func() error { return ms.Update(newService("a", "0")) }, 3},
+		{func() error { return ms.Add(newService("a", "1")) }, 4},
+		// Unchanged objects are not regenerated.
+		{func() error { return ms.Update(newService("a", "1")) }, 4},
+		{func() error { return ms.Update(newService("a", "2")) }, 5},
+		// Objects without a resource version are always regenerated.
+		{func() error { return ms.Add(newService("b", "")) }, 6},
+		{func() error { return ms.Update(newService("b", "")) }, 7},
+		// A relist only regenerates changed or new objects and drops the rest.
+		{func() error {
+			return ms.Replace([]interface{}{newService("a", "2"), newService("c", "1")}, "")
+		}, 8},
+	}
+
+	for i, step := range steps {
+		if err := step.op(); err != nil {
+			t.Fatal(err)
+		}
+		if generated != step.generated {
+			t.Fatalf("step %d: expected metrics to be generated %d times, got %d", i, step.generated, generated)
+		}
+	}
+
+	w := strings.Builder{}
+	ms.WriteAll(&w)
+	m := w.String()
+
+	for uid, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if strings.Contains(m, fmt.Sprintf("uid=%q", uid)) != expected {
+			t.Fatalf("expected metric of uid %v to be present: %v, got:\n%s", uid, expected, m)
+		}
+	}
+	if !strings.Contains(m, `resource_version="2"`) {
+		t.Fatalf("expected metrics of the latest resource version, got:\n%s", m)
+	}
+}
+
+func TestChangeFunc(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		metricFamily := metric.Family{
+			Name: "kube_service_info",
+			Metrics: []*metric.Metric{
+				{
+					LabelKeys:   []string{"service", "resource_version"},
+					LabelValues: []string{o.GetName(), o.GetResourceVersion()},
+					Value:       float64(1),
+				},
+			},
+		}
+
+		return []metric.FamilyInterface{&metricFamily}
+	}
+
+	ms := NewMetricsStore([]string{"Information about service."}, genFunc)
+	ms.WithSkipUnchanged()
+	changes := []Change{}
+	ms.WithChangeFunc(func(c Change) { changes = append(changes, c) })
+
+	newService := func(name, resourceVersion string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				UID:             types.UID(name),
+				ResourceVersion: resourceVersion,
+			},
+		}
+	}
+
+	steps := []struct {
+		op      func() error
+		name    string
+		added   bool
+		deleted bool
+	}{
+		{func() error { return ms.Add(newService("a", "1")) }, "a", true, false},
+		{func() error { return ms.Update(newService("a", "2")) }, "a", false, false},
+		{func() error { return ms.Delete(newService("a", "2")) }, "a", false, true},
+		{func() error { return ms.Add(newService("b", "1")) }, "b", true, false},
+		// A relist reports the objects it drops as deleted.
+		{func() error { return ms.Replace([]interface{}{newService("c", "1")}, "") }, "b", false, true},
+	}
+
+	for i, step := range steps {
+		changes = changes[:0]
+		if err := step.op(); err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) == 0 {
+			t.Fatalf("step %d: expected change", i)
+		}
+		c := changes[0]
+		if c.Namespace != "default" || c.Name != step.name || (c.Old == nil) != step.added || (c.New == nil) != step.deleted {
+			t.Fatalf("step %d: unexpected change %+v", i, c)
+		}
+	}
+
+	// Unchanged objects are not reported.
+	changes = changes[:0]
+	if err := ms.Update(newService("c", "1")); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no change of unchanged object, got %+v", changes)
+	}
+}
+
+func TestWriteAllSortsObjects(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return []metric.FamilyInterface{&metric.Family{
+			Name: "kube_service_info",
+			Metrics: []*metric.Metric{
+				{
+					LabelKeys:   []string{"namespace", "service"},
+					LabelValues: []string{o.GetNamespace(), o.GetName()},
+					Value:       1,
+				},
+			},
+		}}
+	}
+
+	ms := NewMetricsStore([]string{"# HELP kube_service_info Information about service."}, genFunc)
+
+	services := []struct{ namespace, name, uid string }{
+		{"b", "service1", "1"},
+		{"a", "service2", "2"},
+		{"a", "service1", "3"},
+		{"c", "service0", "4"},
+	}
+	for _, s := range services {
+		err := ms.Add(&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: s.namespace,
+				UID:       types.UID(s.uid),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := `# HELP kube_service_info Information about service.
+kube_service_info{namespace="a",service="service1"} 1
+kube_service_info{namespace="a",service="service2"} 1
+kube_service_info{namespace="b",service="service1"} 1
+kube_service_info{namespace="c",service="service0"} 1
+`
+
+	// The order is the same across writes and after objects are deleted.
+	for i := 0; i < 2; i++ {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		if w.String() != expected {
+			t.Fatalf("expected:\n%s\ngot:\n%s", expected, w.String())
+		}
+	}
+
+	err := ms.Delete(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service1", Namespace: "b", UID: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = strings.Replace(expected, "kube_service_info{namespace=\"b\",service=\"service1\"} 1\n", "", 1)
+	w := strings.Builder{}
+	ms.WriteAll(&w)
+	if w.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, w.String())
+	}
+}
+
+func TestNamespaceSeriesLimit(t *testing.T) {
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return []metric.FamilyInterface{&metric.Family{
+			Name: "kube_service_info",
+			Metrics: []*metric.Metric{
+				{
+					LabelKeys:   []string{"namespace", "service"},
+					LabelValues: []string{o.GetNamespace(), o.GetName()},
+					Value:       1,
+				},
+				{
+					LabelKeys:   []string{"namespace", "service", "port"},
+					LabelValues: []string{o.GetNamespace(), o.GetName(), "80"},
+					Value:       1,
+				},
+			},
+		}}
+	}
+
+	ms := NewMetricsStore([]string{"# HELP kube_service_info Information about service."}, genFunc)
+	dropped := map[string]int{}
+	ms.WithNamespaceSeriesLimit(3, func(namespace string, series int) { dropped[namespace] = series })
+
+	services := []struct{ namespace, name, uid string }{
+		{"a", "service1", "1"},
+		{"a", "service2", "2"},
+		{"a", "service3", "3"},
+		{"b", "service1", "4"},
+		{"", "service1", "5"},
+		{"", "service2", "6"},
+	}
+	for _, s := range services {
+		err := ms.Add(&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: s.namespace,
+				UID:       types.UID(s.uid),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only the first object of namespace a fits into the limit, while
+	// cluster-scoped objects are not limited.
+	expected := `# HELP kube_service_info Information about service.
+kube_service_info{namespace="",service="service1",port="80"} 1
+kube_service_info{namespace="",service="service1"} 1
+kube_service_info{namespace="",service="service2",port="80"} 1
+kube_service_info{namespace="",service="service2"} 1
+kube_service_info{namespace="a",service="service1",port="80"} 1
+kube_service_info{namespace="a",service="service1"} 1
+kube_service_info{namespace="b",service="service1",port="80"} 1
+kube_service_info{namespace="b",service="service1"} 1
+`
+	w := strings.Builder{}
+	ms.WriteAll(&w)
+	if w.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, w.String())
+	}
+	if len(dropped) != 1 || dropped["a"] != 4 {
+		t.Fatalf("expected 4 dropped series in namespace a, got %v", dropped)
+	}
+	if ms.WriteObject(&strings.Builder{}, "a", "service2") {
+		t.Fatal("expected object beyond the limit not to be written")
+	}
+
+	// Deleting objects makes room for the dropped ones.
+	for _, uid := range []string{"1", "2"} {
+		if err := ms.Delete(&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "a", UID: types.UID(uid)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected = strings.Replace(expected, `namespace="a",service="service1"`, `namespace="a",service="service3"`, 2)
+	w = strings.Builder{}
+	ms.WriteAll(&w)
+	if w.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, w.String())
+	}
+	if dropped["a"] != 0 {
+		t.Fatalf("expected no dropped series in namespace a, got %v", dropped)
+	}
+}
+
+func TestNamespaceSeriesLimitStopsAtFirstDroppedObject(t *testing.T) {
+	// Every service has as many series as its ports.
+	genFunc := func(obj interface{}) []metric.FamilyInterface {
+		s := obj.(*v1.Service)
+		ms := []*metric.Metric{}
+		for _, p := range s.Spec.Ports {
+			ms = append(ms, &metric.Metric{
+				LabelKeys:   []string{"namespace", "service", "port"},
+				LabelValues: []string{s.Namespace, s.Name, p.Name},
+				Value:       1,
+			})
+		}
+		return []metric.FamilyInterface{&metric.Family{Name: "kube_service_port", Metrics: ms}}
+	}
+
+	ms := NewMetricsStore([]string{"# HELP kube_service_port Port of service."}, genFunc)
+	dropped := map[string]int{}
+	ms.WithNamespaceSeriesLimit(3, func(namespace string, series int) { dropped[namespace] = series })
+
+	services := []struct {
+		name  string
+		ports []string
+	}{
+		{"service1", []string{"a"}},
+		{"service2", []string{"a", "b", "c"}},
+		{"service3", []string{"a"}},
+	}
+	for i, s := range services {
+		svc := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: "ns",
+				UID:       types.UID(fmt.Sprint(i)),
+			},
+		}
+		for _, p := range s.ports {
+			svc.Spec.Ports = append(svc.Spec.Ports, v1.ServicePort{Name: p})
+		}
+		if err := ms.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// service3 would still fit into the limit, but follows service2, which
+	// does not.
+	expected := `# HELP kube_service_port Port of service.
+kube_service_port{namespace="ns",service="service1",port="a"} 1
+`
+	w := strings.Builder{}
+	ms.WriteAll(&w)
+	if w.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, w.String())
+	}
+	if dropped["ns"] != 4 {
+		t.Fatalf("expected 4 dropped series in namespace ns, got %v", dropped)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// metricCache persists the metrics to a file, so that they can be served by
+// the next run of kube-state-metrics while it lists the objects, instead of
+// serving no metrics for minutes in large clusters, during which Prometheus
+// would see all series disappear and come back. Only scrapes of all metrics
+// are served from the file, as it holds the metrics of all resources and
+// namespaces, which can not be filtered cheaply.
+type metricCache struct {
+	path   string
+	maxAge time.Duration
+	now    func() time.Time
+
+	mtx sync.Mutex
+	// snapshot holds the metrics loaded from the file on startup until the
+	// stores have listed their objects. persisted is the time the snapshot
+	// was written.
+	snapshot  []byte
+	persisted time.Time
+}
+
+// newMetricCache returns a new metricCache persisting to the given file. The
+// metrics previously persisted to the file are loaded, unless they are older
+// than maxAge.
+func newMetricCache(path string, maxAge time.Duration) *metricCache {
+	c := &metricCache{
+		path:   path,
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+	if err := c.load(); err != nil {
+		klog.Errorf("Failed to load metric cache: %v", err)
+	}
+	return c
+}
+
+func (c *metricCache) load() error {
+	info, err := os.Stat(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if age := c.now().Sub(info.ModTime()); age > c.maxAge {
+		klog.Infof("Ignoring metric cache %s persisted %s ago", c.path, age.Round(time.Second))
+		return nil
+	}
+
+	snapshot, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	klog.Infof("Serving metric cache %s until all objects are listed", c.path)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.snapshot, c.persisted = snapshot, info.ModTime()
+	return nil
+}
+
+// staleSnapshot returns the loaded metrics and the time they were persisted,
+// unless there are none. Once the stores are synced or the snapshot is older
+// than maxAge, e.g. as a resource can not be listed, it is dropped.
+func (c *metricCache) staleSnapshot(synced bool) ([]byte, time.Time, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.snapshot == nil {
+		return nil, time.Time{}, false
+	}
+	if synced || c.now().Sub(c.persisted) > c.maxAge {
+		klog.Info("Serving live metrics instead of metric cache")
+		c.snapshot = nil
+		return nil, time.Time{}, false
+	}
+	return c.snapshot, c.persisted, true
+}
+
+// persist writes the metrics written by the given function to the file. The
+// metrics are written to a temporary file first, which then replaces the
+// file, so that the file is never incomplete.
+func (c *metricCache) persist(write func(io.Writer)) error {
+	tmp := c.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return errors.Wrap(err, "failed to create metric cache")
+	}
+
+	bw := bufio.NewWriterSize(f, 64*1024)
+	write(bw)
+	err = bw.Flush()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return errors.Wrap(err, "failed to write metric cache")
+	}
+
+	return errors.Wrap(os.Rename(tmp, c.path), "failed to replace metric cache")
+}
+
+// writeCacheTimestamp writes when the served metric cache was persisted.
+func writeCacheTimestamp(w io.Writer, persisted time.Time) {
+	io.WriteString(w, "# HELP kube_state_metrics_cache_timestamp_seconds Unix timestamp at which the served metric cache was persisted.\n# TYPE kube_state_metrics_cache_timestamp_seconds gauge\n")
+	io.WriteString(w, "kube_state_metrics_cache_timestamp_seconds "+strconv.FormatFloat(float64(persisted.Unix()), 'g', -1, 64)+"\n")
+}
+
+// RunMetricCache persists the metrics of all enabled resources to the metric
+// cache every given interval until the given context is done. Metrics are
+// only persisted once the stores have listed their objects, so that the
+// metrics of a complete run are never replaced by incomplete ones. It returns
+// immediately if the metric cache is disabled.
+func (m *MetricsHandler) RunMetricCache(ctx context.Context, interval time.Duration) {
+	if m.cache == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.mtx.RLock()
+			synced := m.synced()
+			m.mtx.RUnlock()
+			if !synced {
+				continue
+			}
+			if err := m.cache.persist(m.WriteAll); err != nil {
+				klog.Errorf("Failed to persist metric cache: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// synced returns whether the stores have been built and have listed their
+// objects. m.mtx must be held for reading.
+func (m *MetricsHandler) synced() bool {
+	return m.cancel != nil && m.storeBuilder.Synced()
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+func TestMetricCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metric-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "metrics.prom")
+	persisted := time.Unix(1600000000, 0)
+	if err := ioutil.WriteFile(path, []byte("kube_pod_info{pod=\"pod1\"} 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, persisted, persisted); err != nil {
+		t.Fatal(err)
+	}
+
+	storeBuilder := store.NewBuilder()
+	if err := storeBuilder.WithEnabledResources([]string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &metricCache{path: path, maxAge: time.Hour, now: func() time.Time { return persisted.Add(time.Minute) }}
+	if err := c.load(); err != nil {
+		t.Fatal(err)
+	}
+	m := &MetricsHandler{
+		storeBuilder: storeBuilder,
+		mtx:          &sync.RWMutex{},
+		stores: map[string][]cache.Store{
+			"pods": {metricsstore.NewMetricsStore(
+				[]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"},
+				func(interface{}) []metric.FamilyInterface { return nil },
+			)},
+		},
+		cache: c,
+	}
+
+	scrape := func(query string) string {
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/metrics"+query, nil))
+		return w.Body.String()
+	}
+
+	const staleHelp = "# HELP kube_state_metrics_data_stale Whether the served metrics may be outdated, by reason: served from the metric cache of a previous run, served from the stores replaced by a rebuild, or listing or watching objects failed, e.g. as the apiserver is unreachable.\n# TYPE kube_state_metrics_data_stale gauge\n"
+	const live = "# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge\n" +
+		staleHelp + "kube_state_metrics_data_stale{reason=\"cache\"} 0\nkube_state_metrics_data_stale{reason=\"rebuild\"} 0\nkube_state_metrics_data_stale{reason=\"list_watch_failed\"} 0\n"
+	const stale = "kube_pod_info{pod=\"pod1\"} 1\n" +
+		"# HELP kube_state_metrics_cache_timestamp_seconds Unix timestamp at which the served metric cache was persisted.\n# TYPE kube_state_metrics_cache_timestamp_seconds gauge\nkube_state_metrics_cache_timestamp_seconds 1.6e+09\n" +
+		staleHelp + "kube_state_metrics_data_stale{reason=\"cache\"} 1\nkube_state_metrics_data_stale{reason=\"rebuild\"} 0\nkube_state_metrics_data_stale{reason=\"list_watch_failed\"} 0\n"
+
+	// Until the stores are built and synced, the cache is served, except
+	// for scrapes selecting resources.
+	if got := scrape(""); got != stale {
+		t.Fatalf("expected:\n%s\ngot:\n%s", stale, got)
+	}
+	if got := scrape("?collect[]=pods"); got != live {
+		t.Fatalf("expected:\n%s\ngot:\n%s", live, got)
+	}
+
+	m.cancel = func() {}
+	if got := scrape(""); got != live {
+		t.Fatalf("expected:\n%s\ngot:\n%s", live, got)
+	}
+
+	if err := c.persist(func(w io.Writer) { io.WriteString(w, "kube_pod_info{pod=\"pod2\"} 1\n") }); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "kube_pod_info{pod=\"pod2\"} 1\n" {
+		t.Fatalf("unexpected persisted metrics:\n%s", data)
+	}
+}
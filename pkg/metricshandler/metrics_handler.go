@@ -17,8 +17,11 @@ limitations under the License.
 package metricshandler
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -38,6 +41,21 @@ import (
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
+var (
+	// gzipWriterPool and bufferedWriterPool hold the writers used to encode
+	// responses, as allocating them for every scrape is expensive.
+	gzipWriterPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(nil)
+		},
+	}
+	bufferedWriterPool = sync.Pool{
+		New: func() interface{} {
+			return bufio.NewWriterSize(nil, 64*1024)
+		},
+	}
+)
+
 // MetricsHandler is a http.Handler that exposes the main kube-state-metrics
 // /metrics endpoint. It allows concurrent reconfiguration at runtime.
 type MetricsHandler struct {
@@ -45,25 +63,57 @@ type MetricsHandler struct {
 	kubeClient         kubernetes.Interface
 	storeBuilder       *store.Builder
 	enableGZIPEncoding bool
-
+	// lazyResources defers building the stores of a resource, and thereby
+	// listing and watching its objects, until its metrics are first
+	// requested.
+	lazyResources bool
+
+	// ctx is the context passed to ConfigureSharding, which the stores are
+	// rebuilt with by Reconfigure.
+	ctx    context.Context
 	cancel func()
 
-	// mtx protects stores, curShard, and curTotalShards
-	mtx            *sync.RWMutex
-	stores         []cache.Store
+	// mtx protects ctx, cancel, stores, curShard, and curTotalShards, and
+	// serializes changes to the configuration of storeBuilder.
+	mtx *sync.RWMutex
+	// stores holds the stores of each started resource. A resource whose
+	// metrics were requested before sharding was configured is recorded
+	// without stores and started by ConfigureSharding.
+	stores         map[string][]cache.Store
 	curShard       int32
 	curTotalShards int
+
+	// cache, if set, persists the metrics and serves the metrics persisted
+	// by a previous run until the stores have listed their objects.
+	cache *metricCache
+
+	// serveStale keeps serving the stores replaced by a rebuild until the
+	// new stores have listed their objects, and exposes whether the served
+	// metrics are stale.
+	serveStale bool
+	// previous holds the stores replaced by the last rebuild while the
+	// current stores have not listed their objects yet. It is protected by
+	// previousMtx, as it is dropped by scrapes holding mtx for reading.
+	previous    map[string][]cache.Store
+	previousMtx sync.Mutex
 }
 
 // New creates and returns a new MetricsHandler with the given options.
 func New(opts *options.Options, kubeClient kubernetes.Interface, storeBuilder *store.Builder, enableGZIPEncoding bool) *MetricsHandler {
-	return &MetricsHandler{
+	m := &MetricsHandler{
 		opts:               opts,
 		kubeClient:         kubeClient,
 		storeBuilder:       storeBuilder,
 		enableGZIPEncoding: enableGZIPEncoding,
+		lazyResources:      opts.LazyResources,
+		serveStale:         opts.ServeStaleMetrics,
 		mtx:                &sync.RWMutex{},
+		stores:             map[string][]cache.Store{},
 	}
+	if opts.MetricCacheFile != "" {
+		m.cache = newMetricCache(opts.MetricCacheFile, opts.MetricCacheMaxAge)
+	}
+	return m
 }
 
 // ConfigureSharding (re-)configures sharding. Re-configuration can be done
@@ -72,16 +122,65 @@ func (m *MetricsHandler) ConfigureSharding(ctx context.Context, shard int32, tot
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
-	if m.cancel != nil {
-		m.cancel()
-	}
 	if totalShards != 1 {
 		klog.Infof("configuring sharding of this instance to be shard index %d (zero-indexed) out of %d total shards", shard, totalShards)
 	}
-	ctx, m.cancel = context.WithCancel(ctx)
+	m.ctx = ctx
+	m.buildStores(shard, totalShards)
+}
+
+// Reconfigure applies the given configuration function to the store builder
+// and rebuilds the stores of all started resources with it. Reconfiguration
+// can be done concurrently with scrapes and sharding changes.
+func (m *MetricsHandler) Reconfigure(configure func(b *store.Builder) error) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := configure(m.storeBuilder); err != nil {
+		return err
+	}
+
+	// Stores which have not been built yet are built with the new
+	// configuration once sharding is configured.
+	if m.cancel == nil {
+		return nil
+	}
+	m.buildStores(m.curShard, m.curTotalShards)
+	return nil
+}
+
+// buildStores stops the current stores and builds the stores of the started
+// resources for the given shard. m.mtx must be held for writing.
+func (m *MetricsHandler) buildStores(shard int32, totalShards int) {
+	if m.cancel != nil {
+		if m.serveStale {
+			m.keepPreviousStores()
+		}
+		m.cancel()
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
 	m.storeBuilder.WithSharding(shard, totalShards)
 	m.storeBuilder.WithContext(ctx)
-	m.stores = m.storeBuilder.Build()
+
+	resources := m.storeBuilder.EnabledResources()
+	if m.lazyResources {
+		// Only restart the resources which have been requested so far.
+		resources = []string{}
+		for _, r := range m.storeBuilder.EnabledResources() {
+			if _, ok := m.stores[r]; ok {
+				resources = append(resources, r)
+			}
+		}
+	}
+
+	stores := make(map[string][]cache.Store, len(resources))
+	for _, r := range resources {
+		stores[r] = m.storeBuilder.BuildResource(r)
+	}
+	klog.Infof("Active resources: %s", strings.Join(resources, ","))
+
+	m.stores = stores
 	m.curShard = shard
 	m.curTotalShards = totalShards
 }
@@ -176,7 +275,35 @@ func (m *MetricsHandler) Run(ctx context.Context) error {
 
 // ServeHTTP implements the http.Handler interface. It writes the metrics in
 // its stores to the response body.
+//
+// The resources to be written can be selected with one or more collect[]
+// query parameters, e.g. /metrics?collect[]=pods&collect[]=nodes.
+//
+// The series can be restricted to the objects in a single watched namespace
+// with the namespace query parameter, e.g. /metrics?namespace=team-a, so that
+// the tenants of a cluster can scrape the metrics of their own namespaces
+// only. Cluster-scoped objects are left out, as they would leak information
+// across tenants. The namespace series limit applies before the namespace is
+// selected, so the series are the ones of the namespace in a full scrape. The
+// parameter only filters the series and does not restrict access, which
+// --scrape-authorization does.
 func (m *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resources, err := m.requestedResources(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	namespace, err := m.requestedNamespace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if m.lazyResources {
+		m.startResources(resources)
+	}
+
 	m.mtx.RLock()
 	defer m.mtx.RUnlock()
 	resHeader := w.Header()
@@ -192,20 +319,250 @@ func (m *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		for _, part := range parts {
 			part = strings.TrimSpace(part)
 			if part == "gzip" || strings.HasPrefix(part, "gzip;") {
-				writer = gzip.NewWriter(writer)
+				gz := gzipWriterPool.Get().(*gzip.Writer)
+				defer gzipWriterPool.Put(gz)
+
+				gz.Reset(w)
+				writer = gz
 				resHeader.Set("Content-Encoding", "gzip")
+				break
 			}
 		}
 	}
 
-	for _, s := range m.stores {
-		ms := s.(*metricsstore.MetricsStore)
-		ms.WriteAll(w)
+	// The stores write many small slices, so buffer them before they reach
+	// the gzip writer or the connection.
+	bw := bufferedWriterPool.Get().(*bufio.Writer)
+	defer bufferedWriterPool.Put(bw)
+	bw.Reset(writer)
+
+	m.writeMetrics(bw, r, resources, namespace)
+
+	if err := bw.Flush(); err != nil {
+		klog.Errorf("failed to write metrics: %v", err)
 	}
+	// Release the reference to the response writer before the pooled writer
+	// is reused.
+	bw.Reset(nil)
 
 	// In case we gzipped the response, we have to close the writer.
-	if closer, ok := writer.(io.Closer); ok {
-		closer.Close()
+	if gz, ok := writer.(*gzip.Writer); ok {
+		if err := gz.Close(); err != nil {
+			klog.Errorf("failed to close gzip writer: %v", err)
+		}
+		gz.Reset(nil)
+	}
+}
+
+// writeMetrics writes the metrics of the given resources requested by the
+// given request. Until the stores have listed their objects, scrapes of all
+// metrics are served from the metric cache, if any. m.mtx must be held for
+// reading.
+func (m *MetricsHandler) writeMetrics(w io.Writer, r *http.Request, resources []string, namespace string) {
+	// The cache holds the metrics of all resources in all namespaces, so
+	// it can not serve scrapes selecting some of them.
+	if m.cache != nil && len(r.URL.Query()) == 0 {
+		if snapshot, persisted, ok := m.cache.staleSnapshot(m.synced()); ok {
+			w.Write(snapshot)
+			writeCacheTimestamp(w, persisted)
+			writeDataStale(w, staleReasonCache)
+			return
+		}
+	}
+
+	previous := m.writeResources(w, resources, namespace)
+	if m.cache != nil || m.serveStale {
+		var reasons []string
+		if previous {
+			reasons = append(reasons, staleReasonRebuild)
+		}
+		if m.storeBuilder.Stale() {
+			reasons = append(reasons, staleReasonListWatch)
+		}
+		writeDataStale(w, reasons...)
+	}
+}
+
+// WriteAll writes the metrics of all enabled resources to the given writer in
+// the Prometheus text format, as served on /metrics.
+func (m *MetricsHandler) WriteAll(w io.Writer) {
+	resources := m.storeBuilder.EnabledResources()
+	if m.lazyResources {
+		m.startResources(resources)
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	m.writeResources(w, resources, "")
+}
+
+// writeResources writes the metrics in the stores of the given resources to
+// the given writer. If namespace is not empty, only the metrics of the
+// objects in that namespace are written. It returns whether the stores
+// replaced by the last rebuild were written, as the current stores have not
+// listed their objects yet. m.mtx must be held for reading.
+func (m *MetricsHandler) writeResources(w io.Writer, resources []string, namespace string) bool {
+	previous := m.previousStores()
+	for _, resource := range resources {
+		stores, ok := previous[resource]
+		if !ok {
+			stores = m.stores[resource]
+		}
+		for _, s := range stores {
+			ms := s.(*metricsstore.MetricsStore)
+			if namespace != "" {
+				ms.WriteNamespace(w, namespace)
+				continue
+			}
+			ms.WriteAll(w)
+		}
+	}
+	return previous != nil
+}
+
+// ServeObject writes the metrics currently generated for a single object to
+// the response body, e.g. for /debug/object?kind=Pod&namespace=x&name=y.
+// Cluster-scoped objects are selected without a namespace. If the object is
+// not held by the stores of this instance, e.g. because it belongs to another
+// shard or is in a namespace which is not watched, 404 is returned.
+func (m *MetricsHandler) ServeObject(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	kind, namespace, name := query.Get("kind"), query.Get("namespace"), query.Get("name")
+	if kind == "" || name == "" {
+		http.Error(w, "kind and name query parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	resource, ok := store.ResourceForKind(kind)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown kind %q", kind), http.StatusBadRequest)
+		return
+	}
+
+	enabled := false
+	for _, e := range m.storeBuilder.EnabledResources() {
+		if e == resource {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		http.Error(w, fmt.Sprintf("resource %q is not enabled", resource), http.StatusNotFound)
+		return
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	stores, ok := m.stores[resource]
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q has not been started, as its metrics have not been requested yet", resource), http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	found := false
+	for _, s := range stores {
+		if s.(*metricsstore.MetricsStore).WriteObject(&buf, namespace, name) {
+			found = true
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("%s %s/%s not found in shard %d of %d", kind, namespace, name, m.curShard, m.curTotalShards), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", `text/plain; version=`+"0.0.4")
+	if _, err := buf.WriteTo(w); err != nil {
+		klog.Errorf("failed to write metrics of object: %v", err)
+	}
+}
+
+// requestedResources returns the enabled resources selected by the collect[]
+// query parameters of the given request, or all enabled resources if there
+// are none. The resources are returned in the order of the enabled resources.
+func (m *MetricsHandler) requestedResources(r *http.Request) ([]string, error) {
+	enabled := m.storeBuilder.EnabledResources()
+
+	collect := r.URL.Query()["collect[]"]
+	if len(collect) == 0 {
+		return enabled, nil
+	}
+
+	isEnabled := make(map[string]bool, len(enabled))
+	for _, e := range enabled {
+		isEnabled[e] = true
+	}
+
+	requested := make(map[string]bool, len(collect))
+	for _, c := range collect {
+		if !isEnabled[c] {
+			return nil, errors.Errorf("resource %q is not enabled", c)
+		}
+		requested[c] = true
+	}
+
+	resources := make([]string, 0, len(requested))
+	for _, e := range enabled {
+		if requested[e] {
+			resources = append(resources, e)
+		}
+	}
+
+	return resources, nil
+}
+
+// requestedNamespace returns the namespace selected by the namespace query
+// parameter of the given request, or an empty string if there is none. Only
+// namespaces which are watched can be selected, as the empty response for
+// any other namespace would look like a healthy scrape.
+func (m *MetricsHandler) requestedNamespace(r *http.Request) (string, error) {
+	values, ok := r.URL.Query()["namespace"]
+	if !ok {
+		return "", nil
+	}
+
+	if len(values) != 1 || values[0] == "" {
+		return "", errors.New("exactly one non-empty namespace must be given")
+	}
+	if !m.storeBuilder.WatchesNamespace(values[0]) {
+		return "", errors.Errorf("namespace %q is not watched", values[0])
+	}
+
+	return values[0], nil
+}
+
+// startResources builds the stores of the given resources which have not
+// been started yet. If sharding has not been configured yet, the resources
+// are started by ConfigureSharding instead.
+func (m *MetricsHandler) startResources(resources []string) {
+	m.mtx.RLock()
+	started := true
+	for _, r := range resources {
+		if _, ok := m.stores[r]; !ok {
+			started = false
+			break
+		}
+	}
+	m.mtx.RUnlock()
+
+	if started {
+		return
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, r := range resources {
+		if _, ok := m.stores[r]; ok {
+			continue
+		}
+		if m.cancel == nil {
+			m.stores[r] = nil
+			continue
+		}
+		klog.Infof("Starting resource %s on first request", r)
+		m.stores[r] = m.storeBuilder.BuildResource(r)
 	}
 }
 
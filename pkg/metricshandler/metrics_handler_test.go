@@ -0,0 +1,426 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/allowdenylist"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestServeHTTP(t *testing.T) {
+	ms := metricsstore.NewMetricsStore(
+		[]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"},
+		func(obj interface{}) []metric.FamilyInterface {
+			p := obj.(*v1.Pod)
+			return []metric.FamilyInterface{metric.Family{
+				Name: "kube_pod_info",
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"pod"},
+						LabelValues: []string{p.Name},
+						Value:       1,
+					},
+				},
+			}}
+		},
+	)
+	if err := ms.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", UID: "uid1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `# HELP kube_pod_info Information about pod.
+# TYPE kube_pod_info gauge
+kube_pod_info{pod="pod1"} 1
+`
+
+	tests := []struct {
+		enableGZIPEncoding bool
+		acceptEncoding     string
+		gzipped            bool
+	}{
+		{enableGZIPEncoding: false, acceptEncoding: "gzip", gzipped: false},
+		{enableGZIPEncoding: true, acceptEncoding: "", gzipped: false},
+		{enableGZIPEncoding: true, acceptEncoding: "deflate, gzip;q=1.0", gzipped: true},
+	}
+
+	storeBuilder := store.NewBuilder()
+	if err := storeBuilder.WithEnabledResources([]string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range tests {
+		m := &MetricsHandler{
+			storeBuilder:       storeBuilder,
+			enableGZIPEncoding: test.enableGZIPEncoding,
+			mtx:                &sync.RWMutex{},
+			stores:             map[string][]cache.Store{"pods": {ms}},
+		}
+
+		// Serve every request twice to cover reused pooled writers.
+		for j := 0; j < 2; j++ {
+			req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+			req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body := resp.Body
+			if test.gzipped {
+				if resp.Header.Get("Content-Encoding") != "gzip" {
+					t.Fatalf("%d: expected gzip content encoding, got %q", i, resp.Header.Get("Content-Encoding"))
+				}
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("%d: %v", i, err)
+				}
+				body = gz
+			}
+
+			got, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+			if string(got) != expected {
+				t.Fatalf("%d: expected:\n%s\ngot:\n%s", i, expected, got)
+			}
+		}
+	}
+}
+
+func TestServeHTTPCollect(t *testing.T) {
+	newStore := func(name string) *metricsstore.MetricsStore {
+		return metricsstore.NewMetricsStore(
+			[]string{"# HELP " + name + " Test.\n# TYPE " + name + " gauge"},
+			func(interface{}) []metric.FamilyInterface { return nil },
+		)
+	}
+
+	storeBuilder := store.NewBuilder()
+	if err := storeBuilder.WithEnabledResources([]string{"pods", "nodes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &MetricsHandler{
+		storeBuilder: storeBuilder,
+		mtx:          &sync.RWMutex{},
+		stores: map[string][]cache.Store{
+			"nodes": {newStore("kube_node_info")},
+			"pods":  {newStore("kube_pod_info")},
+		},
+	}
+
+	tests := []struct {
+		query    string
+		code     int
+		expected string
+	}{
+		{
+			query:    "",
+			code:     200,
+			expected: "# HELP kube_node_info Test.\n# TYPE kube_node_info gauge\n# HELP kube_pod_info Test.\n# TYPE kube_pod_info gauge\n",
+		},
+		{
+			query:    "?collect[]=pods",
+			code:     200,
+			expected: "# HELP kube_pod_info Test.\n# TYPE kube_pod_info gauge\n",
+		},
+		{
+			query:    "?collect[]=pods&collect[]=nodes&collect[]=pods",
+			code:     200,
+			expected: "# HELP kube_node_info Test.\n# TYPE kube_node_info gauge\n# HELP kube_pod_info Test.\n# TYPE kube_pod_info gauge\n",
+		},
+		{
+			query:    "?collect[]=secrets",
+			code:     400,
+			expected: "resource \"secrets\" is not enabled\n",
+		},
+	}
+
+	for i, test := range tests {
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics"+test.query, nil)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Fatalf("%d: expected status code %d, got %d", i, test.code, w.Code)
+		}
+		if w.Body.String() != test.expected {
+			t.Fatalf("%d: expected:\n%s\ngot:\n%s", i, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestServeHTTPNamespace(t *testing.T) {
+	ms := metricsstore.NewMetricsStore(
+		[]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"},
+		func(obj interface{}) []metric.FamilyInterface {
+			p := obj.(*v1.Pod)
+			return []metric.FamilyInterface{metric.Family{
+				Name: "kube_pod_info",
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"namespace", "pod"},
+						LabelValues: []string{p.Namespace, p.Name},
+						Value:       1,
+					},
+				},
+			}}
+		},
+	)
+	for _, p := range []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod1", UID: "uid1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pod1", UID: "uid2"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pod2", UID: "uid3"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "pod1", UID: "uid4"}},
+	} {
+		if err := ms.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	storeBuilder := store.NewBuilder()
+	if err := storeBuilder.WithEnabledResources([]string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+	storeBuilder.WithNamespaces(options.NamespaceList{"default", "team-a"})
+
+	m := &MetricsHandler{
+		storeBuilder: storeBuilder,
+		mtx:          &sync.RWMutex{},
+		stores:       map[string][]cache.Store{"pods": {ms}},
+	}
+
+	const header = "# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge\n"
+	tests := []struct {
+		query    string
+		code     int
+		expected string
+	}{
+		{
+			query:    "?namespace=team-a",
+			code:     200,
+			expected: header + "kube_pod_info{namespace=\"team-a\",pod=\"pod1\"} 1\nkube_pod_info{namespace=\"team-a\",pod=\"pod2\"} 1\n",
+		},
+		{
+			query:    "?namespace=default&collect[]=pods",
+			code:     200,
+			expected: header + "kube_pod_info{namespace=\"default\",pod=\"pod1\"} 1\n",
+		},
+		{
+			query:    "?namespace=team-b",
+			code:     400,
+			expected: "namespace \"team-b\" is not watched\n",
+		},
+		{
+			query:    "?namespace=",
+			code:     400,
+			expected: "exactly one non-empty namespace must be given\n",
+		},
+	}
+
+	for i, test := range tests {
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics"+test.query, nil)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Fatalf("%d: expected status code %d, got %d", i, test.code, w.Code)
+		}
+		if w.Body.String() != test.expected {
+			t.Fatalf("%d: expected:\n%s\ngot:\n%s", i, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestServeObject(t *testing.T) {
+	ms := metricsstore.NewMetricsStore(
+		[]string{"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge"},
+		func(obj interface{}) []metric.FamilyInterface {
+			p := obj.(*v1.Pod)
+			return []metric.FamilyInterface{metric.Family{
+				Name: "kube_pod_info",
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"namespace", "pod"},
+						LabelValues: []string{p.Namespace, p.Name},
+						Value:       1,
+					},
+				},
+			}}
+		},
+	)
+	for _, p := range []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod1", UID: "uid1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod2", UID: "uid2"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "pod1", UID: "uid3"}},
+	} {
+		if err := ms.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	storeBuilder := store.NewBuilder()
+	if err := storeBuilder.WithEnabledResources([]string{"pods", "nodes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &MetricsHandler{
+		storeBuilder:   storeBuilder,
+		mtx:            &sync.RWMutex{},
+		stores:         map[string][]cache.Store{"pods": {ms}},
+		curTotalShards: 1,
+	}
+
+	tests := []struct {
+		query    string
+		code     int
+		expected string
+	}{
+		{
+			query:    "?kind=Pod&namespace=default&name=pod1",
+			code:     200,
+			expected: "# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge\nkube_pod_info{namespace=\"default\",pod=\"pod1\"} 1\n",
+		},
+		{
+			query:    "?kind=pods&namespace=other&name=pod1",
+			code:     200,
+			expected: "# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge\nkube_pod_info{namespace=\"other\",pod=\"pod1\"} 1\n",
+		},
+		{
+			query:    "?kind=Pod&namespace=default&name=pod3",
+			code:     404,
+			expected: "Pod default/pod3 not found in shard 0 of 1\n",
+		},
+		{
+			query:    "?kind=Node&name=node1",
+			code:     404,
+			expected: "resource \"nodes\" has not been started, as its metrics have not been requested yet\n",
+		},
+		{
+			query:    "?kind=Secret&namespace=default&name=secret1",
+			code:     404,
+			expected: "resource \"secrets\" is not enabled\n",
+		},
+		{
+			query:    "?kind=Frobnicator&name=frobnicator1",
+			code:     400,
+			expected: "unknown kind \"Frobnicator\"\n",
+		},
+		{
+			query:    "?kind=Pod",
+			code:     400,
+			expected: "kind and name query parameters are required\n",
+		},
+	}
+
+	for i, test := range tests {
+		req := httptest.NewRequest("GET", "http://localhost:8080/debug/object"+test.query, nil)
+		w := httptest.NewRecorder()
+		m.ServeObject(w, req)
+
+		if w.Code != test.code {
+			t.Fatalf("%d: expected status code %d, got %d", i, test.code, w.Code)
+		}
+		if w.Body.String() != test.expected {
+			t.Fatalf("%d: expected:\n%s\ngot:\n%s", i, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestLazyResources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l, err := allowdenylist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storeBuilder := store.NewBuilder()
+	storeBuilder.WithMetrics(prometheus.NewRegistry())
+	if err := storeBuilder.WithEnabledResources([]string{"configmaps", "pods", "secrets"}); err != nil {
+		t.Fatal(err)
+	}
+	storeBuilder.WithKubeClient(fake.NewSimpleClientset())
+	storeBuilder.WithNamespaces(options.DefaultNamespaces)
+	storeBuilder.WithAllowDenyList(l)
+	storeBuilder.WithGenerateStoreFunc(storeBuilder.DefaultGenerateStoreFunc())
+
+	m := New(&options.Options{LazyResources: true}, nil, storeBuilder, false)
+
+	started := func() []string {
+		m.mtx.RLock()
+		defer m.mtx.RUnlock()
+		resources := []string{}
+		for _, r := range storeBuilder.EnabledResources() {
+			if len(m.stores[r]) > 0 {
+				resources = append(resources, r)
+			}
+		}
+		return resources
+	}
+
+	scrape := func(query string) {
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics"+query, nil)
+		m.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Resources requested before sharding is configured are started once
+	// it is.
+	scrape("?collect[]=secrets")
+	if got := started(); len(got) != 0 {
+		t.Fatalf("expected no started resources, got %v", got)
+	}
+
+	m.ConfigureSharding(ctx, 0, 1)
+	if got := strings.Join(started(), ","); got != "secrets" {
+		t.Fatalf("expected started resources secrets, got %s", got)
+	}
+
+	scrape("?collect[]=pods")
+	if got := strings.Join(started(), ","); got != "pods,secrets" {
+		t.Fatalf("expected started resources pods,secrets, got %s", got)
+	}
+
+	// Re-configuring sharding only restarts the requested resources.
+	m.ConfigureSharding(ctx, 0, 2)
+	if got := strings.Join(started(), ","); got != "pods,secrets" {
+		t.Fatalf("expected started resources pods,secrets, got %s", got)
+	}
+
+	scrape("")
+	if got := strings.Join(started(), ","); got != "configmaps,pods,secrets" {
+		t.Fatalf("expected all resources to be started, got %s", got)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"io"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// keepPreviousStores keeps the current stores, which are about to be
+// replaced, to be served until the new stores have listed their objects. If
+// the current stores have not listed their objects themselves, e.g. as the
+// apiserver is unreachable, the stores they replaced are kept instead.
+// m.mtx must be held for writing.
+func (m *MetricsHandler) keepPreviousStores() {
+	synced := m.synced()
+
+	m.previousMtx.Lock()
+	defer m.previousMtx.Unlock()
+
+	if m.previous == nil || synced {
+		m.previous = m.stores
+	}
+}
+
+// previousStores returns the stores replaced by the last rebuild, or nil
+// once the current stores have listed their objects. m.mtx must be held for
+// reading.
+func (m *MetricsHandler) previousStores() map[string][]cache.Store {
+	m.previousMtx.Lock()
+	defer m.previousMtx.Unlock()
+
+	if m.previous != nil && m.synced() {
+		klog.Info("Serving the rebuilt stores, as they have listed their objects")
+		m.previous = nil
+	}
+	return m.previous
+}
+
+// Reasons of kube_state_metrics_data_stale.
+const (
+	// staleReasonCache is the reason of metrics served from the metric
+	// cache persisted by a previous run.
+	staleReasonCache = "cache"
+	// staleReasonRebuild is the reason of metrics served from the stores
+	// replaced by the last rebuild.
+	staleReasonRebuild = "rebuild"
+	// staleReasonListWatch is the reason of metrics of stores whose last
+	// list or watch failed.
+	staleReasonListWatch = "list_watch_failed"
+)
+
+// staleReasons are all reasons of kube_state_metrics_data_stale, which is
+// written for every reason so that the series do not come and go.
+var staleReasons = []string{staleReasonCache, staleReasonRebuild, staleReasonListWatch}
+
+// writeDataStale writes whether the served metrics may be outdated for each
+// reason, with the given reasons applying.
+func writeDataStale(w io.Writer, reasons ...string) {
+	io.WriteString(w, "# HELP kube_state_metrics_data_stale Whether the served metrics may be outdated, by reason: served from the metric cache of a previous run, served from the stores replaced by a rebuild, or listing or watching objects failed, e.g. as the apiserver is unreachable.\n# TYPE kube_state_metrics_data_stale gauge\n")
+	for _, reason := range staleReasons {
+		value := "0"
+		for _, r := range reasons {
+			if r == reason {
+				value = "1"
+			}
+		}
+		io.WriteString(w, "kube_state_metrics_data_stale{reason=\""+reason+"\"} "+value+"\n")
+	}
+}
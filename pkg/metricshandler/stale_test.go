@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/allowdenylist"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestServeStaleMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l, err := allowdenylist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "configmap1", ResourceVersion: "1"}})
+	var unreachable int32
+	client.PrependReactor("list", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if atomic.LoadInt32(&unreachable) == 1 {
+			return true, nil, context.DeadlineExceeded
+		}
+		return false, nil, nil
+	})
+
+	storeBuilder := store.NewBuilder()
+	storeBuilder.WithMetrics(prometheus.NewRegistry())
+	if err := storeBuilder.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	storeBuilder.WithKubeClient(client)
+	storeBuilder.WithNamespaces(options.DefaultNamespaces)
+	storeBuilder.WithAllowDenyList(l)
+	storeBuilder.WithGenerateStoreFunc(storeBuilder.DefaultGenerateStoreFunc())
+
+	m := New(&options.Options{ServeStaleMetrics: true}, nil, storeBuilder, false)
+
+	const series = `kube_configmap_info{namespace="default",configmap="configmap1"} 1`
+	waitFor := func(stale ...string) {
+		err := wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/metrics", nil))
+			body := w.Body.String()
+			if !strings.Contains(body, series) {
+				return false, nil
+			}
+			for _, reason := range staleReasons {
+				value := "0"
+				for _, r := range stale {
+					if r == reason {
+						value = "1"
+					}
+				}
+				if !strings.Contains(body, "kube_state_metrics_data_stale{reason=\""+reason+"\"} "+value+"\n") {
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			t.Fatalf("expected metrics of configmap1 stale for reasons %v: %v", stale, err)
+		}
+	}
+
+	m.ConfigureSharding(ctx, 0, 1)
+	waitFor()
+
+	// The stores rebuilt while the apiserver is unreachable can not list
+	// their objects, so the replaced stores are served.
+	atomic.StoreInt32(&unreachable, 1)
+	m.ConfigureSharding(ctx, 0, 1)
+	waitFor(staleReasonRebuild, staleReasonListWatch)
+
+	atomic.StoreInt32(&unreachable, 0)
+	waitFor()
+}
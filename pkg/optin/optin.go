@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package optin
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MetricFamilyFilter decides which opt-in metric families have been enabled.
+type MetricFamilyFilter struct {
+	list  []string
+	rList []*regexp.Regexp
+}
+
+// NewMetricFamilyFilter constructs a new MetricFamilyFilter from a set of
+// exact metric names and/or regex patterns. Patterns have to match the whole
+// metric name, so that opting into one metric never enables another one that
+// merely shares its prefix.
+func NewMetricFamilyFilter(metrics map[string]struct{}) (*MetricFamilyFilter, error) {
+	list := make([]string, 0, len(metrics))
+	regexes := make([]*regexp.Regexp, 0, len(metrics))
+	for metric := range metrics {
+		r, err := regexp.Compile("^(?:" + metric + ")$")
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, metric)
+		regexes = append(regexes, r)
+	}
+	sort.Strings(list)
+
+	return &MetricFamilyFilter{
+		list:  list,
+		rList: regexes,
+	}, nil
+}
+
+// IsOptedIn returns whether the given opt-in metric family has been enabled.
+func (f *MetricFamilyFilter) IsOptedIn(name string) bool {
+	for _, r := range f.rList {
+		if r.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the status of the MetricFamilyFilter that can e.g. be passed
+// into a logger.
+func (f *MetricFamilyFilter) Status() string {
+	return "Including the following opt-in metrics: " + strings.Join(f.list, ", ")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package optin
+
+import (
+	"testing"
+)
+
+func TestNewMetricFamilyFilter(t *testing.T) {
+	t.Run("fails with an invalid regex", func(t *testing.T) {
+		_, err := NewMetricFamilyFilter(map[string]struct{}{"kube_(": {}})
+		if err == nil {
+			t.Fatal("expected NewMetricFamilyFilter() to fail with an invalid regex")
+		}
+	})
+}
+
+func TestIsOptedIn(t *testing.T) {
+	f, err := NewMetricFamilyFilter(map[string]struct{}{
+		"kube_secret_tls_cert_not_after": {},
+		"kube_node_.*_requests":          {},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"kube_secret_tls_cert_not_after", true},
+		{"kube_secret_tls_cert_not_after_seconds", false},
+		{"kube_node_cpu_requests", true},
+		{"kube_pod_info", false},
+	}
+	for _, test := range tests {
+		if got := f.IsOptedIn(test.name); got != test.want {
+			t.Errorf("IsOptedIn(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	empty, err := NewMetricFamilyFilter(map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty.IsOptedIn("kube_secret_tls_cert_not_after") {
+		t.Error("expected empty filter to not opt in any metric")
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// reloadableFlags holds the flags which can be set in the file given by
+// --config, and thereby be changed at runtime by reloading it.
+var reloadableFlags = map[string]func(o *Options, value string) error{
+	"namespace": func(o *Options, value string) error {
+		o.Namespaces = NamespaceList{}
+		return o.Namespaces.Set(value)
+	},
+	"metric-allowlist": func(o *Options, value string) error {
+		o.MetricAllowlist = MetricSet{}
+		return o.MetricAllowlist.Set(value)
+	},
+	"metric-denylist": func(o *Options, value string) error {
+		o.MetricDenylist = MetricSet{}
+		return o.MetricDenylist.Set(value)
+	},
+	"metric-annotations-allowlist": func(o *Options, value string) error {
+		o.AnnotationsAllowList = AnnotationsAllowList{}
+		return o.AnnotationsAllowList.Set(value)
+	},
+	"namespace-label-selector": func(o *Options, value string) error {
+		o.NamespaceLabelSelector = value
+		return nil
+	},
+	"resource-label-exclude-selector": func(o *Options, value string) error {
+		o.ResourceLabelExcludeSelectors = ResourceLabelSelectors{}
+		return o.ResourceLabelExcludeSelectors.Set(value)
+	},
+}
+
+// LoadConfig returns a copy of the options with the flags set in the file
+// given by --config applied on top of the command line arguments. The file
+// maps flag names to their values in the command line syntax, e.g.
+// "metric-denylist: kube_secret_.*". Only the flags in reloadableFlags may be
+// set. As --namespace and --namespace-label-selector are mutually exclusive,
+// setting one of them in the file unsets the other one given on the command
+// line. Without --config, the copy equals the options.
+func (o *Options) LoadConfig() (*Options, error) {
+	loaded := *o
+	if o.ConfigFile == "" {
+		return &loaded, nil
+	}
+
+	data, err := ioutil.ReadFile(o.ConfigFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "read config file")
+	}
+
+	config := map[string]string{}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "parse config file %s", o.ConfigFile)
+	}
+
+	for name, value := range config {
+		set, ok := reloadableFlags[name]
+		if !ok {
+			return nil, errors.Errorf("flag %s can not be set in the config file. Reloadable flags: %s", name, strings.Join(reloadableFlagNames(), ","))
+		}
+		if err := set(&loaded, value); err != nil {
+			return nil, errors.Wrapf(err, "invalid value of %s in config file", name)
+		}
+	}
+
+	_, namespaces := config["namespace"]
+	_, namespaceLabelSelector := config["namespace-label-selector"]
+	if namespaces && !namespaceLabelSelector {
+		loaded.NamespaceLabelSelector = ""
+	}
+	if namespaceLabelSelector && !namespaces {
+		loaded.Namespaces = nil
+	}
+
+	return &loaded, nil
+}
+
+func reloadableFlagNames() []string {
+	names := make([]string, 0, len(reloadableFlags))
+	for name := range reloadableFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
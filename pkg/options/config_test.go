@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ksm-options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		Desc    string
+		Config  string
+		Want    *Options
+		WantErr bool
+	}{
+		{
+			Desc:   "empty config file",
+			Config: "",
+			Want: &Options{
+				Namespaces:     NamespaceList{"default"},
+				MetricDenylist: MetricSet{"kube_secret_info": {}},
+			},
+		},
+		{
+			Desc: "config file overriding flags",
+			Config: `namespace: kube-system, monitoring
+metric-denylist: kube_pod_.*
+metric-annotations-allowlist: pods=[team,owner]
+`,
+			Want: &Options{
+				Namespaces:           NamespaceList{"kube-system", "monitoring"},
+				MetricDenylist:       MetricSet{"kube_pod_.*": {}},
+				AnnotationsAllowList: AnnotationsAllowList{"pods": {"team", "owner"}},
+			},
+		},
+		{
+			Desc: "config file setting label selectors",
+			Config: `namespace-label-selector: team=a
+resource-label-exclude-selector: pods=[ephemeral=true]
+`,
+			Want: &Options{
+				MetricDenylist:                MetricSet{"kube_secret_info": {}},
+				NamespaceLabelSelector:        "team=a",
+				ResourceLabelExcludeSelectors: ResourceLabelSelectors{"pods": "ephemeral=true"},
+			},
+		},
+		{
+			Desc:    "flag which can not be reloaded",
+			Config:  "resources: pods\n",
+			WantErr: true,
+		},
+		{
+			Desc:    "invalid annotations allowlist",
+			Config:  "metric-annotations-allowlist: pods\n",
+			WantErr: true,
+		},
+		{
+			Desc:    "invalid YAML",
+			Config:  "namespace: [",
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(dir, "config.yaml")
+		if err := ioutil.WriteFile(path, []byte(test.Config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		opts := &Options{
+			ConfigFile:     path,
+			Namespaces:     NamespaceList{"default"},
+			MetricDenylist: MetricSet{"kube_secret_info": {}},
+		}
+		got, err := opts.LoadConfig()
+		if test.WantErr {
+			if err == nil {
+				t.Errorf("Test error for Desc: %s. Expected error", test.Desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+			continue
+		}
+
+		test.Want.ConfigFile = path
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Want, got)
+		}
+		if !reflect.DeepEqual(opts.Namespaces, NamespaceList{"default"}) {
+			t.Errorf("Test error for Desc: %s. Options were modified: %+v", test.Desc, opts)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/klog"
 
@@ -28,13 +29,38 @@ import (
 
 // Options are the configurable parameters for kube-state-metrics.
 type Options struct {
-	Apiserver       string
-	Kubeconfig      string
-	Help            bool
-	Port            int
-	Host            string
-	TelemetryPort   int
-	TelemetryHost   string
+	Apiserver  string
+	Kubeconfig string
+	ProxyURL   string
+
+	APIServerCAFile             string
+	APIServerInsecureSkipVerify bool
+	APIServerClientCertFile     string
+	APIServerClientKeyFile      string
+
+	Help         bool
+	Port         int
+	Hosts        []string
+	ListenSocket string
+
+	// Host is the host to expose metrics on. If set, it takes precedence
+	// over Hosts.
+	//
+	// Deprecated: Use Hosts instead.
+	Host string
+
+	SourceCIDRAllowlist       CIDRList
+	ScrapesPerMinutePerClient int
+	ScrapeAuthorization       bool
+
+	TelemetryPort  int
+	TelemetryHosts []string
+	// TelemetryHost is the host to expose kube-state-metrics self metrics
+	// on. If set, it takes precedence over TelemetryHosts.
+	//
+	// Deprecated: Use TelemetryHosts instead.
+	TelemetryHost string
+
 	Resources       ResourceSet
 	Namespaces      NamespaceList
 	Shard           int32
@@ -43,10 +69,68 @@ type Options struct {
 	Namespace       string
 	MetricDenylist  MetricSet
 	MetricAllowlist MetricSet
+	MetricOptInList MetricSet
 	Version         bool
 
+	ListCollectorsJSON bool
+	ConfigFile         string
+	EnableReloadAPI    bool
+
+	NamespaceLabelSelector string
+	LazyResources          bool
+
+	AnnotationsAllowList AnnotationsAllowList
+	CustomLabels         CustomLabels
+	TrackUnscheduledPods bool
+
+	ObjectOptInAnnotation string
+	ObjectOptInResources  ResourceSet
+
+	ResourceLabelExcludeSelectors ResourceLabelSelectors
+
+	NamespaceSeriesLimit int
+
+	OnlyCurrentConditionStatus bool
+	SampleTimestamps           bool
+	UIDLabel                   bool
+
+	MetricLabelsMaxCount         int
+	MetricLabelsValueLengthLimit int
+	MetricLabelsHashLongValues   bool
+	MetricLabelsNameScheme       string
+
+	KubeAPIQPS         float32
+	KubeAPIBurst       int
+	KubeAPIBackoffBase time.Duration
+	KubeAPIBackoffMax  time.Duration
+
+	ServerReadTimeout    time.Duration
+	ServerWriteTimeout   time.Duration
+	ServerIdleTimeout    time.Duration
+	ServerMaxHeaderBytes int
+	ServerEnableH2C      bool
+
 	EnableGZIPEncoding bool
 
+	ExportURL     string
+	ExportSubject string
+
+	EnableStreamAPI bool
+
+	MetricAuditLog bool
+
+	MetricCacheFile     string
+	MetricCacheInterval time.Duration
+	MetricCacheMaxAge   time.Duration
+
+	ServeStaleMetrics bool
+
+	CloudMonitoring            bool
+	CloudMonitoringInterval    time.Duration
+	CloudMonitoringProject     string
+	CloudMonitoringLocation    string
+	CloudMonitoringClusterName string
+
 	flags *pflag.FlagSet
 }
 
@@ -56,6 +140,13 @@ func NewOptions() *Options {
 		Resources:       ResourceSet{},
 		MetricAllowlist: MetricSet{},
 		MetricDenylist:  MetricSet{},
+		MetricOptInList: MetricSet{},
+
+		AnnotationsAllowList: AnnotationsAllowList{},
+		CustomLabels:         CustomLabels{},
+
+		ObjectOptInResources:          ResourceSet{},
+		ResourceLabelExcludeSelectors: ResourceLabelSelectors{},
 	}
 }
 
@@ -77,15 +168,35 @@ func (o *Options) AddFlags() {
 
 	o.flags.StringVar(&o.Apiserver, "apiserver", "", `The URL of the apiserver to use as a master`)
 	o.flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "Absolute path to the kubeconfig file")
+	o.flags.StringVar(&o.APIServerCAFile, "apiserver-ca-file", "", "Path to the PEM encoded CA certificates used to verify the certificate of the apiserver, overriding the CA of the kubeconfig or the service account.")
+	o.flags.BoolVar(&o.APIServerInsecureSkipVerify, "apiserver-insecure-skip-verify", false, "Do not verify the certificate of the apiserver. This makes the connection to the apiserver insecure and is only meant for test environments.")
+	o.flags.StringVar(&o.APIServerClientCertFile, "apiserver-client-cert-file", "", "Path to the PEM encoded client certificate used to authenticate to the apiserver. Requires --apiserver-client-key-file.")
+	o.flags.StringVar(&o.APIServerClientKeyFile, "apiserver-client-key-file", "", "Path to the PEM encoded private key of the client certificate given by --apiserver-client-cert-file.")
+	o.flags.StringVar(&o.ProxyURL, "proxy-url", "", "URL of the HTTP proxy to connect to the apiserver through, e.g. http://proxy.example.com:3128. Defaults to the proxy given by the HTTPS_PROXY and NO_PROXY environment variables.")
+	o.flags.Float32Var(&o.KubeAPIQPS, "kube-api-qps", 5, "Maximum number of requests per second to the apiserver.")
+	o.flags.IntVar(&o.KubeAPIBurst, "kube-api-burst", 10, "Maximum number of requests to the apiserver in a burst above --kube-api-qps.")
+	o.flags.DurationVar(&o.KubeAPIBackoffBase, "kube-api-backoff-base", time.Second, "Time waited before retrying the first failed list and watch of a resource. The time is doubled for every consecutive failure up to --kube-api-backoff-max.")
+	o.flags.DurationVar(&o.KubeAPIBackoffMax, "kube-api-backoff-max", 5*time.Minute, "Maximum time waited before retrying a failed list and watch of a resource.")
 	o.flags.BoolVarP(&o.Help, "help", "h", false, "Print Help text")
 	o.flags.IntVar(&o.Port, "port", 8080, `Port to expose metrics on.`)
-	o.flags.StringVar(&o.Host, "host", "0.0.0.0", `Host to expose metrics on.`)
+	o.flags.StringSliceVar(&o.Hosts, "host", []string{"0.0.0.0"}, `Comma-separated list of hosts to expose metrics on, e.g. 10.0.0.10,fd00::10 to expose metrics on both the IPv4 and the IPv6 address of the pod in dual-stack clusters. 0.0.0.0 exposes metrics on all addresses of both families.`)
+	o.flags.Var(&o.SourceCIDRAllowlist, "source-cidr-allowlist", "Comma-separated list of CIDRs or IP addresses of the clients allowed to send requests to the metrics server, e.g. 10.0.0.0/8,192.168.1.10. Requests of other clients are rejected, except for requests to /healthz. All clients are allowed if empty. Mutually exclusive with --listen-socket.")
+	o.flags.BoolVar(&o.ScrapeAuthorization, "scrape-authorization", false, "Authenticate the bearer tokens of the clients of the metrics port with TokenReviews, and only serve /metrics, /debug/object and /stream if their user may list the API resources of the requested resources in the requested namespace, e.g. /metrics?namespace=team-a, or in all namespaces otherwise, according to SubjectAccessReviews. /-/reload and /debug/pprof/ are authorized as non-resource URLs. Decisions are cached for a minute. Requires kube-state-metrics to be allowed to create TokenReviews and SubjectAccessReviews.")
+	o.flags.IntVar(&o.ScrapesPerMinutePerClient, "scrapes-per-minute-per-client", 0, "Maximum number of scrapes of /metrics per minute of each client, identified by its IP address. A client may use up the limit in a burst. Further scrapes are rejected with 429 Too Many Requests. 0 disables the limit.")
+	o.flags.StringVar(&o.ListenSocket, "listen-socket", "", "Path of a Unix domain socket to expose metrics on instead of --host and --port, e.g. /var/run/ksm.sock, so that a sidecar can scrape kube-state-metrics without exposing a port on the pod network.")
 	o.flags.IntVar(&o.TelemetryPort, "telemetry-port", 8081, `Port to expose kube-state-metrics self metrics on.`)
-	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", `Host to expose kube-state-metrics self metrics on.`)
+	o.flags.StringSliceVar(&o.TelemetryHosts, "telemetry-host", []string{"0.0.0.0"}, `Comma-separated list of hosts to expose kube-state-metrics self metrics on.`)
 	o.flags.Var(&o.Resources, "resources", fmt.Sprintf("Comma-separated list of Resources to be enabled. Defaults to %q", &DefaultResources))
+	o.flags.BoolVar(&o.LazyResources, "lazy-resources", false, "Only list and watch the objects of a resource once its metrics are first requested, either by a scrape of all metrics or by a scrape selecting the resource with the collect[] query parameter, e.g. /metrics?collect[]=pods. The first scrape of a resource returns no metrics of its objects while they are being listed.")
+	o.flags.StringVar(&o.ConfigFile, "config", "", "Path to a YAML file setting any of the flags --namespace, --namespace-label-selector, --metric-allowlist, --metric-denylist, --metric-annotations-allowlist and --resource-label-exclude-selector by name, e.g. 'metric-denylist: kube_secret_.*'. Values in the file take precedence over the command line. The file is reloaded on SIGHUP, or on a POST request to /-/reload with --enable-reload-api.")
+	o.flags.BoolVar(&o.EnableReloadAPI, "enable-reload-api", false, "Serve /-/reload on the metrics port, which reloads the file given by --config on POST requests. As a reload lists the objects of all resources again, only enable it if the clients reaching the metrics port are trusted. Requires --config.")
 	o.flags.Var(&o.Namespaces, "namespace", fmt.Sprintf("Comma-separated list of namespaces to be enabled. Defaults to %q", &DefaultNamespaces))
+	o.flags.StringVar(&o.NamespaceLabelSelector, "namespace-label-selector", "", "Label selector of the namespaces to be enabled, e.g. team=payments. Namespaces are enabled and disabled as they gain and lose matching labels. Mutually exclusive with --namespace.")
 	o.flags.Var(&o.MetricAllowlist, "metric-allowlist", "Comma-separated list of metrics to be exposed. This list comprises of exact metric names and/or regex patterns. The allowlist and denylist are mutually exclusive.")
 	o.flags.Var(&o.MetricDenylist, "metric-denylist", "Comma-separated list of metrics not to be enabled. This list comprises of exact metric names and/or regex patterns. The allowlist and denylist are mutually exclusive.")
+	o.flags.Var(&o.MetricOptInList, "metric-opt-in-list", "Comma-separated list of metrics which are opt-in and not enabled by default. This list comprises of exact metric names and/or regex patterns. This is in addition to the metric allow- and denylists.")
+	o.flags.Var(&o.AnnotationsAllowList, "metric-annotations-allowlist", "Comma-separated list of Kubernetes annotation keys that will be used in the resource's annotations metric, e.g. pods=[team,example.com/owner],deployments=[*]. A single '*' exposes all annotations of a resource.")
+	o.flags.Var(&o.CustomLabels, "custom-labels", "Comma-separated list of name=value pairs of static labels added to every exposed series, e.g. cluster=prod-eu1,region=eu-west-1. A custom label is not added to series which already have a label with the same name.")
 	o.flags.Int32Var(&o.Shard, "shard", int32(0), "The instances shard nominal (zero indexed) within the total number of shards. (default 0)")
 	o.flags.IntVar(&o.TotalShards, "total-shards", 1, "The total number of shards. Sharding is disabled when total shards is set to 1.")
 
@@ -94,7 +205,57 @@ func (o *Options) AddFlags() {
 	o.flags.StringVar(&o.Pod, "pod", "", "Name of the pod that contains the kube-state-metrics container. "+autoshardingNotice)
 	o.flags.StringVar(&o.Namespace, "pod-namespace", "", "Name of the namespace of the pod specified by --pod. "+autoshardingNotice)
 	o.flags.BoolVarP(&o.Version, "version", "", false, "kube-state-metrics build version information")
+	o.flags.BoolVar(&o.ListCollectorsJSON, "list-collectors-json", false, "Print all available collectors, the API resources they list and watch and the metric families they expose as JSON and exit.")
+	o.flags.BoolVar(&o.TrackUnscheduledPods, "track-unscheduled-pods", true, "Expose pods which have not been scheduled to a node yet. Disabling this only lists and watches pods with a non-empty spec.nodeName, e.g. when every kube-state-metrics instance only collects the pods of its own node.")
+	o.flags.StringVar(&o.ObjectOptInAnnotation, "object-opt-in-annotation", "", "Annotation in the form key=value, e.g. metrics.example.com/scrape=true, which the objects of the resources given by --object-opt-in-resources must carry to be exposed. Objects losing the annotation are removed. Requires --object-opt-in-resources.")
+	o.flags.Var(&o.ObjectOptInResources, "object-opt-in-resources", "Comma-separated list of resources, e.g. pods, of which only the objects carrying the annotation given by --object-opt-in-annotation are exposed. All objects of the other resources are exposed.")
+	o.flags.Var(&o.ResourceLabelExcludeSelectors, "resource-label-exclude-selector", "Comma-separated list of label selectors per resource, e.g. pods=[ephemeral=true],jobs=[ci in (true)]. Objects matching the selector of their resource are not exposed, and are removed once they gain matching labels.")
+	o.flags.IntVar(&o.NamespaceSeriesLimit, "namespace-series-limit", 0, "Maximum number of series of every resource exposed per namespace. The limit applies to each resource separately. Once an object of a namespace, in the order of their names, exceeds the limit, it and all further objects of the namespace are not exposed, and kube_state_metrics_namespace_series_dropped counts their series. Cluster-scoped objects and the opt-in metric families joining several objects are not limited. 0 disables the limit.")
+	o.flags.BoolVar(&o.OnlyCurrentConditionStatus, "only-current-condition-status", false, "Only expose the series of the current status of a condition, e.g. kube_node_status_condition{status=\"true\"} for a node which is ready, instead of one series for each of true, false and unknown.")
+	o.flags.BoolVar(&o.UIDLabel, "metric-uid-label", false, "Add the UID of the object as uid label to the info and created metrics, e.g. kube_pod_created, to tell apart recreated objects of the same name.")
+	o.flags.BoolVar(&o.SampleTimestamps, "sample-timestamps", false, "Expose the last transition times of conditions as the timestamps of the samples of condition metrics, e.g. kube_node_status_condition. Meant for systems other than Prometheus ingesting the exposition format, as Prometheus rejects samples with timestamps too far in the past.")
+	o.flags.IntVar(&o.MetricLabelsMaxCount, "metric-labels-max-count", 0, "Maximum number of Kubernetes labels or annotations of an object exposed by the kube_*_labels and kube_*_annotations metrics. Labels beyond the limit are dropped in alphabetical order of their names. 0 disables the limit.")
+	o.flags.IntVar(&o.MetricLabelsValueLengthLimit, "metric-labels-value-length-limit", 0, "Maximum length of the Kubernetes label and annotation values exposed by the kube_*_labels and kube_*_annotations metrics. Longer values are truncated. 0 disables the limit.")
+	o.flags.BoolVar(&o.MetricLabelsHashLongValues, "metric-labels-hash-long-values", false, "Replace label and annotation values longer than --metric-labels-value-length-limit by their 64 bit FNV-1a hash instead of truncating them.")
+	o.flags.StringVar(&o.MetricLabelsNameScheme, "metric-labels-name-scheme", "underscore", "How Kubernetes label and annotation keys are converted by the kube_*_labels and kube_*_annotations metrics. One of 'underscore' (app.kubernetes.io/name becomes label_app_kubernetes_io_name), 'strip-prefix' (app.kubernetes.io/name becomes label_name) or 'generic' (one series per key with the unmodified key and value as the key and value labels).")
+	o.flags.DurationVar(&o.ServerReadTimeout, "server-read-timeout", time.Minute, "Maximum duration for reading a request, including its body, by the metrics and telemetry servers. 0 disables the timeout.")
+	o.flags.DurationVar(&o.ServerWriteTimeout, "server-write-timeout", 0, "Maximum duration for writing a response by the metrics and telemetry servers. Scrapes taking longer are cut off, so it must exceed the duration of a scrape in large clusters. Streams of /stream over HTTP/1.1 are exempt. 0 disables the timeout.")
+	o.flags.DurationVar(&o.ServerIdleTimeout, "server-idle-timeout", 5*time.Minute, "Maximum duration the metrics and telemetry servers keep idle connections open between requests. 0 uses --server-read-timeout instead.")
+	o.flags.IntVar(&o.ServerMaxHeaderBytes, "server-max-header-bytes", 1<<20, "Maximum size of the request headers read by the metrics and telemetry servers, in bytes.")
+	o.flags.BoolVar(&o.ServerEnableH2C, "server-enable-h2c", false, "Serve HTTP/2 without TLS (h2c) to clients with prior knowledge on the metrics and telemetry ports, besides HTTP/1.1. Upgrades from HTTP/1.1 to HTTP/2 are not supported.")
 	o.flags.BoolVar(&o.EnableGZIPEncoding, "enable-gzip-encoding", false, "Gzip responses when requested by clients via 'Accept-Encoding: gzip' header.")
+	o.flags.StringVar(&o.ExportURL, "export-url", "", "URL of a NATS server to publish the changes of the metrics of objects to as JSON messages, e.g. nats://nats.example.com:4222, or tls://nats.example.com:4222 to require TLS. A token or user and password may be given in the URL. Disabled if empty.")
+	o.flags.StringVar(&o.ExportSubject, "export-subject", "kube-state-metrics", "Subject the changes of the metrics of objects are published to with --export-url.")
+	o.flags.BoolVar(&o.MetricAuditLog, "metric-audit-log", false, "Log the series of objects which appeared, disappeared or changed their value class, e.g. from zero to positive, between updates of the objects as JSON records. Meant for debugging flapping metrics and validating changes of collectors, as all objects are logged once they are listed.")
+	o.flags.StringVar(&o.MetricCacheFile, "metric-cache-file", "", "File to persist the metrics to, e.g. on a persistent volume, so that they are served by the next run of kube-state-metrics until it has listed all objects. Scrapes served from the file expose kube_state_metrics_data_stale{reason=\"cache\"} 1. Scrapes selecting resources or a namespace are never served from the file. Disabled if empty.")
+	o.flags.DurationVar(&o.MetricCacheInterval, "metric-cache-interval", time.Minute, "Interval at which the metrics are persisted to --metric-cache-file.")
+	o.flags.DurationVar(&o.MetricCacheMaxAge, "metric-cache-max-age", time.Hour, "Maximum age of the metrics persisted to --metric-cache-file to be served, e.g. if a resource can not be listed after a restart.")
+	o.flags.BoolVar(&o.ServeStaleMetrics, "serve-stale-metrics", false, "Keep serving the last known metrics while the apiserver is unreachable, including the metrics of the stores replaced on resharding or a reload until the new stores have listed their objects, and expose kube_state_metrics_data_stale 1 with the reason while the served metrics may be outdated. The replaced stores are kept in memory along with the new ones until then.")
+	o.flags.BoolVar(&o.EnableStreamAPI, "enable-stream-api", false, "Serve /stream, which streams a snapshot of the metrics of all objects followed by the changes of their metrics as newline delimited JSON messages.")
+	o.flags.BoolVar(&o.CloudMonitoring, "cloud-monitoring", false, "Export the metrics to Google Cloud Monitoring, formerly Stackdriver, as custom metrics, e.g. custom.googleapis.com/kube_state_metrics/kube_pod_info. Authenticates as the service account of the metadata server of GCE and GKE.")
+	o.flags.DurationVar(&o.CloudMonitoringInterval, "cloud-monitoring-interval", time.Minute, "Interval of exports to Cloud Monitoring. Must be at least 5s.")
+	o.flags.StringVar(&o.CloudMonitoringProject, "cloud-monitoring-project", "", "Project to export the metrics to with --cloud-monitoring. Defaults to the project of the metadata server.")
+	o.flags.StringVar(&o.CloudMonitoringLocation, "cloud-monitoring-location", "", "Location of the cluster, e.g. europe-west1, in the monitored resources of the metrics exported with --cloud-monitoring. Defaults to the cluster-location attribute of the metadata server.")
+	o.flags.StringVar(&o.CloudMonitoringClusterName, "cloud-monitoring-cluster-name", "", "Name of the cluster in the monitored resources of the metrics exported with --cloud-monitoring. Defaults to the cluster-name attribute of the metadata server.")
+}
+
+// ListenHosts returns the hosts to expose metrics on, which is Host if the
+// deprecated field is set and Hosts otherwise.
+func (o *Options) ListenHosts() []string {
+	if o.Host != "" {
+		return []string{o.Host}
+	}
+	return o.Hosts
+}
+
+// TelemetryListenHosts returns the hosts to expose kube-state-metrics self
+// metrics on, which is TelemetryHost if the deprecated field is set and
+// TelemetryHosts otherwise.
+func (o *Options) TelemetryListenHosts() []string {
+	if o.TelemetryHost != "" {
+		return []string{o.TelemetryHost}
+	}
+	return o.TelemetryHosts
 }
 
 // Parse parses the flag definitions from the argument list.
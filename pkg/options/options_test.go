@@ -18,6 +18,7 @@ package options
 
 import (
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 
@@ -40,6 +41,11 @@ func TestOptionsParse(t *testing.T) {
 			Args:           []string{"./kube-state-metrics", "--namespace=default,kube-system"},
 			RecoverInvoked: false,
 		},
+		{
+			Desc:           "track unscheduled pods command line argument",
+			Args:           []string{"./kube-state-metrics", "--track-unscheduled-pods=false"},
+			RecoverInvoked: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -73,3 +79,26 @@ func TestOptionsParse(t *testing.T) {
 		}
 	}
 }
+
+func TestListenHosts(t *testing.T) {
+	opts := NewOptions()
+	opts.Hosts = []string{"10.0.0.10", "fd00::10"}
+	opts.TelemetryHosts = []string{"0.0.0.0"}
+
+	if hosts := opts.ListenHosts(); !reflect.DeepEqual(hosts, opts.Hosts) {
+		t.Errorf("expected hosts %v, got %v", opts.Hosts, hosts)
+	}
+	if hosts := opts.TelemetryListenHosts(); !reflect.DeepEqual(hosts, opts.TelemetryHosts) {
+		t.Errorf("expected telemetry hosts %v, got %v", opts.TelemetryHosts, hosts)
+	}
+
+	opts.Host = "127.0.0.1"
+	opts.TelemetryHost = "::1"
+
+	if hosts := opts.ListenHosts(); !reflect.DeepEqual(hosts, []string{"127.0.0.1"}) {
+		t.Errorf("expected deprecated host to take precedence, got %v", hosts)
+	}
+	if hosts := opts.TelemetryListenHosts(); !reflect.DeepEqual(hosts, []string{"::1"}) {
+		t.Errorf("expected deprecated telemetry host to take precedence, got %v", hosts)
+	}
+}
@@ -17,12 +17,17 @@ limitations under the License.
 package options
 
 import (
+	"net"
+	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+var labelNameRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 // MetricSet represents a collection which has a unique set of metrics.
 type MetricSet map[string]struct{}
 
@@ -126,3 +131,238 @@ func (n *NamespaceList) Set(value string) error {
 func (n *NamespaceList) Type() string {
 	return "string"
 }
+
+// AnnotationsAllowList represents the annotation keys which are exposed as
+// Prometheus labels, indexed by resource name.
+type AnnotationsAllowList map[string][]string
+
+func (a *AnnotationsAllowList) String() string {
+	s := *a
+	resources := make([]string, 0, len(s))
+	for resource := range s {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	ss := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		ss = append(ss, resource+"=["+strings.Join(s[resource], ",")+"]")
+	}
+	return strings.Join(ss, ",")
+}
+
+// Set converts a comma-separated string of resource=[annotation,...] pairs
+// into the AnnotationsAllowList, e.g. "pods=[team,owner],deployments=[*]".
+func (a *AnnotationsAllowList) Set(value string) error {
+	s := *a
+
+	for _, part := range splitOutsideBrackets(value) {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("invalid annotations allowlist entry %q, expected resource=[annotation,...]", part)
+		}
+
+		resource := strings.TrimSpace(kv[0])
+		annotations := strings.TrimSpace(kv[1])
+		if len(resource) == 0 || !strings.HasPrefix(annotations, "[") || !strings.HasSuffix(annotations, "]") {
+			return errors.Errorf("invalid annotations allowlist entry %q, expected resource=[annotation,...]", part)
+		}
+
+		for _, annotation := range strings.Split(annotations[1:len(annotations)-1], ",") {
+			annotation = strings.TrimSpace(annotation)
+			if len(annotation) != 0 {
+				s[resource] = append(s[resource], annotation)
+			}
+		}
+	}
+	return nil
+}
+
+// Type returns a descriptive string about the AnnotationsAllowList type.
+func (a *AnnotationsAllowList) Type() string {
+	return "string"
+}
+
+// ResourceLabelSelectors represents a label selector per resource, indexed by
+// resource name.
+type ResourceLabelSelectors map[string]string
+
+func (r *ResourceLabelSelectors) String() string {
+	s := *r
+	resources := make([]string, 0, len(s))
+	for resource := range s {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	ss := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		ss = append(ss, resource+"=["+s[resource]+"]")
+	}
+	return strings.Join(ss, ",")
+}
+
+// Set converts a comma-separated string of resource=[selector] pairs into the
+// ResourceLabelSelectors, e.g. "pods=[ephemeral=true],jobs=[ci in (true)]".
+func (r *ResourceLabelSelectors) Set(value string) error {
+	s := *r
+
+	for _, part := range splitOutsideBrackets(value) {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("invalid label selector entry %q, expected resource=[selector]", part)
+		}
+
+		resource := strings.TrimSpace(kv[0])
+		selector := strings.TrimSpace(kv[1])
+		if len(resource) == 0 || !strings.HasPrefix(selector, "[") || !strings.HasSuffix(selector, "]") {
+			return errors.Errorf("invalid label selector entry %q, expected resource=[selector]", part)
+		}
+
+		s[resource] = strings.TrimSpace(selector[1 : len(selector)-1])
+	}
+	return nil
+}
+
+// Type returns a descriptive string about the ResourceLabelSelectors type.
+func (r *ResourceLabelSelectors) Type() string {
+	return "string"
+}
+
+// splitOutsideBrackets splits the given string at the commas which are not
+// enclosed in square brackets.
+func splitOutsideBrackets(value string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range value {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// CustomLabels represents the static labels added to every exposed series,
+// indexed by label name.
+type CustomLabels map[string]string
+
+func (c *CustomLabels) String() string {
+	s := *c
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ss := make([]string, 0, len(names))
+	for _, name := range names {
+		ss = append(ss, name+"="+s[name])
+	}
+	return strings.Join(ss, ",")
+}
+
+// Set converts a comma-separated string of name=value pairs into the
+// CustomLabels, e.g. "cluster=prod-eu1,region=eu-west-1".
+func (c *CustomLabels) Set(value string) error {
+	s := *c
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("invalid custom label %q, expected name=value", part)
+		}
+
+		name := strings.TrimSpace(kv[0])
+		if !labelNameRE.MatchString(name) {
+			return errors.Errorf("invalid custom label %q, %q is not a valid label name", part, name)
+		}
+		s[name] = strings.TrimSpace(kv[1])
+	}
+	return nil
+}
+
+// Type returns a descriptive string about the CustomLabels type.
+func (c *CustomLabels) Type() string {
+	return "string"
+}
+
+// CIDRList represents a list of IP networks.
+type CIDRList []*net.IPNet
+
+func (c *CIDRList) String() string {
+	ss := make([]string, 0, len(*c))
+	for _, n := range *c {
+		ss = append(ss, n.String())
+	}
+	return strings.Join(ss, ",")
+}
+
+// Set converts a comma-separated string of CIDRs or IP addresses into IP
+// networks and appends them to the CIDRList, e.g. "10.0.0.0/8,192.168.1.10".
+// An IP address is converted into the network holding only this address.
+func (c *CIDRList) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		if !strings.Contains(part, "/") {
+			ip := net.ParseIP(part)
+			if ip == nil {
+				return errors.Errorf("invalid CIDR or IP address %q", part)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			*c = append(*c, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(part)
+		if err != nil {
+			return errors.Wrapf(err, "invalid CIDR %q", part)
+		}
+		*c = append(*c, n)
+	}
+	return nil
+}
+
+// Contains returns whether any network of the CIDRList contains the given IP.
+func (c CIDRList) Contains(ip net.IP) bool {
+	for _, n := range c {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Type returns a descriptive string about the CIDRList type.
+func (c *CIDRList) Type() string {
+	return "string"
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package options
 
 import (
+	"net"
 	"reflect"
 	"testing"
 )
@@ -116,3 +117,174 @@ func TestMetricSetSet(t *testing.T) {
 		}
 	}
 }
+
+func TestAnnotationsAllowListSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      AnnotationsAllowList
+		WantedError bool
+	}{
+		{
+			Desc:   "empty annotations allowlist",
+			Value:  "",
+			Wanted: AnnotationsAllowList{},
+		},
+		{
+			Desc:  "normal annotations allowlist",
+			Value: "pods=[team, example.com/owner],deployments=[*]",
+			Wanted: AnnotationsAllowList(map[string][]string{
+				"pods":        {"team", "example.com/owner"},
+				"deployments": {"*"},
+			}),
+		},
+		{
+			Desc:        "missing brackets",
+			Value:       "pods=team",
+			Wanted:      AnnotationsAllowList{},
+			WantedError: true,
+		},
+		{
+			Desc:        "missing resource",
+			Value:       "=[team]",
+			Wanted:      AnnotationsAllowList{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		a := &AnnotationsAllowList{}
+		gotError := a.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*a, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *a, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestResourceLabelSelectorsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      ResourceLabelSelectors
+		WantedError bool
+	}{
+		{
+			Desc:   "empty label selectors",
+			Value:  "",
+			Wanted: ResourceLabelSelectors{},
+		},
+		{
+			Desc:  "normal label selectors",
+			Value: "pods=[ephemeral=true,ci],jobs=[ci in (true, yes)]",
+			Wanted: ResourceLabelSelectors(map[string]string{
+				"pods": "ephemeral=true,ci",
+				"jobs": "ci in (true, yes)",
+			}),
+		},
+		{
+			Desc:        "missing brackets",
+			Value:       "pods=ephemeral=true",
+			Wanted:      ResourceLabelSelectors{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		r := &ResourceLabelSelectors{}
+		gotError := r.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*r, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *r, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestCustomLabelsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      CustomLabels
+		WantedError bool
+	}{
+		{
+			Desc:   "empty custom labels",
+			Value:  "",
+			Wanted: CustomLabels{},
+		},
+		{
+			Desc:  "normal custom labels",
+			Value: "cluster=prod-eu1, region=eu-west-1",
+			Wanted: CustomLabels(map[string]string{
+				"cluster": "prod-eu1",
+				"region":  "eu-west-1",
+			}),
+		},
+		{
+			Desc:        "missing value",
+			Value:       "cluster",
+			Wanted:      CustomLabels{},
+			WantedError: true,
+		},
+		{
+			Desc:        "invalid label name",
+			Value:       "cluster-name=prod",
+			Wanted:      CustomLabels{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		c := &CustomLabels{}
+		gotError := c.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*c, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *c, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestCIDRListSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      string
+		WantedError bool
+	}{
+		{
+			Desc:   "empty CIDR list",
+			Value:  "",
+			Wanted: "",
+		},
+		{
+			Desc:   "CIDRs and IP addresses",
+			Value:  "10.0.0.0/8, 192.168.1.10,fd00::/8,fd00::1",
+			Wanted: "10.0.0.0/8,192.168.1.10/32,fd00::/8,fd00::1/128",
+		},
+		{
+			Desc:        "invalid CIDR",
+			Value:       "10.0.0.0/33",
+			WantedError: true,
+		},
+		{
+			Desc:        "invalid IP address",
+			Value:       "example.com",
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		c := &CIDRList{}
+		gotError := c.Set(test.Value)
+		if (gotError != nil) != test.WantedError || (!test.WantedError && c.String() != test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %s. Got: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, c.String(), test.WantedError, gotError)
+		}
+	}
+
+	c := &CIDRList{}
+	if err := c.Set("10.0.0.0/8,fd00::1"); err != nil {
+		t.Fatal(err)
+	}
+	for ip, wanted := range map[string]bool{"10.1.2.3": true, "11.0.0.1": false, "fd00::1": true, "fd00::2": false} {
+		if got := c.Contains(net.ParseIP(ip)); got != wanted {
+			t.Errorf("Test error for IP %s. Want contained: %v. Got: %v", ip, wanted, got)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rules holds the recommended Prometheus recording and alerting rules
+// based on the metrics of kube-state-metrics.
+package rules
+
+// Rule is a Prometheus recording or alerting rule, in the format of
+// Prometheus rule files.
+type Rule struct {
+	Record      string            `json:"record,omitempty"`
+	Alert       string            `json:"alert,omitempty"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// RuleGroup is a group of rules, in the format of Prometheus rule files.
+type RuleGroup struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// Recommended holds the recommended rules based on the metrics of each
+// resource. Every rule may only use the metric families of its resource which
+// are exposed by default.
+var Recommended = map[string][]Rule{
+	"daemonsets": {
+		{
+			Alert: "KubeDaemonSetRolloutStuck",
+			Expr:  "kube_daemonset_status_number_available < kube_daemonset_status_desired_number_scheduled",
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "DaemonSet rollout is stuck.",
+				"description": "DaemonSet {{ $labels.namespace }}/{{ $labels.daemonset }} has had unavailable pods for 15 minutes.",
+			},
+		},
+		{
+			Alert: "KubeDaemonSetMisScheduled",
+			Expr:  "kube_daemonset_status_number_misscheduled > 0",
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "DaemonSet pods are misscheduled.",
+				"description": "{{ $value }} pods of DaemonSet {{ $labels.namespace }}/{{ $labels.daemonset }} are running where they are not supposed to run.",
+			},
+		},
+	},
+	"deployments": {
+		{
+			Alert: "KubeDeploymentRolloutStuck",
+			Expr:  `kube_deployment_status_condition{condition="Progressing",status="false"} == 1`,
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Deployment rollout is not progressing.",
+				"description": "Rollout of deployment {{ $labels.namespace }}/{{ $labels.deployment }} is not progressing: {{ $labels.reason }}.",
+			},
+		},
+		{
+			Alert: "KubeDeploymentGenerationMismatch",
+			Expr:  "kube_deployment_status_observed_generation != kube_deployment_metadata_generation",
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Deployment generation mismatch.",
+				"description": "The latest generation of deployment {{ $labels.namespace }}/{{ $labels.deployment }} has not been observed by its controller for 15 minutes.",
+			},
+		},
+		{
+			Alert: "KubeDeploymentReplicasMismatch",
+			Expr:  "kube_deployment_spec_replicas > kube_deployment_status_replicas_available",
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Deployment has not matched the expected number of replicas.",
+				"description": "Deployment {{ $labels.namespace }}/{{ $labels.deployment }} has had fewer available replicas than desired for 15 minutes.",
+			},
+		},
+	},
+	"jobs": {
+		{
+			Alert: "KubeJobFailed",
+			Expr:  `kube_job_failed{condition="true"} > 0`,
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Job failed.",
+				"description": "Job {{ $labels.namespace }}/{{ $labels.job_name }} failed to complete.",
+			},
+		},
+	},
+	"persistentvolumeclaims": {
+		{
+			Alert: "KubePersistentVolumeClaimPending",
+			Expr:  `kube_persistentvolumeclaim_status_phase{phase="Pending"} == 1`,
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "PersistentVolumeClaim is pending.",
+				"description": "PersistentVolumeClaim {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} has not been bound for 15 minutes.",
+			},
+		},
+		{
+			// The usage of volumes is exposed by the kubelet, not by
+			// kube-state-metrics.
+			Alert: "KubePersistentVolumeClaimFillingUp",
+			Expr:  "kubelet_volume_stats_available_bytes / kubelet_volume_stats_capacity_bytes < 0.03 and on (namespace, persistentvolumeclaim) kube_persistentvolumeclaim_info",
+			For:   "1m",
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "PersistentVolumeClaim is filling up.",
+				"description": "The volume of PersistentVolumeClaim {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} has {{ $value | humanizePercentage }} free space left. Requires the volume metrics of the kubelet.",
+			},
+		},
+	},
+	"poddisruptionbudgets": {
+		{
+			Alert: "KubePodDisruptionBudgetViolated",
+			Expr:  "kube_poddisruptionbudget_violated == 1",
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "PodDisruptionBudget is violated.",
+				"description": "PodDisruptionBudget {{ $labels.namespace }}/{{ $labels.poddisruptionbudget }} has had fewer healthy pods than desired for 15 minutes.",
+			},
+		},
+	},
+	"pods": {
+		{
+			Record: "namespace:kube_pod_container_status_waiting_reason_crashloopbackoff:sum",
+			Expr:   `sum by (namespace) (kube_pod_container_status_waiting_reason{reason="CrashLoopBackOff"})`,
+		},
+		{
+			Record: "namespace_phase:kube_pod_status_phase:sum",
+			Expr:   "sum by (namespace, phase) (kube_pod_status_phase)",
+		},
+		{
+			Alert: "KubePodCrashLooping",
+			Expr:  `max_over_time(kube_pod_container_status_waiting_reason{reason="CrashLoopBackOff"}[5m]) >= 1`,
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Pod is crash looping.",
+				"description": "Container {{ $labels.container }} of pod {{ $labels.namespace }}/{{ $labels.pod }} has been in CrashLoopBackOff for 15 minutes.",
+			},
+		},
+	},
+	"statefulsets": {
+		{
+			Alert: "KubeStatefulSetReplicasMismatch",
+			Expr:  "kube_statefulset_status_replicas_ready != kube_statefulset_status_replicas",
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "StatefulSet has not matched the expected number of replicas.",
+				"description": "StatefulSet {{ $labels.namespace }}/{{ $labels.statefulset }} has had replicas which are not ready for 15 minutes.",
+			},
+		},
+		{
+			Alert: "KubeStatefulSetUpdateNotRolledOut",
+			Expr:  "max without (revision) (kube_statefulset_status_current_revision unless kube_statefulset_status_update_revision) * (kube_statefulset_replicas != kube_statefulset_status_replicas_updated)",
+			For:   "15m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "StatefulSet update has not been rolled out.",
+				"description": "The update of StatefulSet {{ $labels.namespace }}/{{ $labels.statefulset }} has not been rolled out for 15 minutes.",
+			},
+		},
+	},
+}
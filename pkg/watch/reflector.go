@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// ReflectorBackoff is the backoff applied between failed list and watch
+// attempts of a reflector started with RunReflector.
+var ReflectorBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    10,
+	Cap:      5 * time.Minute,
+}
+
+// reflectorRestartPeriod is the time waited before a reflector lists and
+// watches again after a watch ended without an error.
+const reflectorRestartPeriod = time.Second
+
+// RunReflector runs the given reflector until stopCh is closed. Unlike
+// cache.Reflector.Run, which retries every second, failed list and watch
+// attempts, e.g. while the apiserver is unavailable, are retried with an
+// exponential backoff. The backoff is reset once a list and watch ended
+// without an error.
+func RunReflector(reflector *cache.Reflector, name string, stopCh <-chan struct{}) {
+	backoff := ReflectorBackoff
+
+	for {
+		delay := reflectorRestartPeriod
+		if err := reflector.ListAndWatch(stopCh); err != nil {
+			delay = backoff.Step()
+			klog.Errorf("Failed to list and watch %s, retrying in %s: %v", name, delay, err)
+		} else {
+			backoff = ReflectorBackoff
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(delay):
+		}
+	}
+}
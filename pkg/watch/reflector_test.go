@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestRunReflectorRetriesFailedLists(t *testing.T) {
+	defer func(b wait.Backoff) { ReflectorBackoff = b }(ReflectorBackoff)
+	ReflectorBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 10}
+
+	const failures = 3
+
+	var mtx sync.Mutex
+	lists := 0
+	lw := &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			lists++
+			if lists <= failures {
+				return nil, errors.New("apiserver unavailable")
+			}
+			return &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}}, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
+
+	metrics := NewListWatchMetrics(prometheus.NewRegistry())
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	reflector := cache.NewReflector(NewInstrumentedListerWatcher(lw, metrics, "*v1.Pod"), &v1.Pod{}, store, 0)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go RunReflector(reflector, "*v1.Pod", stopCh)
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return reflector.LastSyncResourceVersion() == "1", nil
+	})
+	if err != nil {
+		t.Fatalf("expected reflector to list successfully after %d failures: %v", failures, err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if lists != failures+1 {
+		t.Fatalf("expected %d lists, got %d", failures+1, lists)
+	}
+	if v := gaugeValue(t, metrics.ListHealthy.WithLabelValues("*v1.Pod")); v != 1 {
+		t.Fatalf("expected resource to be healthy after a successful list, got %v", v)
+	}
+	if v := counterValue(t, metrics.ListTotal.WithLabelValues("error", "*v1.Pod")); v != failures {
+		t.Fatalf("expected %d failed lists, got %v", failures, v)
+	}
+}
+
+func gaugeValue(t *testing.T, m prometheus.Metric) float64 {
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		t.Fatal(err)
+	}
+	return pb.GetGauge().GetValue()
+}
+
+func counterValue(t *testing.T, m prometheus.Metric) float64 {
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		t.Fatal(err)
+	}
+	return pb.GetCounter().GetValue()
+}
@@ -24,15 +24,18 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
-// ListWatchMetrics stores the pointers of kube_state_metrics_[list|watch]_total metrics.
+// ListWatchMetrics stores the pointers of kube_state_metrics_[list|watch]_total
+// and kube_state_metrics_list_healthy metrics.
 type ListWatchMetrics struct {
-	WatchTotal *prometheus.CounterVec
-	ListTotal  *prometheus.CounterVec
+	WatchTotal  *prometheus.CounterVec
+	ListTotal   *prometheus.CounterVec
+	ListHealthy *prometheus.GaugeVec
 }
 
 // NewListWatchMetrics takes in a prometheus registry and initializes
-// and registers the kube_state_metrics_list_total and
-// kube_state_metrics_watch_total metrics. It returns those registered metrics.
+// and registers the kube_state_metrics_list_total,
+// kube_state_metrics_watch_total and kube_state_metrics_list_healthy metrics.
+// It returns those registered metrics.
 func NewListWatchMetrics(r *prometheus.Registry) *ListWatchMetrics {
 	var m ListWatchMetrics
 	m.WatchTotal = prometheus.NewCounterVec(
@@ -50,10 +53,19 @@ func NewListWatchMetrics(r *prometheus.Registry) *ListWatchMetrics {
 		},
 		[]string{"result", "resource"},
 	)
+
+	m.ListHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_list_healthy",
+			Help: "Whether the last list of a resource in kube-state-metrics succeeded",
+		},
+		[]string{"resource"},
+	)
 	if r != nil {
 		r.MustRegister(
 			m.ListTotal,
 			m.WatchTotal,
+			m.ListHealthy,
 		)
 	}
 	return &m
@@ -82,10 +94,12 @@ func (i *InstrumentedListerWatcher) List(options metav1.ListOptions) (res runtim
 	res, err = i.lw.List(options)
 	if err != nil {
 		i.metrics.ListTotal.WithLabelValues("error", i.resource).Inc()
+		i.metrics.ListHealthy.WithLabelValues(i.resource).Set(0)
 		return
 	}
 
 	i.metrics.ListTotal.WithLabelValues("success", i.resource).Inc()
+	i.metrics.ListHealthy.WithLabelValues(i.resource).Set(1)
 	return
 }
 
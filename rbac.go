@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/optin"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// rbacCommand is the name of the subcommand printing the ClusterRole needed
+// by kube-state-metrics.
+const rbacCommand = "rbac"
+
+// runRBAC parses the flags of the rbac subcommand and writes the minimal
+// ClusterRole needed to collect the requested resources to w as YAML.
+func runRBAC(args []string, w io.Writer) error {
+	resources := options.ResourceSet{}
+	optInMetrics := options.MetricSet{}
+	flags := pflag.NewFlagSet(rbacCommand, pflag.ContinueOnError)
+	flags.Var(&resources, "resources", fmt.Sprintf("Comma-separated list of Resources to be enabled. Defaults to %q", &options.DefaultResources))
+	flags.Var(&optInMetrics, "metric-opt-in-list", "Comma-separated list of opt-in metrics to be enabled. Only the API resources needed by the enabled opt-in metrics are granted.")
+	name := flags.String("name", "kube-state-metrics", "Name of the ClusterRole.")
+	autoSharding := flags.Bool("auto-sharding", false, "Allow getting the pod of kube-state-metrics and its StatefulSet, as needed for automated sharding with --pod and --pod-namespace.")
+	scrapeAuthorization := flags.Bool("scrape-authorization", false, "Allow creating TokenReviews and SubjectAccessReviews, as needed for --scrape-authorization.")
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	enabled := options.DefaultResources.AsSlice()
+	if len(resources) > 0 {
+		enabled = resources.AsSlice()
+	}
+
+	optInList, err := optin.NewMetricFamilyFilter(optInMetrics)
+	if err != nil {
+		return err
+	}
+
+	rules, err := store.PolicyRules(enabled, optInList)
+	if err != nil {
+		return err
+	}
+	if *autoSharding {
+		rules = append(rules,
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"statefulsets"}, Verbs: []string{"get"}},
+		)
+	}
+	if *scrapeAuthorization {
+		rules = append(rules,
+			rbacv1.PolicyRule{APIGroups: []string{"authentication.k8s.io"}, Resources: []string{"tokenreviews"}, Verbs: []string{"create"}},
+			rbacv1.PolicyRule{APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"subjectaccessreviews"}, Verbs: []string{"create"}},
+		)
+	}
+
+	role := &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: *name,
+			Labels: map[string]string{
+				"app.kubernetes.io/name": "kube-state-metrics",
+			},
+		},
+		Rules: rules,
+	}
+
+	out, err := yaml.Marshal(role)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
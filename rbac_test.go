@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunRBAC(t *testing.T) {
+	var out bytes.Buffer
+	if err := runRBAC([]string{"--resources=secrets,statefulsets", "--name=ksm"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  creationTimestamp: null
+  labels:
+    app.kubernetes.io/name: kube-state-metrics
+  name: ksm
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - secrets
+  verbs:
+  - list
+  - watch
+- apiGroups:
+  - apps
+  resources:
+  - statefulsets
+  verbs:
+  - list
+  - watch
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := runRBAC([]string{"--resources=namespaces", "--metric-opt-in-list=kube_namespace_workloads"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "  - deployments\n") || strings.Contains(out.String(), "  - pods\n") {
+		t.Fatalf("expected workloads but not pods to be granted, got:\n%s", out.String())
+	}
+
+	if err := runRBAC([]string{"--resources=unknown"}, &out); err == nil {
+		t.Fatal("expected error for unknown resource")
+	}
+}
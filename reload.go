@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/allowdenylist"
+	"k8s.io/kube-state-metrics/pkg/listwatch"
+	"k8s.io/kube-state-metrics/pkg/metricshandler"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+const reloadPath = "/-/reload"
+
+// configureFilters configures the store builder with the options which can be
+// reloaded at runtime: the namespaces, the metric allow- and denylist, the
+// annotations allowlist and the label exclude selectors. The namespaces
+// matching the namespace label selector are set by a namespaceSelector.
+// Invalid options are rejected before any of them is applied.
+func configureFilters(b *store.Builder, opts *options.Options) error {
+	if len(opts.Namespaces) > 0 && opts.NamespaceLabelSelector != "" {
+		return errors.New("--namespace and --namespace-label-selector are mutually exclusive")
+	}
+	if opts.NamespaceLabelSelector != "" {
+		if _, err := labels.Parse(opts.NamespaceLabelSelector); err != nil {
+			return errors.Wrap(err, "failed to parse namespace label selector")
+		}
+	}
+
+	allowDenyList, err := allowdenylist.New(opts.MetricAllowlist, opts.MetricDenylist)
+	if err != nil {
+		return err
+	}
+
+	err = allowDenyList.Parse()
+	if err != nil {
+		return errors.Wrap(err, "error initializing the allowdeny list")
+	}
+
+	if err := b.WithLabelExcludeSelectors(opts.ResourceLabelExcludeSelectors); err != nil {
+		return errors.Wrap(err, "failed to set up label exclude selectors")
+	}
+	if len(opts.ResourceLabelExcludeSelectors) > 0 {
+		klog.Infof("Not exposing the objects matching the label exclude selectors %s", opts.ResourceLabelExcludeSelectors.String())
+	}
+
+	klog.Infof("metric allow-denylisting: %v", allowDenyList.Status())
+
+	switch {
+	case opts.NamespaceLabelSelector != "":
+		// The namespaces are set by the namespace label selector.
+	case len(opts.Namespaces) == 0:
+		klog.Info("Using all namespace")
+		b.WithNamespaces(options.DefaultNamespaces)
+	default:
+		if opts.Namespaces.IsAllNamespaces() {
+			klog.Info("Using all namespace")
+		} else {
+			klog.Infof("Using %s namespaces", opts.Namespaces)
+		}
+		b.WithNamespaces(opts.Namespaces)
+	}
+
+	b.WithAllowDenyList(allowDenyList)
+
+	if len(opts.AnnotationsAllowList) > 0 {
+		klog.Infof("Using annotations allowlist: %s", opts.AnnotationsAllowList.String())
+	}
+	b.WithAllowAnnotations(opts.AnnotationsAllowList)
+
+	return nil
+}
+
+// namespaceSelector watches the namespaces matching the namespace label
+// selector and sets them as the namespaces of a store builder.
+type namespaceSelector struct {
+	ctx        context.Context
+	kubeClient clientset.Interface
+
+	selector string
+	cancel   context.CancelFunc
+}
+
+// set replaces the current watch with a watch of the namespaces matching the
+// given label selector, and returns once they have been set as the namespaces
+// of the given store builder. An empty selector stops the current watch.
+func (n *namespaceSelector) set(b *store.Builder, selector string) error {
+	if selector == n.selector {
+		return nil
+	}
+
+	if n.cancel != nil {
+		n.cancel()
+		n.cancel = nil
+	}
+	n.selector = ""
+	if selector == "" {
+		return nil
+	}
+
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse namespace label selector")
+	}
+	klog.Infof("Using namespaces matching label selector %s", parsed)
+
+	ctx, cancel := context.WithCancel(n.ctx)
+	err = listwatch.WatchNamespaceSelector(ctx, n.kubeClient, parsed, func(namespaces []string) {
+		// Namespaces set by a replaced watch must not override the
+		// namespaces configured after it.
+		if ctx.Err() == nil {
+			b.WithNamespaces(namespaces)
+		}
+	})
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to watch namespaces")
+	}
+
+	n.selector = selector
+	n.cancel = cancel
+	return nil
+}
+
+// reloader reloads the file given by --config and applies the reloadable
+// options in it to the stores of a MetricsHandler.
+type reloader struct {
+	opts       *options.Options
+	handler    *metricshandler.MetricsHandler
+	namespaces *namespaceSelector
+}
+
+// reload reloads the config file. If it is invalid, the current configuration
+// is kept.
+func (r *reloader) reload() error {
+	opts, err := r.opts.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "load config")
+	}
+
+	return r.handler.Reconfigure(func(b *store.Builder) error {
+		if err := configureFilters(b, opts); err != nil {
+			return err
+		}
+		return r.namespaces.set(b, opts.NamespaceLabelSelector)
+	})
+}
+
+// ServeHTTP implements the http.Handler interface. It reloads the config file
+// on POST requests.
+func (r *reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Only POST requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	klog.Infof("Reloading config on request from %s", req.RemoteAddr)
+	if err := r.reload(); err != nil {
+		klog.Errorf("Failed to reload config: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	klog.Info("Reloaded config")
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/metricshandler"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ksm-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := options.NewOptions()
+	opts.ConfigFile = filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(opts.ConfigFile, []byte("metric-denylist: kube_configmap_created\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubeClient := fake.NewSimpleClientset(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "configmap", Namespace: "default"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"team": "a"}}},
+	)
+
+	builder := store.NewBuilder()
+	builder.WithMetrics(prometheus.NewRegistry())
+	builder.WithEnabledResources([]string{"configmaps"})
+	builder.WithKubeClient(kubeClient)
+	builder.WithGenerateStoreFunc(builder.DefaultGenerateStoreFunc())
+
+	configuredOpts, err := opts.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := configureFilters(builder, configuredOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := metricshandler.New(opts, kubeClient, builder, false)
+	handler.ConfigureSharding(ctx, 0, 1)
+	r := &reloader{opts: opts, handler: handler, namespaces: &namespaceSelector{ctx: ctx, kubeClient: kubeClient}}
+
+	scrape := func() string {
+		// Wait for caches to fill
+		time.Sleep(time.Second)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/metrics", nil))
+		return w.Body.String()
+	}
+
+	if body := scrape(); strings.Contains(body, "kube_configmap_created") || !strings.Contains(body, "kube_configmap_info") {
+		t.Fatalf("expected only kube_configmap_info before reload, got:\n%s", body)
+	}
+
+	if err := ioutil.WriteFile(opts.ConfigFile, []byte("metric-denylist: kube_configmap_info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080"+reloadPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status %d for GET request, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "http://localhost:8080"+reloadPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	if body := scrape(); !strings.Contains(body, "kube_configmap_created") || strings.Contains(body, "kube_configmap_info") {
+		t.Fatalf("expected only kube_configmap_created after reload, got:\n%s", body)
+	}
+
+	// An invalid config file keeps the current configuration.
+	if err := ioutil.WriteFile(opts.ConfigFile, []byte("resources: pods\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err == nil {
+		t.Fatal("expected error for flag which can not be reloaded")
+	}
+
+	if body := scrape(); !strings.Contains(body, "kube_configmap_created") || strings.Contains(body, "kube_configmap_info") {
+		t.Fatalf("expected only kube_configmap_created after failed reload, got:\n%s", body)
+	}
+
+	// The namespace label selector replaces the namespaces.
+	if err := ioutil.WriteFile(opts.ConfigFile, []byte("namespace-label-selector: team=b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if body := scrape(); strings.Contains(body, "kube_configmap_info{") {
+		t.Fatalf("expected no configmaps outside of the selected namespaces, got:\n%s", body)
+	}
+
+	if err := ioutil.WriteFile(opts.ConfigFile, []byte("namespace-label-selector: team=a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if body := scrape(); !strings.Contains(body, "kube_configmap_info{") {
+		t.Fatalf("expected configmaps of the selected namespaces, got:\n%s", body)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/rules"
+)
+
+// rulesCommand is the name of the subcommand printing the recommended
+// Prometheus recording and alerting rules.
+const rulesCommand = "rules"
+
+// runRules parses the flags of the rules subcommand and writes the
+// recommended rules based on the metrics of the requested resources to w as
+// a Prometheus rule file.
+func runRules(args []string, w io.Writer) error {
+	resources := options.ResourceSet{}
+	flags := pflag.NewFlagSet(rulesCommand, pflag.ContinueOnError)
+	flags.Var(&resources, "resources", fmt.Sprintf("Comma-separated list of Resources to be enabled. Defaults to %q", &options.DefaultResources))
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	enabled := options.DefaultResources.AsSlice()
+	if len(resources) > 0 {
+		enabled = resources.AsSlice()
+	}
+	sort.Strings(enabled)
+
+	groups, err := store.RecommendedRules(enabled)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(struct {
+		Groups []rules.RuleGroup `json:"groups"`
+	}{groups})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
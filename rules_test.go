@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRunRules(t *testing.T) {
+	var out bytes.Buffer
+	if err := runRules([]string{"--resources=poddisruptionbudgets,secrets"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `groups:
+- name: kube-state-metrics-poddisruptionbudgets
+  rules:
+  - alert: KubePodDisruptionBudgetViolated
+    annotations:
+      description: PodDisruptionBudget {{ $labels.namespace }}/{{ $labels.poddisruptionbudget
+        }} has had fewer healthy pods than desired for 15 minutes.
+      summary: PodDisruptionBudget is violated.
+    expr: kube_poddisruptionbudget_violated == 1
+    for: 15m
+    labels:
+      severity: warning
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	if err := runRules([]string{"--resources=unknown"}, &out); err == nil {
+		t.Fatal("expected error for unknown resource")
+	}
+}